@@ -18,6 +18,7 @@ import (
 	"reflect"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -36,6 +37,9 @@ type baseClient struct {
 		sync.RWMutex
 		clientConns map[string]*grpc.ClientConn
 		leader      string
+		// followers is the client urls of the followers, it is only updated
+		// if the follower read is enabled.
+		followers []string
 	}
 
 	checkLeaderCh chan struct{}
@@ -49,6 +53,29 @@ type baseClient struct {
 	gRPCDialOptions []grpc.DialOption
 
 	timeout time.Duration
+
+	cacheOption cacheOption
+
+	// maxFollowerStaleness is the max staleness of the regions read from the
+	// followers, 0 means the follower read is disabled.
+	maxFollowerStaleness time.Duration
+	nextFollower         uint32
+
+	tsoOption tsoOption
+}
+
+// tsoOption is the options of batching the TSO requests.
+type tsoOption struct {
+	// maxBatchSize is the max number of the requests in a batch.
+	maxBatchSize int
+	// maxBatchWait is the max time to wait for more requests if the batch is
+	// not full, 0 means to send the pending requests without waiting.
+	maxBatchWait time.Duration
+	// streams is the number of the TSO streams to send the batches in
+	// parallel.
+	streams int
+	// latencyHistogram enables the latency histograms of each TSO request.
+	latencyHistogram bool
 }
 
 // SecurityOption records options about tls
@@ -75,6 +102,57 @@ func WithCustomTimeoutOption(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithFollowerRead makes the client send GetRegion, GetPrevRegion, ScanRegions
+// and GetStore requests to the followers, the followers serve the requests if
+// their regions are synced with the leader within maxStaleness. The requests
+// fall back to the leader if the followers fail to serve them, including the
+// regions whose leaders are unknown to the followers. Note that the regions
+// served by the followers have no down peers and pending peers, and the stores
+// served by the followers are reloaded from the storage periodically.
+func WithFollowerRead(maxStaleness time.Duration) ClientOption {
+	return func(c *baseClient) {
+		c.maxFollowerStaleness = maxStaleness
+	}
+}
+
+// WithTSOMaxBatchSize configures the max number of the TSO requests in a batch.
+func WithTSOMaxBatchSize(size int) ClientOption {
+	return func(c *baseClient) {
+		if size > 0 {
+			c.tsoOption.maxBatchSize = size
+		}
+	}
+}
+
+// WithTSOMaxBatchWaitTime makes the client wait at most the duration to collect
+// more TSO requests into a batch if the batch is not full. It reduces the RPCs
+// at the cost of the latency of each request.
+func WithTSOMaxBatchWaitTime(wait time.Duration) ClientOption {
+	return func(c *baseClient) {
+		c.tsoOption.maxBatchWait = wait
+	}
+}
+
+// WithTSOStreams configures the number of the TSO streams to send the batched
+// TSO requests to the leader in parallel. The timestamps of the requests sent
+// by different streams are not ordered unless a request is sent after the
+// other is finished.
+func WithTSOStreams(streams int) ClientOption {
+	return func(c *baseClient) {
+		if streams > 0 {
+			c.tsoOption.streams = streams
+		}
+	}
+}
+
+// WithTSOLatencyHistogram enables the histograms of the time each TSO request
+// waits to be sent and to be responded.
+func WithTSOLatencyHistogram() ClientOption {
+	return func(c *baseClient) {
+		c.tsoOption.latencyHistogram = true
+	}
+}
+
 // newBaseClient returns a new baseClient.
 func newBaseClient(ctx context.Context, urls []string, security SecurityOption, opts ...ClientOption) (*baseClient, error) {
 	ctx1, cancel := context.WithCancel(ctx)
@@ -85,6 +163,14 @@ func newBaseClient(ctx context.Context, urls []string, security SecurityOption,
 		cancel:        cancel,
 		security:      security,
 		timeout:       defaultPDTimeout,
+		cacheOption: cacheOption{
+			regionTTL: defaultRegionCacheTTL,
+			storeTTL:  defaultStoreCacheTTL,
+		},
+		tsoOption: tsoOption{
+			maxBatchSize: maxMergeTSORequests,
+			streams:      1,
+		},
 	}
 	c.connMu.clientConns = make(map[string]*grpc.ClientConn)
 	for _, opt := range opts {
@@ -203,6 +289,9 @@ func (c *baseClient) updateLeader() error {
 			}
 		}
 		c.updateURLs(members.GetMembers())
+		if c.maxFollowerStaleness > 0 {
+			c.updateFollowers(members.GetMembers(), members.GetLeader())
+		}
 		return c.switchLeader(members.GetLeader().GetClientUrls())
 	}
 	return errors.Errorf("failed to get leader from %v", c.urls)
@@ -237,6 +326,30 @@ func (c *baseClient) updateURLs(members []*pdpb.Member) {
 	c.urls = urls
 }
 
+func (c *baseClient) updateFollowers(members []*pdpb.Member, leader *pdpb.Member) {
+	var followers []string
+	for _, m := range members {
+		if m.GetMemberId() != leader.GetMemberId() && len(m.GetClientUrls()) > 0 {
+			followers = append(followers, m.GetClientUrls()[0])
+		}
+	}
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	c.connMu.followers = followers
+}
+
+// getFollowerAddr returns the client url of a follower in turn, it returns an
+// empty string if there is no follower.
+func (c *baseClient) getFollowerAddr() string {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	if len(c.connMu.followers) == 0 {
+		return ""
+	}
+	i := atomic.AddUint32(&c.nextFollower, 1)
+	return c.connMu.followers[int(i)%len(c.connMu.followers)]
+}
+
 func (c *baseClient) switchLeader(addrs []string) error {
 	// FIXME: How to safely compare leader urls? For now, only allows one client url.
 	addr := addrs[0]
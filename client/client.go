@@ -14,15 +14,18 @@
 package pd
 
 import (
+	"bytes"
 	"context"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/kvproto/pkg/errorpb"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -49,7 +52,8 @@ type Client interface {
 	GetTSAsync(ctx context.Context) TSFuture
 	// GetRegion gets a region and its leader Peer from PD by key.
 	// The region may expire after split. Caller is responsible for caching and
-	// taking care of region change.
+	// taking care of region change, or uses WithCache to cache the regions and
+	// invalidates the changed regions.
 	// Also it may return nil if PD finds no Region for the key temporarily,
 	// client should retry later.
 	GetRegion(ctx context.Context, key []byte) (*Region, error)
@@ -64,7 +68,7 @@ type Client interface {
 	ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*metapb.Region, []*metapb.Peer, error)
 	// GetStore gets a store from PD by store id.
 	// The store may expire later. Caller is responsible for caching and taking care
-	// of store change.
+	// of store change, or uses WithCache to cache the stores.
 	GetStore(ctx context.Context, storeID uint64) (*metapb.Store, error)
 	// GetAllStores gets all stores from pd.
 	// The store may expire later. Caller is responsible for caching and taking care
@@ -83,12 +87,46 @@ type Client interface {
 	// ScatterRegion scatters the specified region. Should use it for a batch of regions,
 	// and the distribution of these regions will be dispersed.
 	ScatterRegion(ctx context.Context, regionID uint64) error
+	// ScatterRegions scatters the specified regions as a group. PD balances the
+	// peers and leaders among all regions scattered with the same group, so
+	// the regions of a table can use the table as the group.
+	ScatterRegions(ctx context.Context, regionIDs []uint64, group string) error
 	// GetOperator gets the status of operator of the specified region.
 	GetOperator(ctx context.Context, regionID uint64) (*pdpb.GetOperatorResponse, error)
+	// WatchRegions watches the changed regions overlapped with [startKey,
+	// endKey), an empty endKey means the end of the keyspace. PD only sends
+	// the regions in the range. A changed region is sent when its epoch,
+	// peers, leader or flow is changed. The event does not carry the leader,
+	// the cached region is removed so the next GetRegion returns the new
+	// leader. The merged regions are not sent, the caller should treat the
+	// regions overlapped with a changed region as removed. The watcher
+	// resumes from its last change after reconnecting, and rescans the
+	// regions in the range if PD reports that some changes are lost. The
+	// channel is closed when ctx is done or the client is closed.
+	WatchRegions(ctx context.Context, startKey, endKey []byte) (<-chan []*RegionEvent, error)
 	// Close closes the client.
 	Close()
 }
 
+// RegionCacheClient invalidates the regions and stores cached by the client,
+// the client returned by NewClient implements it. It is separated from Client
+// to keep the other implementations of Client unchanged, use a type assertion
+// to get it from a Client.
+type RegionCacheClient interface {
+	// InvalidateRegion removes the region from the cache, the caller should
+	// invalidate the region if it finds the cached region is stale, e.g. the
+	// region is not found or the leader is changed. It does nothing if the
+	// cache is not enabled.
+	InvalidateRegion(regionID uint64)
+	// OnEpochNotMatch invalidates the region and the cached regions
+	// overlapped with the current regions in the EpochNotMatch error returned
+	// by TiKV. It does nothing if the cache is not enabled.
+	OnEpochNotMatch(regionID uint64, err *errorpb.EpochNotMatch)
+	// InvalidateStore removes the store from the cache. It does nothing if the
+	// cache is not enabled.
+	InvalidateStore(storeID uint64)
+}
+
 // GetStoreOp represents available options when getting stores.
 type GetStoreOp struct {
 	excludeTombstone bool
@@ -116,6 +154,9 @@ const (
 	updateLeaderTimeout   = time.Second // Use a shorter timeout to recover faster from network isolation.
 	maxMergeTSORequests   = 10000       // should be higher if client is sending requests in burst
 	maxInitClusterRetries = 100
+	// maxScatterRegionsPerRequest bounds the regions sent by the metadata of
+	// a ScatterRegion request.
+	maxScatterRegionsPerRequest = 256
 )
 
 var (
@@ -131,10 +172,9 @@ type client struct {
 	*baseClient
 	tsoRequests chan *tsoRequest
 
-	lastPhysical int64
-	lastLogical  int64
-
-	tsDeadlineCh chan deadline
+	// regionCache and storeCache are nil if the cache is not enabled.
+	regionCache *regionCache
+	storeCache  *storeCache
 }
 
 // NewClient creates a PD client.
@@ -150,25 +190,42 @@ func NewClientWithContext(ctx context.Context, pdAddrs []string, security Securi
 		return nil, err
 	}
 	c := &client{
-		baseClient:   base,
-		tsoRequests:  make(chan *tsoRequest, maxMergeTSORequests),
-		tsDeadlineCh: make(chan deadline, 1),
+		baseClient:  base,
+		tsoRequests: make(chan *tsoRequest, maxMergeTSORequests),
+	}
+	if base.cacheOption.enable {
+		c.regionCache = newRegionCache(base.cacheOption.regionTTL)
+		c.storeCache = newStoreCache(base.cacheOption.storeTTL)
 	}
 
-	c.wg.Add(2)
-	go c.tsLoop()
-	go c.tsCancelLoop()
+	for i := 0; i < base.tsoOption.streams; i++ {
+		s := &tsoStream{deadlineCh: make(chan deadline, 1)}
+		c.wg.Add(2)
+		go c.tsLoop(s)
+		go c.tsCancelLoop(s.deadlineCh)
+	}
 
 	return c, nil
 }
 
+// tsoStream is a TSO stream to the leader, the client sends the batched TSO
+// requests with one or more streams in parallel.
+type tsoStream struct {
+	// lastPhysical and lastLogical are the last timestamp received by the
+	// stream, the timestamps of a stream are always increasing.
+	lastPhysical int64
+	lastLogical  int64
+
+	deadlineCh chan deadline
+}
+
 type deadline struct {
 	timer  <-chan time.Time
 	done   chan struct{}
 	cancel context.CancelFunc
 }
 
-func (c *client) tsCancelLoop() {
+func (c *client) tsCancelLoop(deadlineCh <-chan deadline) {
 	defer c.wg.Done()
 
 	ctx, cancel := context.WithCancel(c.ctx)
@@ -176,7 +233,7 @@ func (c *client) tsCancelLoop() {
 
 	for {
 		select {
-		case d := <-c.tsDeadlineCh:
+		case d := <-deadlineCh:
 			select {
 			case <-d.timer:
 				log.Error("tso request is canceled due to timeout")
@@ -191,14 +248,13 @@ func (c *client) tsCancelLoop() {
 	}
 }
 
-func (c *client) tsLoop() {
+func (c *client) tsLoop(s *tsoStream) {
 	defer c.wg.Done()
 
 	loopCtx, loopCancel := context.WithCancel(c.ctx)
 	defer loopCancel()
 
-	defaultSize := maxMergeTSORequests + 1
-	requests := make([]*tsoRequest, defaultSize)
+	requests := make([]*tsoRequest, c.tsoOption.maxBatchSize)
 
 	var opts []opentracing.StartSpanOption
 	var stream pdpb.PD_TsoClient
@@ -233,11 +289,7 @@ func (c *client) tsLoop() {
 
 		select {
 		case first := <-c.tsoRequests:
-			pendingPlus1 := len(c.tsoRequests) + 1
-			requests[0] = first
-			for i := 1; i < pendingPlus1; i++ {
-				requests[i] = <-c.tsoRequests
-			}
+			count := c.collectTSORequests(loopCtx, first, requests)
 			done := make(chan struct{})
 			dl := deadline{
 				timer:  time.After(c.timeout),
@@ -245,13 +297,13 @@ func (c *client) tsLoop() {
 				cancel: cancel,
 			}
 			select {
-			case c.tsDeadlineCh <- dl:
+			case s.deadlineCh <- dl:
 			case <-loopCtx.Done():
 				cancel()
 				return
 			}
-			opts = extractSpanReference(requests[:pendingPlus1], opts[:0])
-			err = c.processTSORequests(stream, requests[:pendingPlus1], opts)
+			opts = extractSpanReference(requests[:count], opts[:0])
+			err = c.processTSORequests(s, stream, requests[:count], opts)
 			close(done)
 		case <-loopCtx.Done():
 			cancel()
@@ -273,6 +325,39 @@ func (c *client) tsLoop() {
 	}
 }
 
+// collectTSORequests collects the pending requests into a batch with the
+// first request, and waits at most maxBatchWait for more requests if the batch
+// is not full. It returns the number of the requests in the batch.
+func (c *client) collectTSORequests(ctx context.Context, first *tsoRequest, requests []*tsoRequest) int {
+	requests[0] = first
+	count := 1
+	for count < len(requests) {
+		select {
+		case requests[count] = <-c.tsoRequests:
+			count++
+			continue
+		default:
+		}
+		break
+	}
+	if c.tsoOption.maxBatchWait <= 0 || count == len(requests) {
+		return count
+	}
+	timer := time.NewTimer(c.tsoOption.maxBatchWait)
+	defer timer.Stop()
+	for count < len(requests) {
+		select {
+		case requests[count] = <-c.tsoRequests:
+			count++
+		case <-timer.C:
+			return count
+		case <-ctx.Done():
+			return count
+		}
+	}
+	return count
+}
+
 func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOption) []opentracing.StartSpanOption {
 	for _, req := range requests {
 		if span := opentracing.SpanFromContext(req.ctx); span != nil {
@@ -282,13 +367,18 @@ func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOp
 	return opts
 }
 
-func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoRequest, opts []opentracing.StartSpanOption) error {
+func (c *client) processTSORequests(s *tsoStream, stream pdpb.PD_TsoClient, requests []*tsoRequest, opts []opentracing.StartSpanOption) error {
 	if len(opts) > 0 {
 		span := opentracing.StartSpan("pdclient.processTSORequests", opts...)
 		defer span.Finish()
 	}
 	count := len(requests)
 	start := time.Now()
+	if c.tsoOption.latencyHistogram {
+		for _, req := range requests {
+			tsoRequestBatchWait.Observe(start.Sub(req.start).Seconds())
+		}
+	}
 	req := &pdpb.TsoRequest{
 		Header: c.requestHeader(),
 		Count:  uint32(count),
@@ -317,12 +407,18 @@ func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoReq
 	physical, logical := resp.GetTimestamp().GetPhysical(), resp.GetTimestamp().GetLogical()
 	// Server returns the highest ts.
 	logical -= int64(resp.GetCount() - 1)
-	if tsLessEqual(physical, logical, c.lastPhysical, c.lastLogical) {
+	if tsLessEqual(physical, logical, s.lastPhysical, s.lastLogical) {
 		panic(errors.Errorf("timestamp fallback, newly acquired ts (%d,%d) is less or equal to last one (%d, %d)",
-			physical, logical, c.lastLogical, c.lastLogical))
+			physical, logical, s.lastLogical, s.lastLogical))
+	}
+	s.lastPhysical = physical
+	s.lastLogical = logical + int64(len(requests)) - 1
+	if c.tsoOption.latencyHistogram {
+		now := time.Now()
+		for _, req := range requests {
+			tsoRequestResponse.Observe(now.Sub(req.start).Seconds())
+		}
 	}
-	c.lastPhysical = physical
-	c.lastLogical = logical + int64(len(requests)) - 1
 	c.finishTSORequest(requests, physical, logical, nil)
 	return nil
 }
@@ -375,6 +471,48 @@ func (c *client) leaderClient() pdpb.PDClient {
 	return pdpb.NewPDClient(c.connMu.clientConns[c.connMu.leader])
 }
 
+// followerClient gets the client of a follower, it returns nil if there is no
+// available follower.
+func (c *client) followerClient() pdpb.PDClient {
+	addr := c.getFollowerAddr()
+	if addr == "" {
+		return nil
+	}
+	cc, err := c.getOrCreateGRPCConn(addr)
+	if err != nil {
+		log.Warn("[pd] failed to connect follower", zap.String("follower", addr), zap.Error(err))
+		return nil
+	}
+	return pdpb.NewPDClient(cc)
+}
+
+// readWithFallback calls read with a follower client if the follower read is
+// enabled, and calls it again with the leader client if the follower fails to
+// serve the request. The follower has at most half of the time before the
+// deadline of ctx.
+func (c *client) readWithFallback(ctx context.Context, read func(context.Context, pdpb.PDClient) error) error {
+	if c.maxFollowerStaleness > 0 {
+		if cli := c.followerClient(); cli != nil {
+			followerCtx := grpcutil.WithFollowerRead(ctx, c.maxFollowerStaleness)
+			var cancel context.CancelFunc
+			if deadline, ok := ctx.Deadline(); ok {
+				followerCtx, cancel = context.WithTimeout(followerCtx, time.Until(deadline)/2)
+			} else {
+				followerCtx, cancel = context.WithTimeout(followerCtx, c.timeout/2)
+			}
+			err := read(followerCtx, cli)
+			cancel()
+			if err == nil {
+				followerReadServed.Inc()
+				return nil
+			}
+			followerReadFallback.Inc()
+			log.Debug("[pd] failed to read from follower, fall back to leader", zap.Error(err))
+		}
+	}
+	return read(ctx, c.leaderClient())
+}
+
 var tsoReqPool = sync.Pool{
 	New: func() interface{} {
 		return &tsoRequest{
@@ -453,13 +591,27 @@ func (c *client) GetRegion(ctx context.Context, key []byte) (*Region, error) {
 		span = opentracing.StartSpan("pdclient.GetRegion", opentracing.ChildOf(span.Context()))
 		defer span.Finish()
 	}
+	if c.regionCache != nil {
+		if region := c.regionCache.searchRegion(key); region != nil {
+			return region, nil
+		}
+		if c.cacheOption.prefetchLimit > 0 {
+			if region := c.prefetchRegions(ctx, key); region != nil {
+				return region, nil
+			}
+		}
+	}
 	start := time.Now()
 	defer func() { cmdDurationGetRegion.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	resp, err := c.leaderClient().GetRegion(ctx, &pdpb.GetRegionRequest{
-		Header:    c.requestHeader(),
-		RegionKey: key,
+	var resp *pdpb.GetRegionResponse
+	err := c.readWithFallback(ctx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.GetRegion(ctx, &pdpb.GetRegionRequest{
+			Header:    c.requestHeader(),
+			RegionKey: key,
+		})
+		return err
 	})
 	cancel()
 
@@ -468,7 +620,7 @@ func (c *client) GetRegion(ctx context.Context, key []byte) (*Region, error) {
 		c.ScheduleCheckLeader()
 		return nil, errors.WithStack(err)
 	}
-	return c.parseRegionResponse(resp), nil
+	return c.cacheRegion(c.parseRegionResponse(resp)), nil
 }
 
 func (c *client) GetPrevRegion(ctx context.Context, key []byte) (*Region, error) {
@@ -476,13 +628,22 @@ func (c *client) GetPrevRegion(ctx context.Context, key []byte) (*Region, error)
 		span = opentracing.StartSpan("pdclient.GetPrevRegion", opentracing.ChildOf(span.Context()))
 		defer span.Finish()
 	}
+	if c.regionCache != nil {
+		if region := c.regionCache.searchPrevRegion(key); region != nil {
+			return region, nil
+		}
+	}
 	start := time.Now()
 	defer func() { cmdDurationGetPrevRegion.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	resp, err := c.leaderClient().GetPrevRegion(ctx, &pdpb.GetRegionRequest{
-		Header:    c.requestHeader(),
-		RegionKey: key,
+	var resp *pdpb.GetRegionResponse
+	err := c.readWithFallback(ctx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.GetPrevRegion(ctx, &pdpb.GetRegionRequest{
+			Header:    c.requestHeader(),
+			RegionKey: key,
+		})
+		return err
 	})
 	cancel()
 
@@ -491,7 +652,7 @@ func (c *client) GetPrevRegion(ctx context.Context, key []byte) (*Region, error)
 		c.ScheduleCheckLeader()
 		return nil, errors.WithStack(err)
 	}
-	return c.parseRegionResponse(resp), nil
+	return c.cacheRegion(c.parseRegionResponse(resp)), nil
 }
 
 func (c *client) GetRegionByID(ctx context.Context, regionID uint64) (*Region, error) {
@@ -499,6 +660,11 @@ func (c *client) GetRegionByID(ctx context.Context, regionID uint64) (*Region, e
 		span = opentracing.StartSpan("pdclient.GetRegionByID", opentracing.ChildOf(span.Context()))
 		defer span.Finish()
 	}
+	if c.regionCache != nil {
+		if region := c.regionCache.getRegion(regionID); region != nil {
+			return region, nil
+		}
+	}
 	start := time.Now()
 	defer func() { cmdDurationGetRegionByID.Observe(time.Since(start).Seconds()) }()
 
@@ -514,7 +680,7 @@ func (c *client) GetRegionByID(ctx context.Context, regionID uint64) (*Region, e
 		c.ScheduleCheckLeader()
 		return nil, errors.WithStack(err)
 	}
-	return c.parseRegionResponse(resp), nil
+	return c.cacheRegion(c.parseRegionResponse(resp)), nil
 }
 
 func (c *client) ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*metapb.Region, []*metapb.Peer, error) {
@@ -532,32 +698,85 @@ func (c *client) ScanRegions(ctx context.Context, key, endKey []byte, limit int)
 		defer cancel()
 	}
 
-	resp, err := c.leaderClient().ScanRegions(scanCtx, &pdpb.ScanRegionsRequest{
-		Header:   c.requestHeader(),
-		StartKey: key,
-		EndKey:   endKey,
-		Limit:    int32(limit),
+	var resp *pdpb.ScanRegionsResponse
+	err := c.readWithFallback(scanCtx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.ScanRegions(ctx, &pdpb.ScanRegionsRequest{
+			Header:   c.requestHeader(),
+			StartKey: key,
+			EndKey:   endKey,
+			Limit:    int32(limit),
+		})
+		return err
 	})
 	if err != nil {
 		cmdFailedDurationScanRegions.Observe(time.Since(start).Seconds())
 		c.ScheduleCheckLeader()
 		return nil, nil, errors.WithStack(err)
 	}
+	if c.regionCache != nil {
+		for i, meta := range resp.GetRegions() {
+			c.cacheRegion(newScannedRegion(meta, resp.GetLeaders(), i))
+		}
+	}
 	return resp.GetRegions(), resp.GetLeaders(), nil
 }
 
+func newScannedRegion(meta *metapb.Region, leaders []*metapb.Peer, i int) *Region {
+	region := &Region{Meta: meta}
+	if i < len(leaders) && leaders[i].GetId() != 0 {
+		region.Leader = leaders[i]
+	}
+	return region
+}
+
+// prefetchRegions scans the regions from the key and caches them, it returns
+// the region contains the key.
+func (c *client) prefetchRegions(ctx context.Context, key []byte) *Region {
+	regions, leaders, err := c.ScanRegions(ctx, key, nil, c.cacheOption.prefetchLimit)
+	if err != nil {
+		log.Warn("[pd] failed to prefetch regions", zap.Error(err))
+		return nil
+	}
+	for i, meta := range regions {
+		if bytes.Compare(key, meta.GetStartKey()) >= 0 &&
+			(len(meta.GetEndKey()) == 0 || bytes.Compare(key, meta.GetEndKey()) < 0) {
+			return newScannedRegion(meta, leaders, i)
+		}
+	}
+	return nil
+}
+
+// cacheRegion caches the region if the cache is enabled, it returns the
+// region.
+func (c *client) cacheRegion(region *Region) *Region {
+	// a region without leader is useless for routing, get it again next time.
+	if c.regionCache != nil && region != nil && region.Leader != nil {
+		c.regionCache.put(region)
+	}
+	return region
+}
+
 func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, error) {
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("pdclient.GetStore", opentracing.ChildOf(span.Context()))
 		defer span.Finish()
 	}
+	if c.storeCache != nil {
+		if store := c.storeCache.get(storeID); store != nil {
+			return store, nil
+		}
+	}
 	start := time.Now()
 	defer func() { cmdDurationGetStore.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	resp, err := c.leaderClient().GetStore(ctx, &pdpb.GetStoreRequest{
-		Header:  c.requestHeader(),
-		StoreId: storeID,
+	var resp *pdpb.GetStoreResponse
+	err := c.readWithFallback(ctx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.GetStore(ctx, &pdpb.GetStoreRequest{
+			Header:  c.requestHeader(),
+			StoreId: storeID,
+		})
+		return err
 	})
 	cancel()
 
@@ -570,6 +789,9 @@ func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, e
 	if store == nil {
 		return nil, errors.New("[pd] store field in rpc response not set")
 	}
+	if c.storeCache != nil {
+		c.storeCache.put(store)
+	}
 	if store.GetState() == metapb.StoreState_Tombstone {
 		return nil, nil
 	}
@@ -603,6 +825,11 @@ func (c *client) GetAllStores(ctx context.Context, opts ...GetStoreOption) ([]*m
 		return nil, errors.WithStack(err)
 	}
 	stores := resp.GetStores()
+	if c.storeCache != nil {
+		for _, store := range stores {
+			c.storeCache.put(store)
+		}
+	}
 	return stores, nil
 }
 
@@ -664,20 +891,58 @@ func (c *client) ScatterRegion(ctx context.Context, regionID uint64) error {
 		span = opentracing.StartSpan("pdclient.ScatterRegion", opentracing.ChildOf(span.Context()))
 		defer span.Finish()
 	}
+	return c.scatterRegion(ctx, regionID, "")
+}
+
+func (c *client) ScatterRegions(ctx context.Context, regionIDs []uint64, group string) error {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.ScatterRegions", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	var errs []string
+	for len(regionIDs) > 0 {
+		batch := regionIDs
+		if len(batch) > maxScatterRegionsPerRequest {
+			batch = batch[:maxScatterRegionsPerRequest]
+		}
+		regionIDs = regionIDs[len(batch):]
+		if err := c.scatterRegions(ctx, batch, group); err != nil {
+			log.Warn("[pd] failed to scatter regions", zap.Uint64s("region-ids", batch), zap.String("group", group), zap.Error(err))
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("scatter regions failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (c *client) scatterRegion(ctx context.Context, regionID uint64, group string) error {
+	return c.scatterRegions(ctx, []uint64{regionID}, group)
+}
+
+// scatterRegions scatters the regions by a single request. A batch of regions
+// is sent by the metadata of the request, the request only carries the first
+// one.
+func (c *client) scatterRegions(ctx context.Context, regionIDs []uint64, group string) error {
 	start := time.Now()
 	defer func() { cmdDurationScatterRegion.Observe(time.Since(start).Seconds()) }()
 
+	ctx = grpcutil.WithScatterGroup(ctx, group)
+	if len(regionIDs) > 1 {
+		ctx = grpcutil.WithScatterRegions(ctx, regionIDs)
+	}
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	resp, err := c.leaderClient().ScatterRegion(ctx, &pdpb.ScatterRegionRequest{
 		Header:   c.requestHeader(),
-		RegionId: regionID,
+		RegionId: regionIDs[0],
 	})
 	cancel()
 	if err != nil {
 		return err
 	}
 	if resp.Header.GetError() != nil {
-		return errors.Errorf("scatter region %d failed: %s", regionID, resp.Header.GetError().String())
+		return errors.Errorf("scatter regions %v failed: %s", regionIDs, resp.Header.GetError().String())
 	}
 	return nil
 }
@@ -698,6 +963,28 @@ func (c *client) GetOperator(ctx context.Context, regionID uint64) (*pdpb.GetOpe
 	})
 }
 
+func (c *client) InvalidateRegion(regionID uint64) {
+	if c.regionCache != nil {
+		c.regionCache.remove(regionID)
+	}
+}
+
+func (c *client) OnEpochNotMatch(regionID uint64, err *errorpb.EpochNotMatch) {
+	if c.regionCache == nil {
+		return
+	}
+	c.regionCache.remove(regionID)
+	for _, region := range err.GetCurrentRegions() {
+		c.regionCache.removeStale(region)
+	}
+}
+
+func (c *client) InvalidateStore(storeID uint64) {
+	if c.storeCache != nil {
+		c.storeCache.remove(storeID)
+	}
+}
+
 func (c *client) requestHeader() *pdpb.RequestHeader {
 	return &pdpb.RequestHeader{
 		ClusterId: c.clusterID,
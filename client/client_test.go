@@ -67,6 +67,31 @@ func (s *testClientSuite) TestUpdateURLs(c *C) {
 	c.Assert(cli.urls, DeepEquals, getURLs([]*pdpb.Member{members[1], members[3], members[2], members[0]}))
 }
 
+func (s *testClientSuite) TestCollectTSORequests(c *C) {
+	cli := &client{
+		baseClient:  &baseClient{tsoOption: tsoOption{maxBatchSize: 3}},
+		tsoRequests: make(chan *tsoRequest, 10),
+	}
+	requests := make([]*tsoRequest, cli.tsoOption.maxBatchSize)
+	for i := 0; i < 4; i++ {
+		cli.tsoRequests <- &tsoRequest{}
+	}
+	// the batch is full.
+	c.Assert(cli.collectTSORequests(context.Background(), &tsoRequest{}, requests), Equals, 3)
+	c.Assert(cli.collectTSORequests(context.Background(), &tsoRequest{}, requests), Equals, 3)
+	c.Assert(cli.collectTSORequests(context.Background(), &tsoRequest{}, requests), Equals, 1)
+
+	// wait for more requests.
+	cli.tsoOption.maxBatchWait = 100 * time.Millisecond
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cli.tsoRequests <- &tsoRequest{}
+	}()
+	start := time.Now()
+	c.Assert(cli.collectTSORequests(context.Background(), &tsoRequest{}, requests), Equals, 2)
+	c.Assert(time.Since(start), GreaterEqual, cli.tsoOption.maxBatchWait)
+}
+
 var _ = Suite(&testClientCtxSuite{})
 
 type testClientCtxSuite struct{}
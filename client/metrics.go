@@ -51,6 +51,31 @@ var (
 			Help:      "Bucketed histogram of the batch size of handled requests.",
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
 		})
+
+	tsoRequestLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "tso_request_latency_seconds",
+			Help:      "Bucketed histogram of the latency (s) of each TSO request.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 15),
+		}, []string{"type"})
+
+	cacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd_client",
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Counter of the requests to the region and store cache.",
+		}, []string{"type", "result"})
+
+	followerReadCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "follower_read_total",
+			Help:      "Counter of the read requests sent to the followers.",
+		}, []string{"result"})
 )
 
 var (
@@ -79,6 +104,19 @@ var (
 	cmdFailedDurationUpdateGCSafePoint        = cmdFailedDuration.WithLabelValues("update_gc_safe_point")
 	cmdFailedDurationUpdateServiceGCSafePoint = cmdFailedDuration.WithLabelValues("update_service_gc_safe_point")
 	requestDurationTSO                        = requestDuration.WithLabelValues("tso")
+
+	// tsoRequestBatchWait is the time from a TSO request is created to it is sent.
+	tsoRequestBatchWait = tsoRequestLatency.WithLabelValues("batch_wait")
+	// tsoRequestResponse is the time from a TSO request is created to it is responded.
+	tsoRequestResponse = tsoRequestLatency.WithLabelValues("response")
+
+	regionCacheHit  = cacheCounter.WithLabelValues("region", "hit")
+	regionCacheMiss = cacheCounter.WithLabelValues("region", "miss")
+	storeCacheHit   = cacheCounter.WithLabelValues("store", "hit")
+	storeCacheMiss  = cacheCounter.WithLabelValues("store", "miss")
+
+	followerReadServed   = followerReadCounter.WithLabelValues("served")
+	followerReadFallback = followerReadCounter.WithLabelValues("fallback")
 )
 
 func init() {
@@ -86,4 +124,7 @@ func init() {
 	prometheus.MustRegister(cmdFailedDuration)
 	prometheus.MustRegister(requestDuration)
 	prometheus.MustRegister(tsoBatchSize)
+	prometheus.MustRegister(tsoRequestLatency)
+	prometheus.MustRegister(cacheCounter)
+	prometheus.MustRegister(followerReadCounter)
 }
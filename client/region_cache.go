@@ -0,0 +1,306 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/btree"
+)
+
+const (
+	defaultRegionCacheTTL  = 10 * time.Minute
+	defaultStoreCacheTTL   = 30 * time.Second
+	regionCacheBTreeDegree = 64
+)
+
+// cacheOption is the options of the region and store cache.
+type cacheOption struct {
+	enable    bool
+	regionTTL time.Duration
+	storeTTL  time.Duration
+	// prefetchLimit is the number of the regions scanned from the missed key
+	// when the region cache misses, 0 means only to get the missed region.
+	prefetchLimit int
+}
+
+// WithCache enables the region and store cache of the client. A cached region
+// is returned until it expires after regionTTL or is invalidated by the caller
+// through RegionCacheClient, and a cached store is returned until it expires
+// after storeTTL. Zero TTLs mean the default TTLs.
+func WithCache(regionTTL, storeTTL time.Duration) ClientOption {
+	return func(c *baseClient) {
+		c.cacheOption.enable = true
+		if regionTTL > 0 {
+			c.cacheOption.regionTTL = regionTTL
+		}
+		if storeTTL > 0 {
+			c.cacheOption.storeTTL = storeTTL
+		}
+	}
+}
+
+// WithRegionPrefetch makes the client scan limit regions from the missed key
+// and cache them when the region cache misses. It only works with WithCache.
+func WithRegionPrefetch(limit int) ClientOption {
+	return func(c *baseClient) {
+		c.cacheOption.prefetchLimit = limit
+	}
+}
+
+type regionCacheItem struct {
+	region *Region
+	expire time.Time
+}
+
+// Less returns true if the region start key is less than the other.
+func (r *regionCacheItem) Less(other btree.Item) bool {
+	return bytes.Compare(r.region.Meta.GetStartKey(), other.(*regionCacheItem).region.Meta.GetStartKey()) < 0
+}
+
+func (r *regionCacheItem) contains(key []byte) bool {
+	start, end := r.region.Meta.GetStartKey(), r.region.Meta.GetEndKey()
+	return bytes.Compare(key, start) >= 0 && (len(end) == 0 || bytes.Compare(key, end) < 0)
+}
+
+func newSearchItem(key []byte) *regionCacheItem {
+	return &regionCacheItem{region: &Region{Meta: &metapb.Region{StartKey: key}}}
+}
+
+// regionCache caches the regions by the key ranges and the ids.
+type regionCache struct {
+	sync.RWMutex
+	tree    *btree.BTree
+	regions map[uint64]*regionCacheItem
+	ttl     time.Duration
+}
+
+func newRegionCache(ttl time.Duration) *regionCache {
+	return &regionCache{
+		tree:    btree.New(regionCacheBTreeDegree),
+		regions: make(map[uint64]*regionCacheItem),
+		ttl:     ttl,
+	}
+}
+
+// find returns the item contains the key, it may be expired.
+func (c *regionCache) find(key []byte) *regionCacheItem {
+	var result *regionCacheItem
+	c.tree.DescendLessOrEqual(newSearchItem(key), func(i btree.Item) bool {
+		result = i.(*regionCacheItem)
+		return false
+	})
+	if result == nil || !result.contains(key) {
+		return nil
+	}
+	return result
+}
+
+// searchRegion returns the cached region contains the key.
+func (c *regionCache) searchRegion(key []byte) *Region {
+	c.RLock()
+	defer c.RUnlock()
+	item := c.find(key)
+	if item == nil || time.Now().After(item.expire) {
+		regionCacheMiss.Inc()
+		return nil
+	}
+	regionCacheHit.Inc()
+	return item.region
+}
+
+// searchPrevRegion returns the cached region before the region contains the
+// key, the two regions should be adjacent.
+func (c *regionCache) searchPrevRegion(key []byte) *Region {
+	c.RLock()
+	defer c.RUnlock()
+	cur := c.find(key)
+	if cur == nil || time.Now().After(cur.expire) {
+		regionCacheMiss.Inc()
+		return nil
+	}
+	var prev *regionCacheItem
+	c.tree.DescendLessOrEqual(cur, func(i btree.Item) bool {
+		if i == btree.Item(cur) {
+			return true
+		}
+		prev = i.(*regionCacheItem)
+		return false
+	})
+	if prev == nil || time.Now().After(prev.expire) ||
+		!bytes.Equal(prev.region.Meta.GetEndKey(), cur.region.Meta.GetStartKey()) {
+		regionCacheMiss.Inc()
+		return nil
+	}
+	regionCacheHit.Inc()
+	return prev.region
+}
+
+// getRegion returns the cached region by the id.
+func (c *regionCache) getRegion(regionID uint64) *Region {
+	c.RLock()
+	defer c.RUnlock()
+	item, ok := c.regions[regionID]
+	if !ok || time.Now().After(item.expire) {
+		regionCacheMiss.Inc()
+		return nil
+	}
+	regionCacheHit.Inc()
+	return item.region
+}
+
+// put caches the region, the cached regions overlapped with it are removed.
+// The region is not cached if it is older than a cached one, e.g. it is got by
+// a slow request or from a follower.
+func (c *regionCache) put(region *Region) {
+	if region == nil || region.Meta == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	if item, ok := c.regions[region.Meta.GetId()]; ok && !now.After(item.expire) && isStaleEpoch(region.Meta, item.region.Meta, true) {
+		return
+	}
+	item := &regionCacheItem{region: region, expire: now.Add(c.ttl)}
+	start := c.find(region.Meta.GetStartKey())
+	if start == nil {
+		start = item
+	}
+	var overlaps []*regionCacheItem
+	endKey := region.Meta.GetEndKey()
+	c.tree.AscendGreaterOrEqual(start, func(i btree.Item) bool {
+		over := i.(*regionCacheItem)
+		if len(endKey) > 0 && bytes.Compare(endKey, over.region.Meta.GetStartKey()) <= 0 {
+			return false
+		}
+		overlaps = append(overlaps, over)
+		return true
+	})
+	for _, over := range overlaps {
+		if !now.After(over.expire) && isStaleEpoch(region.Meta, over.region.Meta, over.region.Meta.GetId() == region.Meta.GetId()) {
+			return
+		}
+	}
+	c.removeLocked(region.Meta.GetId())
+	for _, over := range overlaps {
+		c.removeLocked(over.region.Meta.GetId())
+	}
+	c.tree.ReplaceOrInsert(item)
+	c.regions[region.Meta.GetId()] = item
+}
+
+// isStaleEpoch returns true if the region is older than the cached one. The
+// conf versions are only comparable for the same region, while the versions
+// increase by the splits and merges of the overlapped regions.
+func isStaleEpoch(region, cached *metapb.Region, sameRegion bool) bool {
+	if region.GetRegionEpoch().GetVersion() < cached.GetRegionEpoch().GetVersion() {
+		return true
+	}
+	return sameRegion && region.GetRegionEpoch().GetConfVer() < cached.GetRegionEpoch().GetConfVer()
+}
+
+// remove removes the region from the cache.
+func (c *regionCache) remove(regionID uint64) {
+	c.Lock()
+	defer c.Unlock()
+	c.removeLocked(regionID)
+}
+
+func (c *regionCache) removeLocked(regionID uint64) {
+	item, ok := c.regions[regionID]
+	if !ok {
+		return
+	}
+	delete(c.regions, regionID)
+	c.tree.Delete(item)
+}
+
+// removeStale removes the cached regions overlapped with the region if they
+// are older than it.
+func (c *regionCache) removeStale(region *metapb.Region) {
+	c.Lock()
+	defer c.Unlock()
+	var stales []uint64
+	start := c.find(region.GetStartKey())
+	if start == nil {
+		start = newSearchItem(region.GetStartKey())
+	}
+	endKey := region.GetEndKey()
+	c.tree.AscendGreaterOrEqual(start, func(i btree.Item) bool {
+		over := i.(*regionCacheItem)
+		if len(endKey) > 0 && bytes.Compare(endKey, over.region.Meta.GetStartKey()) <= 0 {
+			return false
+		}
+		if over.region.Meta.GetId() != region.GetId() ||
+			over.region.Meta.GetRegionEpoch().GetVersion() < region.GetRegionEpoch().GetVersion() ||
+			over.region.Meta.GetRegionEpoch().GetConfVer() < region.GetRegionEpoch().GetConfVer() {
+			stales = append(stales, over.region.Meta.GetId())
+		}
+		return true
+	})
+	for _, id := range stales {
+		c.removeLocked(id)
+	}
+}
+
+type storeCacheItem struct {
+	store  *metapb.Store
+	expire time.Time
+}
+
+// storeCache caches the stores by the ids.
+type storeCache struct {
+	sync.RWMutex
+	stores map[uint64]*storeCacheItem
+	ttl    time.Duration
+}
+
+func newStoreCache(ttl time.Duration) *storeCache {
+	return &storeCache{
+		stores: make(map[uint64]*storeCacheItem),
+		ttl:    ttl,
+	}
+}
+
+func (c *storeCache) get(storeID uint64) *metapb.Store {
+	c.RLock()
+	defer c.RUnlock()
+	item, ok := c.stores[storeID]
+	if !ok || time.Now().After(item.expire) {
+		storeCacheMiss.Inc()
+		return nil
+	}
+	storeCacheHit.Inc()
+	return item.store
+}
+
+func (c *storeCache) put(store *metapb.Store) {
+	c.Lock()
+	defer c.Unlock()
+	if store.GetState() == metapb.StoreState_Tombstone {
+		delete(c.stores, store.GetId())
+		return
+	}
+	c.stores[store.GetId()] = &storeCacheItem{store: store, expire: time.Now().Add(c.ttl)}
+}
+
+func (c *storeCache) remove(storeID uint64) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.stores, storeID)
+}
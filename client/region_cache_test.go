@@ -0,0 +1,122 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testRegionCacheSuite{})
+
+type testRegionCacheSuite struct{}
+
+func newCachedRegion(id uint64, start, end string, version uint64) *Region {
+	return &Region{
+		Meta: &metapb.Region{
+			Id:          id,
+			StartKey:    []byte(start),
+			EndKey:      []byte(end),
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: version},
+		},
+	}
+}
+
+func (s *testRegionCacheSuite) TestRegionCache(c *C) {
+	cache := newRegionCache(time.Minute)
+	cache.put(newCachedRegion(1, "", "b", 1))
+	cache.put(newCachedRegion(2, "b", "d", 1))
+	cache.put(newCachedRegion(3, "e", "", 1))
+
+	c.Assert(cache.searchRegion([]byte("a")).Meta.GetId(), Equals, uint64(1))
+	c.Assert(cache.searchRegion([]byte("b")).Meta.GetId(), Equals, uint64(2))
+	c.Assert(cache.searchRegion([]byte("d")), IsNil)
+	c.Assert(cache.searchRegion([]byte("z")).Meta.GetId(), Equals, uint64(3))
+	c.Assert(cache.getRegion(2).Meta.GetId(), Equals, uint64(2))
+	c.Assert(cache.getRegion(4), IsNil)
+
+	c.Assert(cache.searchPrevRegion([]byte("c")).Meta.GetId(), Equals, uint64(1))
+	c.Assert(cache.searchPrevRegion([]byte("a")), IsNil)
+	// region 2 and 3 are not adjacent.
+	c.Assert(cache.searchPrevRegion([]byte("e")), IsNil)
+
+	// the merged region replaces the overlapped regions.
+	cache.put(newCachedRegion(4, "a", "f", 2))
+	c.Assert(cache.getRegion(1), IsNil)
+	c.Assert(cache.getRegion(2), IsNil)
+	c.Assert(cache.getRegion(3), IsNil)
+	c.Assert(cache.searchRegion([]byte("c")).Meta.GetId(), Equals, uint64(4))
+	c.Assert(cache.tree.Len(), Equals, 1)
+
+	// the stale regions overlapped with a newer one are not cached.
+	cache.put(newCachedRegion(1, "", "b", 1))
+	c.Assert(cache.getRegion(1), IsNil)
+	cache.put(newCachedRegion(4, "a", "f", 1))
+	c.Assert(cache.getRegion(4).Meta.GetRegionEpoch().GetVersion(), Equals, uint64(2))
+	stale := newCachedRegion(4, "a", "f", 2)
+	stale.Meta.RegionEpoch.ConfVer = 0
+	cache.put(stale)
+	c.Assert(cache.getRegion(4).Meta.GetRegionEpoch().GetConfVer(), Equals, uint64(1))
+	// the conf versions of different regions are not compared.
+	other := newCachedRegion(5, "e", "g", 2)
+	other.Meta.RegionEpoch.ConfVer = 0
+	cache.put(other)
+	c.Assert(cache.getRegion(5), NotNil)
+	c.Assert(cache.getRegion(4), IsNil)
+	cache.remove(5)
+	cache.put(newCachedRegion(4, "a", "f", 2))
+
+	cache.remove(4)
+	c.Assert(cache.searchRegion([]byte("c")), IsNil)
+	c.Assert(cache.tree.Len(), Equals, 0)
+	c.Assert(cache.regions, HasLen, 0)
+}
+
+func (s *testRegionCacheSuite) TestRemoveStale(c *C) {
+	cache := newRegionCache(time.Minute)
+	cache.put(newCachedRegion(1, "", "b", 1))
+	cache.put(newCachedRegion(2, "b", "d", 1))
+	cache.put(newCachedRegion(3, "d", "", 1))
+
+	// region 2 is split into [b, c) and [c, d).
+	cache.removeStale(newCachedRegion(2, "b", "c", 2).Meta)
+	c.Assert(cache.getRegion(2), IsNil)
+	c.Assert(cache.getRegion(1), NotNil)
+	c.Assert(cache.getRegion(3), NotNil)
+
+	cache.put(newCachedRegion(2, "b", "c", 2))
+	cache.removeStale(newCachedRegion(2, "b", "c", 2).Meta)
+	c.Assert(cache.getRegion(2), NotNil)
+	cache.removeStale(newCachedRegion(4, "c", "d", 2).Meta)
+	c.Assert(cache.getRegion(2), NotNil)
+	c.Assert(cache.getRegion(3), NotNil)
+}
+
+func (s *testRegionCacheSuite) TestCacheExpire(c *C) {
+	regions := newRegionCache(10 * time.Millisecond)
+	regions.put(newCachedRegion(1, "", "", 1))
+	stores := newStoreCache(10 * time.Millisecond)
+	stores.put(&metapb.Store{Id: 1})
+	stores.put(&metapb.Store{Id: 2, State: metapb.StoreState_Tombstone})
+	c.Assert(regions.searchRegion([]byte("a")), NotNil)
+	c.Assert(stores.get(1), NotNil)
+	c.Assert(stores.get(2), IsNil)
+
+	time.Sleep(20 * time.Millisecond)
+	c.Assert(regions.searchRegion([]byte("a")), IsNil)
+	c.Assert(regions.getRegion(1), IsNil)
+	c.Assert(stores.get(1), IsNil)
+}
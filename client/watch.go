@@ -0,0 +1,223 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	watchChanSize      = 16
+	watchRetryInterval = time.Second
+	// watchKeepAliveTimeout is the timeout to receive a message from the
+	// leader, the leader sends a keepalive message every 10 seconds.
+	watchKeepAliveTimeout = 30 * time.Second
+	watchScanLimit        = 1024
+	// watchLatestIndex is the start index to only watch the latest changes,
+	// it should be the same as syncer.LatestIndex.
+	watchLatestIndex = math.MaxUint64
+	// watchResyncIndex is the start index of the responses which tell the
+	// watcher to rescan its regions, it should be the same as
+	// syncer.ResyncIndex.
+	watchResyncIndex = math.MaxUint64 - 1
+)
+
+var watcherID uint64
+
+// RegionEvent is a changed region received by WatchRegions.
+type RegionEvent struct {
+	Region *metapb.Region
+	// Resync is true if the region is scanned after the watcher loses some
+	// changes, e.g. the changes are removed from the history of the leader or
+	// the leader fails to notify them.
+	Resync bool
+}
+
+// regionWatcher receives the changed regions in its range from the region
+// syncer of the leader, it resumes from the next index after reconnecting.
+type regionWatcher struct {
+	c                *client
+	startKey, endKey []byte
+	// name is the unique name of the watcher used by the region syncer.
+	name      string
+	nextIndex uint64
+	ch        chan []*RegionEvent
+}
+
+func (c *client) WatchRegions(ctx context.Context, startKey, endKey []byte) (<-chan []*RegionEvent, error) {
+	w := &regionWatcher{
+		c:         c,
+		startKey:  startKey,
+		endKey:    endKey,
+		name:      fmt.Sprintf("pd-client-watcher-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&watcherID, 1)),
+		nextIndex: watchLatestIndex,
+		ch:        make(chan []*RegionEvent, watchChanSize),
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, streamCancel, err := w.open(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+		w.run(ctx, stream, streamCancel)
+	}()
+	go func() {
+		// stop the watcher if the client is closed.
+		select {
+		case <-c.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return w.ch, nil
+}
+
+func (w *regionWatcher) open(ctx context.Context) (pdpb.PD_SyncRegionsClient, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := w.c.leaderClient().SyncRegions(grpcutil.WithWatchRange(ctx, w.startKey, w.endKey))
+	if err != nil {
+		cancel()
+		return nil, nil, errors.WithStack(err)
+	}
+	err = stream.Send(&pdpb.SyncRegionRequest{
+		Header:     w.c.requestHeader(),
+		Member:     &pdpb.Member{Name: w.name, ClientUrls: []string{w.name}},
+		StartIndex: w.nextIndex,
+	})
+	if err != nil {
+		cancel()
+		return nil, nil, errors.WithStack(err)
+	}
+	return stream, cancel, nil
+}
+
+func (w *regionWatcher) run(ctx context.Context, stream pdpb.PD_SyncRegionsClient, cancel context.CancelFunc) {
+	defer close(w.ch)
+	for {
+		err := w.recv(ctx, stream, cancel)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn("[pd] region watcher meets error, reconnect later", zap.Uint64("next-index", w.nextIndex), zap.Error(err))
+		w.c.ScheduleCheckLeader()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchRetryInterval):
+			}
+			stream, cancel, err = w.open(ctx)
+			if err == nil {
+				break
+			}
+			log.Warn("[pd] region watcher failed to reconnect", zap.Error(err))
+		}
+	}
+}
+
+// recv receives the changed regions until the stream fails. The stream is
+// canceled if no message is received in time, it happens if the receiver of
+// the events is too slow, then the watcher resumes with a new stream.
+func (w *regionWatcher) recv(ctx context.Context, stream pdpb.PD_SyncRegionsClient, cancel context.CancelFunc) error {
+	defer cancel()
+	timer := time.AfterFunc(watchKeepAliveTimeout, cancel)
+	defer timer.Stop()
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		timer.Reset(watchKeepAliveTimeout)
+
+		var events []*RegionEvent
+		if resp.GetStartIndex() == watchResyncIndex {
+			log.Info("[pd] region watcher loses the changes, resync the regions", zap.Uint64("next-index", w.nextIndex))
+			if events, err = w.scan(ctx); err != nil {
+				return err
+			}
+		} else {
+			// the leader removes the regions out of the range and moves the
+			// start index forward, the next index may go back if the history
+			// overlaps with the latest changes after reconnecting.
+			next := resp.GetStartIndex() + uint64(len(resp.GetRegions()))
+			if w.nextIndex == watchLatestIndex || next > w.nextIndex {
+				w.nextIndex = next
+			}
+			for _, region := range resp.GetRegions() {
+				// the leader of the old version sends all regions.
+				if w.inRange(region) {
+					events = append(events, &RegionEvent{Region: region})
+				}
+			}
+		}
+		if len(events) == 0 {
+			continue
+		}
+		if w.c.regionCache != nil {
+			// the leader may be changed, so the region is always removed.
+			for _, e := range events {
+				w.c.regionCache.remove(e.Region.GetId())
+				w.c.regionCache.removeStale(e.Region)
+			}
+		}
+		select {
+		case w.ch <- events:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// scan scans all regions in the range of the watcher.
+func (w *regionWatcher) scan(ctx context.Context) ([]*RegionEvent, error) {
+	var events []*RegionEvent
+	key := w.startKey
+	for {
+		regions, _, err := w.c.ScanRegions(ctx, key, w.endKey, watchScanLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, region := range regions {
+			events = append(events, &RegionEvent{Region: region, Resync: true})
+		}
+		if len(regions) == 0 {
+			return events, nil
+		}
+		key = regions[len(regions)-1].GetEndKey()
+		if len(key) == 0 || (len(w.endKey) > 0 && bytes.Compare(key, w.endKey) >= 0) {
+			return events, nil
+		}
+	}
+}
+
+func (w *regionWatcher) inRange(region *metapb.Region) bool {
+	return (len(w.endKey) == 0 || bytes.Compare(region.GetStartKey(), w.endKey) < 0) &&
+		(len(region.GetEndKey()) == 0 || bytes.Compare(region.GetEndKey(), w.startKey) > 0)
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testWatchSuite{})
+
+type testWatchSuite struct{}
+
+func (s *testWatchSuite) TestInRange(c *C) {
+	w := &regionWatcher{startKey: []byte("b"), endKey: []byte("d")}
+	cases := []struct {
+		start, end string
+		inRange    bool
+	}{
+		{"", "", true},
+		{"", "b", false},
+		{"", "b1", true},
+		{"c", "", true},
+		{"d", "", false},
+		{"b1", "c", true},
+	}
+	for _, t := range cases {
+		region := &metapb.Region{StartKey: []byte(t.start), EndKey: []byte(t.end)}
+		c.Assert(w.inRange(region), Equals, t.inRange)
+	}
+	w.endKey = nil
+	c.Assert(w.inRange(&metapb.Region{StartKey: []byte("z")}), IsTrue)
+}
@@ -21,9 +21,11 @@ import (
 )
 
 var (
-	tablePrefix  = []byte{'t'}
-	metaPrefix   = []byte{'m'}
-	recordPrefix = []byte{'r'}
+	tablePrefix     = []byte{'t'}
+	metaPrefix      = []byte{'m'}
+	recordPrefix    = []byte{'r'}
+	indexPrefixSep  = []byte("_i")
+	recordPrefixSep = []byte("_r")
 )
 
 const (
@@ -53,6 +55,33 @@ func (k Key) TableID() int64 {
 	return tableID
 }
 
+// IndexID returns the index ID of the key, if the key is not index key, returns 0.
+func (k Key) IndexID() int64 {
+	_, key, err := DecodeBytes(k)
+	if err != nil {
+		return 0
+	}
+	if !bytes.HasPrefix(key, tablePrefix) {
+		return 0
+	}
+	key, _, err = DecodeInt(key[len(tablePrefix):])
+	if err != nil || !bytes.HasPrefix(key, indexPrefixSep) {
+		return 0
+	}
+	_, indexID, _ := DecodeInt(key[len(indexPrefixSep):])
+	return indexID
+}
+
+// IsRecordKey checks if the key is a record key of a table.
+func (k Key) IsRecordKey() bool {
+	_, key, err := DecodeBytes(k)
+	if err != nil || !bytes.HasPrefix(key, tablePrefix) {
+		return false
+	}
+	key, _, err = DecodeInt(key[len(tablePrefix):])
+	return err == nil && bytes.HasPrefix(key, recordPrefixSep)
+}
+
 // MetaOrTable checks if the key is a meta key or table key.
 // If the key is a meta key, it returns true and 0.
 // If the key is a table key, it returns false and table ID.
@@ -184,6 +213,16 @@ func GenerateTableKey(tableID int64) []byte {
 	return buf
 }
 
+// GenerateIndexKey generates an index key.
+func GenerateIndexKey(tableID, indexID int64) []byte {
+	buf := make([]byte, 0, len(tablePrefix)+len(indexPrefixSep)+8*2)
+	buf = append(buf, tablePrefix...)
+	buf = EncodeInt(buf, tableID)
+	buf = append(buf, indexPrefixSep...)
+	buf = EncodeInt(buf, indexID)
+	return buf
+}
+
 // GenerateRowKey generates a row key.
 func GenerateRowKey(tableID, rowID int64) []byte {
 	buf := make([]byte, 0, len(tablePrefix)+len(recordPrefix)+8*2)
@@ -52,3 +52,21 @@ func (s *testCodecSuite) TestTableID(c *C) {
 	key = EncodeBytes([]byte("t\x80\x00\x00\x00\x00\x00\xff"))
 	c.Assert(key.TableID(), Equals, int64(0))
 }
+
+func (s *testCodecSuite) TestIndexID(c *C) {
+	key := EncodeBytes(GenerateIndexKey(0xff, 2))
+	c.Assert(key.TableID(), Equals, int64(0xff))
+	c.Assert(key.IndexID(), Equals, int64(2))
+	c.Assert(key.IsRecordKey(), IsFalse)
+
+	key = EncodeBytes([]byte("t\x80\x00\x00\x00\x00\x00\x00\xff_r\x80\x00\x00\x00\x00\x00\x00\x01"))
+	c.Assert(key.IndexID(), Equals, int64(0))
+	c.Assert(key.IsRecordKey(), IsTrue)
+
+	key = EncodeBytes(GenerateTableKey(0xff))
+	c.Assert(key.IndexID(), Equals, int64(0))
+	c.Assert(key.IsRecordKey(), IsFalse)
+
+	key = GenerateIndexKey(0xff, 2)
+	c.Assert(key.IndexID(), Equals, int64(0))
+}
@@ -16,14 +16,142 @@ package grpcutil
 import (
 	"context"
 	"crypto/tls"
+	"encoding/hex"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/pkg/transport"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 )
 
+const (
+	// scatterGroupKey is the gRPC metadata key of the scatter group. The
+	// group is passed by metadata to keep pdpb.ScatterRegionRequest unchanged.
+	scatterGroupKey = "pd-scatter-group"
+	// scatterRegionsKey is the gRPC metadata key of the regions scattered by
+	// a single ScatterRegion request.
+	scatterRegionsKey = "pd-scatter-regions"
+	// watchRangeKey is the gRPC metadata key of the key range watched by a
+	// SyncRegions stream, the streams of the followers have no range.
+	watchRangeKey = "pd-watch-range"
+	// followerReadKey is the gRPC metadata key which marks a request can be
+	// served by a follower, the value is the max staleness in milliseconds.
+	followerReadKey = "pd-follower-read"
+)
+
+// WithScatterGroup returns a context to send the scatter group to PD.
+func WithScatterGroup(ctx context.Context, group string) context.Context {
+	if group == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, scatterGroupKey, group)
+}
+
+// GetScatterGroup returns the scatter group sent by the client.
+func GetScatterGroup(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(scatterGroupKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// WithScatterRegions returns a context to scatter the regions by a single
+// request.
+func WithScatterRegions(ctx context.Context, regionIDs []uint64) context.Context {
+	if len(regionIDs) == 0 {
+		return ctx
+	}
+	ids := make([]string, 0, len(regionIDs))
+	for _, id := range regionIDs {
+		ids = append(ids, strconv.FormatUint(id, 10))
+	}
+	return metadata.AppendToOutgoingContext(ctx, scatterRegionsKey, strings.Join(ids, ","))
+}
+
+// GetScatterRegions returns the regions to scatter sent by the client.
+func GetScatterRegions(ctx context.Context) ([]uint64, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	values := md.Get(scatterRegionsKey)
+	if len(values) == 0 || values[0] == "" {
+		return nil, nil
+	}
+	var regionIDs []uint64
+	for _, s := range strings.Split(values[0], ",") {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		regionIDs = append(regionIDs, id)
+	}
+	return regionIDs, nil
+}
+
+// WithWatchRange returns a context to watch the regions in [startKey, endKey)
+// by a SyncRegions stream.
+func WithWatchRange(ctx context.Context, startKey, endKey []byte) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, watchRangeKey, hex.EncodeToString(startKey)+","+hex.EncodeToString(endKey))
+}
+
+// GetWatchRange returns the key range watched by the SyncRegions stream, ok is
+// false if the stream is not sent by a watcher.
+func GetWatchRange(ctx context.Context) (startKey, endKey []byte, ok bool, err error) {
+	md, exist := metadata.FromIncomingContext(ctx)
+	if !exist {
+		return nil, nil, false, nil
+	}
+	values := md.Get(watchRangeKey)
+	if len(values) == 0 {
+		return nil, nil, false, nil
+	}
+	keys := strings.Split(values[0], ",")
+	if len(keys) != 2 {
+		return nil, nil, false, errors.Errorf("invalid watch range %s", values[0])
+	}
+	if startKey, err = hex.DecodeString(keys[0]); err != nil {
+		return nil, nil, false, errors.WithStack(err)
+	}
+	if endKey, err = hex.DecodeString(keys[1]); err != nil {
+		return nil, nil, false, errors.WithStack(err)
+	}
+	return startKey, endKey, true, nil
+}
+
+// WithFollowerRead returns a context to send a request which can be served by
+// a follower synced with the leader within maxStaleness.
+func WithFollowerRead(ctx context.Context, maxStaleness time.Duration) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, followerReadKey, strconv.FormatInt(int64(maxStaleness/time.Millisecond), 10))
+}
+
+// IsFollowerRead returns the max staleness of the follower read request, it
+// returns false if the request is not a follower read request.
+func IsFollowerRead(ctx context.Context) (time.Duration, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	values := md.Get(followerReadKey)
+	if len(values) == 0 {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
 // SecurityConfig is the configuration for supporting tls.
 type SecurityConfig struct {
 	// CAPath is the path of file that contains list of trusted SSL CAs. if set, following four settings shouldn't be empty
@@ -559,6 +559,11 @@ func (mc *Cluster) GetStoreLimitByType(storeID uint64, typ storelimit.Type) floa
 	return mc.ScheduleOptions.GetStoreLimitByType(storeID, typ)
 }
 
+// GetStoreLimitAlgorithm mocks method.
+func (mc *Cluster) GetStoreLimitAlgorithm(storeID uint64) storelimit.Algorithm {
+	return mc.ScheduleOptions.GetStoreLimitAlgorithm(storeID)
+}
+
 // CheckLabelProperty checks label property.
 func (mc *Cluster) CheckLabelProperty(typ string, labels []*metapb.StoreLabel) bool {
 	for _, cfg := range mc.LabelProperties[typ] {
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 )
@@ -48,8 +49,20 @@ const (
 
 // StoreLimitConfig is a mock of StoreLimitConfig.
 type StoreLimitConfig struct {
-	AddPeer    float64 `toml:"add-peer" json:"add-peer"`
-	RemovePeer float64 `toml:"remove-peer" json:"remove-peer"`
+	AddPeer         float64 `toml:"add-peer" json:"add-peer"`
+	RemovePeer      float64 `toml:"remove-peer" json:"remove-peer"`
+	SendSnapshot    float64 `toml:"send-snapshot" json:"send-snapshot"`
+	ReceiveSnapshot float64 `toml:"receive-snapshot" json:"receive-snapshot"`
+	Algorithm       string  `toml:"algorithm" json:"algorithm,omitempty"`
+}
+
+func newStoreLimitConfig() StoreLimitConfig {
+	return StoreLimitConfig{
+		AddPeer:         defaultStoreLimit,
+		RemovePeer:      defaultStoreLimit,
+		SendSnapshot:    storelimit.Unlimited,
+		ReceiveSnapshot: storelimit.Unlimited,
+	}
 }
 
 // ScheduleOptions is a mock of ScheduleOptions
@@ -69,6 +82,8 @@ type ScheduleOptions struct {
 	SplitMergeInterval           time.Duration
 	EnableOneWayMerge            bool
 	EnableCrossTableMerge        bool
+	EnableHotRegionSplit         bool
+	HotRegionSplitPolicy         pdpb.CheckPolicy
 	KeyType                      string
 	MaxStoreDownTime             time.Duration
 	MaxReplicas                  int
@@ -79,6 +94,7 @@ type ScheduleOptions struct {
 	LowSpaceRatio                float64
 	HighSpaceRatio               float64
 	StoreLimitMode               string
+	StoreLimitAlgorithm          string
 	EnableRemoveDownReplica      bool
 	EnableReplaceOfflineReplica  bool
 	EnableMakeUpReplica          bool
@@ -124,29 +140,38 @@ func NewScheduleOptions() *ScheduleOptions {
 	mso.EnableLocationReplacement = true
 	mso.LeaderSchedulePolicy = defaultLeaderSchedulePolicy
 	mso.KeyType = defaultKeyType
+	mso.HotRegionSplitPolicy = pdpb.CheckPolicy_APPROXIMATE
 	mso.StoreLimit = make(map[uint64]StoreLimitConfig)
+	mso.StoreLimitAlgorithm = string(storelimit.TokenBucket)
 	return mso
 }
 
 // SetStoreLimit mocks method
 func (mso *ScheduleOptions) SetStoreLimit(storeID uint64, typ storelimit.Type, ratePerMin float64) {
-	var sc StoreLimitConfig
-	if _, ok := mso.StoreLimit[storeID]; ok {
-		switch typ {
-		case storelimit.AddPeer:
-			sc = StoreLimitConfig{AddPeer: ratePerMin, RemovePeer: mso.StoreLimit[storeID].RemovePeer}
-		case storelimit.RemovePeer:
-			sc = StoreLimitConfig{AddPeer: mso.StoreLimit[storeID].AddPeer, RemovePeer: ratePerMin}
-		}
-	} else {
-		switch typ {
-		case storelimit.AddPeer:
-			sc = StoreLimitConfig{AddPeer: ratePerMin, RemovePeer: defaultStoreLimit}
-		case storelimit.RemovePeer:
-			sc = StoreLimitConfig{AddPeer: defaultStoreLimit, RemovePeer: ratePerMin}
-		}
+	sc, ok := mso.StoreLimit[storeID]
+	if !ok {
+		sc = newStoreLimitConfig()
 	}
+	switch typ {
+	case storelimit.AddPeer:
+		sc.AddPeer = ratePerMin
+	case storelimit.RemovePeer:
+		sc.RemovePeer = ratePerMin
+	case storelimit.SendSnapshot:
+		sc.SendSnapshot = ratePerMin
+	case storelimit.ReceiveSnapshot:
+		sc.ReceiveSnapshot = ratePerMin
+	}
+	mso.StoreLimit[storeID] = sc
+}
 
+// SetStoreLimitAlgorithm mocks method
+func (mso *ScheduleOptions) SetStoreLimitAlgorithm(storeID uint64, algorithm string) {
+	sc, ok := mso.StoreLimit[storeID]
+	if !ok {
+		sc = newStoreLimitConfig()
+	}
+	sc.Algorithm = algorithm
 	mso.StoreLimit[storeID] = sc
 }
 
@@ -154,13 +179,23 @@ func (mso *ScheduleOptions) SetStoreLimit(storeID uint64, typ storelimit.Type, r
 func (mso *ScheduleOptions) SetAllStoresLimit(typ storelimit.Type, ratePerMin float64) {
 	switch typ {
 	case storelimit.AddPeer:
-		for storeID := range mso.StoreLimit {
-			sc := StoreLimitConfig{AddPeer: ratePerMin, RemovePeer: mso.StoreLimit[storeID].RemovePeer}
+		for storeID, sc := range mso.StoreLimit {
+			sc.AddPeer = ratePerMin
 			mso.StoreLimit[storeID] = sc
 		}
 	case storelimit.RemovePeer:
-		for storeID := range mso.StoreLimit {
-			sc := StoreLimitConfig{AddPeer: mso.StoreLimit[storeID].AddPeer, RemovePeer: ratePerMin}
+		for storeID, sc := range mso.StoreLimit {
+			sc.RemovePeer = ratePerMin
+			mso.StoreLimit[storeID] = sc
+		}
+	case storelimit.SendSnapshot:
+		for storeID, sc := range mso.StoreLimit {
+			sc.SendSnapshot = ratePerMin
+			mso.StoreLimit[storeID] = sc
+		}
+	case storelimit.ReceiveSnapshot:
+		for storeID, sc := range mso.StoreLimit {
+			sc.ReceiveSnapshot = ratePerMin
 			mso.StoreLimit[storeID] = sc
 		}
 	}
@@ -195,6 +230,10 @@ func (mso *ScheduleOptions) GetHotRegionScheduleLimit() uint64 {
 func (mso *ScheduleOptions) GetStoreLimitByType(storeID uint64, typ storelimit.Type) float64 {
 	limit, ok := mso.StoreLimit[storeID]
 	if !ok {
+		// snapshots are not limited by default
+		if typ == storelimit.SendSnapshot || typ == storelimit.ReceiveSnapshot {
+			return storelimit.Unlimited
+		}
 		return 0
 	}
 	switch typ {
@@ -202,11 +241,28 @@ func (mso *ScheduleOptions) GetStoreLimitByType(storeID uint64, typ storelimit.T
 		return limit.AddPeer
 	case storelimit.RemovePeer:
 		return limit.RemovePeer
+	case storelimit.SendSnapshot:
+		return limit.SendSnapshot
+	case storelimit.ReceiveSnapshot:
+		return limit.ReceiveSnapshot
 	default:
 		panic("no such limit type")
 	}
 }
 
+// GetStoreLimitAlgorithm mocks method
+func (mso *ScheduleOptions) GetStoreLimitAlgorithm(storeID uint64) storelimit.Algorithm {
+	name := mso.StoreLimitAlgorithm
+	if limit, ok := mso.StoreLimit[storeID]; ok && limit.Algorithm != "" {
+		name = limit.Algorithm
+	}
+	algorithm, err := storelimit.ParseAlgorithm(name)
+	if err != nil {
+		return storelimit.TokenBucket
+	}
+	return algorithm
+}
+
 // GetMaxSnapshotCount mocks method
 func (mso *ScheduleOptions) GetMaxSnapshotCount() uint64 {
 	return mso.MaxSnapshotCount
@@ -242,6 +298,16 @@ func (mso *ScheduleOptions) IsCrossTableMergeEnabled() bool {
 	return mso.EnableCrossTableMerge
 }
 
+// IsHotRegionSplitEnabled mocks method
+func (mso *ScheduleOptions) IsHotRegionSplitEnabled() bool {
+	return mso.EnableHotRegionSplit
+}
+
+// GetHotRegionSplitPolicy mocks method
+func (mso *ScheduleOptions) GetHotRegionSplitPolicy() pdpb.CheckPolicy {
+	return mso.HotRegionSplitPolicy
+}
+
 // GetMaxStoreDownTime mocks method
 func (mso *ScheduleOptions) GetMaxStoreDownTime() time.Duration {
 	return mso.MaxStoreDownTime
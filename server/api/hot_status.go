@@ -14,9 +14,13 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
 	"github.com/unrolled/render"
 )
 
@@ -77,3 +81,67 @@ func (h *hotStatusHandler) GetHotStores(w http.ResponseWriter, r *http.Request)
 	}
 	h.rd.JSON(w, http.StatusOK, stats)
 }
+
+// @Tags hotspot
+// @Summary List the hot regions recorded in the history.
+// @Param start query integer false "Start time in unix seconds, inclusive"
+// @Param end query integer false "End time in unix seconds, exclusive"
+// @Param region query integer false "Region ID"
+// @Param store query integer false "Store ID"
+// @Param hot_type query string false "Hot region type, read or write"
+// @Produce json
+// @Success 200 {array} core.HistoryHotRegion
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /hotspot/regions/history [get]
+func (h *hotStatusHandler) GetHistoryHotRegions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, end := time.Unix(0, 0), time.Now().Add(time.Second)
+	for name, t := range map[string]*time.Time{"start": &start, "end": &end} {
+		if str := query.Get(name); str != "" {
+			ts, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %s", name, str))
+				return
+			}
+			*t = time.Unix(ts, 0)
+		}
+	}
+	var regionID, storeID uint64
+	for name, id := range map[string]*uint64{"region": &regionID, "store": &storeID} {
+		if str := query.Get(name); str != "" {
+			v, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %s", name, str))
+				return
+			}
+			*id = v
+		}
+	}
+	hotRegionTypes := core.HotRegionTypes
+	switch typ := query.Get("hot_type"); typ {
+	case "":
+	case core.HotReadRegionType, core.HotWriteRegionType:
+		hotRegionTypes = []string{typ}
+	default:
+		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid hot_type: %s", typ))
+		return
+	}
+
+	regions, err := h.Handler.GetHistoryHotRegions(hotRegionTypes, start, end)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	results := make([]*core.HistoryHotRegion, 0, len(regions))
+	for _, region := range regions {
+		if regionID != 0 && region.RegionID != regionID {
+			continue
+		}
+		if storeID != 0 && region.StoreID != storeID {
+			continue
+		}
+		results = append(results, region)
+	}
+	h.rd.JSON(w, http.StatusOK, results)
+}
@@ -15,9 +15,11 @@ package api
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
 	_ "github.com/pingcap/pd/v4/server/schedulers"
 )
 
@@ -48,3 +50,36 @@ func (s testHotStatusSuite) TestGetHotStore(c *C) {
 	err := readJSON(testDialClient, s.urlPrefix+"/stores", &stat)
 	c.Assert(err, IsNil)
 }
+
+func (s testHotStatusSuite) TestGetHistoryHotRegions(c *C) {
+	now := time.Now()
+	updateTime := now.Add(-time.Minute).UnixNano() / int64(time.Millisecond)
+	regions := []core.HistoryHotRegion{
+		{UpdateTime: updateTime, RegionID: 1, StoreID: 1, IsLeader: true, HotRegionType: core.HotReadRegionType},
+		{UpdateTime: updateTime, RegionID: 2, StoreID: 1, IsLeader: true, HotRegionType: core.HotWriteRegionType},
+		{UpdateTime: updateTime, RegionID: 2, StoreID: 2, HotRegionType: core.HotWriteRegionType},
+	}
+	c.Assert(s.svr.GetHotRegionStorage().SaveHotRegions(regions), IsNil)
+
+	url := fmt.Sprintf("%s/regions/history?start=%d", s.urlPrefix, now.Add(-time.Hour).Unix())
+	var results []*core.HistoryHotRegion
+	c.Assert(readJSON(testDialClient, url, &results), IsNil)
+	c.Assert(results, HasLen, 3)
+
+	results = nil
+	c.Assert(readJSON(testDialClient, url+"&hot_type=write&store=1", &results), IsNil)
+	c.Assert(results, HasLen, 1)
+	c.Assert(*results[0], DeepEquals, regions[1])
+
+	results = nil
+	c.Assert(readJSON(testDialClient, url+"&region=2", &results), IsNil)
+	c.Assert(results, HasLen, 2)
+
+	results = nil
+	url = fmt.Sprintf("%s/regions/history?end=%d", s.urlPrefix, now.Add(-time.Hour).Unix())
+	c.Assert(readJSON(testDialClient, url, &results), IsNil)
+	c.Assert(results, HasLen, 0)
+
+	err := readJSON(testDialClient, url+"&hot_type=unknown", &results)
+	c.Assert(err, NotNil)
+}
@@ -14,8 +14,10 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
@@ -106,6 +108,72 @@ func (h *operatorHandler) List(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, results)
 }
 
+// @Tags operator
+// @Summary List the records of finished operators.
+// @Param start query integer false "Start Unix timestamp, inclusive"
+// @Param end query integer false "End Unix timestamp, exclusive"
+// @Param region query integer false "Region ID"
+// @Param store query integer false "Store ID"
+// @Param kind query string false "Operator kind, such as leader or region"
+// @Produce json
+// @Success 200 {array} operator.OpRecord
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /operators/history [get]
+func (h *operatorHandler) History(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, end := time.Unix(0, 0), time.Now().Add(time.Second)
+	for name, t := range map[string]*time.Time{"start": &start, "end": &end} {
+		if str := query.Get(name); str != "" {
+			ts, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				h.r.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %s", name, str))
+				return
+			}
+			*t = time.Unix(ts, 0)
+		}
+	}
+	var regionID, storeID uint64
+	for name, id := range map[string]*uint64{"region": &regionID, "store": &storeID} {
+		if str := query.Get(name); str != "" {
+			v, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				h.r.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %s", name, str))
+				return
+			}
+			*id = v
+		}
+	}
+	var kind operator.OpKind
+	if str := query.Get("kind"); str != "" {
+		var err error
+		if kind, err = operator.ParseOperatorKind(str); err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	records, err := h.GetOperatorRecords(start, end)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	results := make([]*operator.OpRecord, 0, len(records))
+	for _, record := range records {
+		if regionID != 0 && record.RegionID != regionID {
+			continue
+		}
+		if storeID != 0 && !record.HasStore(storeID) {
+			continue
+		}
+		if kind != 0 && !record.MatchKind(kind) {
+			continue
+		}
+		results = append(results, record)
+	}
+	h.r.JSON(w, http.StatusOK, results)
+}
+
 // FIXME: details of input json body params
 // @Tags operator
 // @Summary Create an operator.
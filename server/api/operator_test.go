@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/failpoint"
@@ -27,6 +28,7 @@ import (
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
 )
 
 var _ = Suite(&testOperatorSuite{})
@@ -135,6 +137,52 @@ func (s *testOperatorSuite) TestMergeRegionOperator(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testOperatorSuite) TestOperatorHistory(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	mustPutStore(c, s.svr, 2, metapb.StoreState_Up, nil)
+	// a newer version so it is not stale even if other tests cover the range.
+	r := newTestRegionInfo(40, 1, []byte("x"), []byte("y"), core.SetRegionVersion(10))
+	mustRegionHeartbeat(c, s.svr, r)
+
+	err := postJSON(testDialClient, fmt.Sprintf("%s/operators", s.urlPrefix), []byte(`{"name":"add-peer", "region_id": 40, "store_id": 2}`))
+	c.Assert(err, IsNil)
+	_, err = doDelete(testDialClient, fmt.Sprintf("%s/operators/%d", s.urlPrefix, 40))
+	c.Assert(err, IsNil)
+
+	historyURL := fmt.Sprintf("%s/operators/history", s.urlPrefix)
+	check := func() {
+		var records []*operator.OpRecord
+		c.Assert(readJSON(testDialClient, historyURL+"?region=40", &records), IsNil)
+		c.Assert(records, HasLen, 1)
+		c.Assert(records[0].RegionID, Equals, uint64(40))
+		c.Assert(records[0].Desc, Equals, "admin-add-peer")
+		c.Assert(records[0].Status, Equals, "Canceled")
+		c.Assert(records[0].Stores, DeepEquals, []uint64{2})
+		c.Assert(records[0].Steps, HasLen, 2)
+		finishTime := records[0].FinishTime
+
+		c.Assert(readJSON(testDialClient, historyURL+"?region=40&store=2&kind=region", &records), IsNil)
+		c.Assert(records, HasLen, 1)
+		c.Assert(readJSON(testDialClient, historyURL+"?region=40&store=1", &records), IsNil)
+		c.Assert(records, HasLen, 0)
+		c.Assert(readJSON(testDialClient, historyURL+"?region=40&kind=leader", &records), IsNil)
+		c.Assert(records, HasLen, 0)
+		url := fmt.Sprintf("%s?region=40&end=%d", historyURL, finishTime.Add(-time.Minute).Unix())
+		c.Assert(readJSON(testDialClient, url, &records), IsNil)
+		c.Assert(records, HasLen, 0)
+	}
+	// the record is pending in memory.
+	check()
+	// the record is persisted.
+	oc, err := s.svr.GetHandler().GetOperatorController()
+	c.Assert(err, IsNil)
+	oc.PruneHistory()
+	check()
+
+	c.Assert(readJSON(testDialClient, historyURL+"?start=abc", &[]*operator.OpRecord{}), NotNil)
+	c.Assert(readJSON(testDialClient, historyURL+"?kind=foo", &[]*operator.OpRecord{}), NotNil)
+}
+
 func mustPutStore(c *C, svr *server.Server, id uint64, state metapb.StoreState, labels []*metapb.StoreLabel) {
 	_, err := svr.PutStore(context.Background(), &pdpb.PutStoreRequest{
 		Header: &pdpb.RequestHeader{ClusterId: svr.ClusterID()},
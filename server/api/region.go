@@ -15,6 +15,7 @@ package api
 
 import (
 	"container/heap"
+	"encoding/hex"
 	"net/http"
 	"net/url"
 	"sort"
@@ -24,6 +25,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/kvproto/pkg/replication_modepb"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/unrolled/render"
@@ -235,6 +237,65 @@ func (h *regionsHandler) GetRegionCount(w http.ResponseWriter, r *http.Request)
 	h.rd.JSON(w, http.StatusOK, &RegionsInfo{Count: count})
 }
 
+// ScatterRegionsInput is the input of scattering regions. The regions are
+// specified by IDs, or by a key range in hex format if no ID is given.
+type ScatterRegionsInput struct {
+	RegionIDs   []uint64 `json:"regions_id"`
+	StartKeyHex string   `json:"start_key"`
+	EndKeyHex   string   `json:"end_key"`
+	// Group balances the peers and leaders among the regions of the group.
+	Group string `json:"group"`
+	// Limit is the max count of regions scattered in the key range, it is
+	// capped by 10000.
+	Limit int `json:"limit"`
+}
+
+// ScatterRegionsOutput is the result of scattering regions.
+type ScatterRegionsOutput struct {
+	OperatorCount int               `json:"operator_count"`
+	Failures      map[uint64]string `json:"failures,omitempty"`
+	// NextKeyHex is the start key in hex format of the regions not scattered
+	// yet if the key range has too many regions, send it as the start key to
+	// continue.
+	NextKeyHex string `json:"next_key,omitempty"`
+}
+
+// @Tags region
+// @Summary Scatter a batch of regions as a group.
+// @Accept json
+// @Param body body ScatterRegionsInput true "regions and group"
+// @Produce json
+// @Success 200 {object} ScatterRegionsOutput
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /regions/scatter [post]
+func (h *regionsHandler) ScatterRegions(w http.ResponseWriter, r *http.Request) {
+	var input ScatterRegionsInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if len(input.RegionIDs) == 0 && input.StartKeyHex == "" && input.EndKeyHex == "" {
+		h.rd.JSON(w, http.StatusBadRequest, "missing regions_id or key range")
+		return
+	}
+	startKey, err := hex.DecodeString(input.StartKeyHex)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "start key should be in hex format")
+		return
+	}
+	endKey, err := hex.DecodeString(input.EndKeyHex)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "end key should be in hex format")
+		return
+	}
+	count, failures, nextKey, err := h.svr.GetHandler().ScatterRegions(input.RegionIDs, startKey, endKey, input.Group, input.Limit)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, &ScatterRegionsOutput{OperatorCount: count, Failures: failures, NextKeyHex: hex.EncodeToString(nextKey)})
+}
+
 // @Tags region
 // @Summary List all regions of a specific store.
 // @Param id path integer true "Store Id"
@@ -15,6 +15,8 @@ package api
 
 import (
 	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -428,3 +430,87 @@ func BenchmarkHexRegionKeyStr(b *testing.B) {
 		_ = core.HexRegionKeyStr(key)
 	}
 }
+
+var _ = Suite(&testScatterRegionsSuite{})
+
+type testScatterRegionsSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testScatterRegionsSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testScatterRegionsSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testScatterRegionsSuite) TestScatterRegions(c *C) {
+	for id := uint64(1); id <= 6; id++ {
+		mustPutStore(c, s.svr, id, metapb.StoreState_Up, nil)
+	}
+	// Both regions are on store 1, 2 and 3.
+	for i, id := range []uint64{601, 602} {
+		key := []byte{'s', byte('a' + i)}
+		r := newTestRegionInfo(id, 1, key, append(key, 'z'), core.SetWrittenBytes(0), core.SetReadBytes(0),
+			core.WithAddPeer(&metapb.Peer{Id: id*10 + 2, StoreId: 2}),
+			core.WithAddPeer(&metapb.Peer{Id: id*10 + 3, StoreId: 3}))
+		mustRegionHeartbeat(c, s.svr, r)
+	}
+
+	scatterURL := fmt.Sprintf("%s/regions/scatter", s.urlPrefix)
+	c.Assert(postJSON(testDialClient, scatterURL, []byte(`{"group": "t"}`)), NotNil)
+
+	var output ScatterRegionsOutput
+	err := postJSON(testDialClient, scatterURL, []byte(`{"regions_id": [601, 602, 699], "group": "t"}`), func(res []byte, code int) {
+		c.Assert(json.Unmarshal(res, &output), IsNil)
+	})
+	c.Assert(err, IsNil)
+	// The first region of the group stays, the second one is moved.
+	c.Assert(output.OperatorCount, Equals, 1)
+	c.Assert(output.Failures, HasLen, 1)
+	c.Assert(output.Failures[699], Matches, ".*not found.*")
+	c.Assert(output.NextKeyHex, Equals, "")
+	c.Assert(s.svr.GetRaftCluster().GetOperatorController().GetOperator(601), IsNil)
+	op := s.svr.GetRaftCluster().GetOperatorController().GetOperator(602)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "scatter-region")
+}
+
+func (s *testScatterRegionsSuite) TestScatterRegionsByKeyRange(c *C) {
+	for id := uint64(1); id <= 6; id++ {
+		mustPutStore(c, s.svr, id, metapb.StoreState_Up, nil)
+	}
+	// The keys are not valid UTF-8.
+	keys := [][]byte{{0x74, 0xff, 0x01}, {0x74, 0xff, 0x80}, {0x74, 0xff, 0xfe}, {0x75}}
+	for i := 0; i < 3; i++ {
+		r := newTestRegionInfo(uint64(701+i), 1, keys[i], keys[i+1], core.SetWrittenBytes(0), core.SetReadBytes(0))
+		mustRegionHeartbeat(c, s.svr, r)
+	}
+
+	scatterURL := fmt.Sprintf("%s/regions/scatter", s.urlPrefix)
+	c.Assert(postJSON(testDialClient, scatterURL, []byte(`{"start_key": "74ff", "end_key": "zz"}`)), NotNil)
+
+	scatter := func(startKey string) ScatterRegionsOutput {
+		var output ScatterRegionsOutput
+		input := fmt.Sprintf(`{"start_key": "%s", "end_key": "75", "limit": 2}`, startKey)
+		err := postJSON(testDialClient, scatterURL, []byte(input), func(res []byte, code int) {
+			c.Assert(json.Unmarshal(res, &output), IsNil)
+		})
+		c.Assert(err, IsNil)
+		return output
+	}
+	output := scatter("74ff")
+	c.Assert(output.NextKeyHex, Equals, hex.EncodeToString(keys[2]))
+	// Continue with the next key.
+	output = scatter(output.NextKeyHex)
+	c.Assert(output.NextKeyHex, Equals, "")
+}
@@ -39,6 +39,9 @@ func (s *testRuleSuite) SetUpSuite(c *C) {
 	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/config", addr, apiPrefix)
 
 	mustBootstrapCluster(c, s.svr)
+	PDServerCfg := s.svr.GetConfig().PDServerCfg
+	PDServerCfg.KeyType = "raw"
+	c.Assert(s.svr.SetPDServerConfig(PDServerCfg), IsNil)
 }
 
 func (s *testRuleSuite) TearDownSuite(c *C) {
@@ -115,6 +118,131 @@ func (s *testRuleSuite) Testrule(c *C) {
 	c.Assert(resp3.StatusCode, Equals, http.StatusOK)
 }
 
+func (s *testRuleSuite) TestBatch(c *C) {
+	c.Assert(postJSON(testDialClient, s.urlPrefix, []byte(`{"enable-placement-rules":"true"}`)), IsNil)
+	opt1 := placement.RuleOp{
+		Action: placement.RuleOpAdd,
+		Rule:   &placement.Rule{GroupID: "batch", ID: "a", StartKeyHex: "1111", EndKeyHex: "3333", Role: "voter", Count: 1},
+	}
+	opt2 := placement.RuleOp{
+		Action: placement.RuleOpAdd,
+		Rule:   &placement.Rule{GroupID: "batch", ID: "b", StartKeyHex: "3333", EndKeyHex: "5555", Role: "voter", Count: 1},
+	}
+	opt3 := placement.RuleOp{
+		Action: placement.RuleOpDel,
+		Rule:   &placement.Rule{GroupID: "batch", ID: "a"},
+	}
+	opt4 := placement.RuleOp{
+		Action:           placement.RuleOpDel,
+		Rule:             &placement.Rule{GroupID: "batch"},
+		DeleteByIDPrefix: true,
+	}
+
+	// add and delete in one batch
+	postData, err := json.Marshal([]placement.RuleOp{opt1, opt2, opt3})
+	c.Assert(err, IsNil)
+	err = postJSON(testDialClient, s.urlPrefix+"/rules/batch", postData)
+	c.Assert(err, IsNil)
+	var resp []*placement.Rule
+	err = readJSON(testDialClient, s.urlPrefix+"/rules/group/batch", &resp)
+	c.Assert(err, IsNil)
+	c.Assert(resp, HasLen, 1)
+	compareRule(c, resp[0], opt2.Rule)
+
+	// invalid batch does not take effect
+	bad := placement.RuleOp{
+		Action: placement.RuleOpAdd,
+		Rule:   &placement.Rule{GroupID: "batch", ID: "c", StartKeyHex: "xx", Role: "voter", Count: 1},
+	}
+	postData, err = json.Marshal([]placement.RuleOp{opt4, bad})
+	c.Assert(err, IsNil)
+	err = postJSON(testDialClient, s.urlPrefix+"/rules/batch", postData)
+	c.Assert(err, NotNil)
+	err = readJSON(testDialClient, s.urlPrefix+"/rules/group/batch", &resp)
+	c.Assert(err, IsNil)
+	c.Assert(resp, HasLen, 1)
+
+	// delete by ID prefix
+	postData, err = json.Marshal([]placement.RuleOp{opt4})
+	c.Assert(err, IsNil)
+	err = postJSON(testDialClient, s.urlPrefix+"/rules/batch", postData)
+	c.Assert(err, IsNil)
+	err = readJSON(testDialClient, s.urlPrefix+"/rules/group/batch", &resp)
+	c.Assert(err, IsNil)
+	c.Assert(resp, HasLen, 0)
+}
+
+func (s *testRuleSuite) TestPlan(c *C) {
+	c.Assert(postJSON(testDialClient, s.urlPrefix, []byte(`{"enable-placement-rules":"true"}`)), IsNil)
+	opt := placement.RuleOp{
+		Action: placement.RuleOpAdd,
+		Rule:   &placement.Rule{GroupID: "plan", ID: "a", Role: "learner", Count: 1},
+	}
+	postData, err := json.Marshal([]placement.RuleOp{opt})
+	c.Assert(err, IsNil)
+	var impact placement.PlanImpact
+	err = postJSON(testDialClient, s.urlPrefix+"/rules/plan", postData, func(res []byte, code int) {
+		c.Assert(code, Equals, http.StatusOK)
+		c.Assert(json.Unmarshal(res, &impact), IsNil)
+	})
+	c.Assert(err, IsNil)
+	c.Assert(impact.AffectedCount, Equals, impact.RegionCount)
+	// the only store already has a peer of each region, so no peer can be
+	// placed, including the ones lacked by the default rule.
+	c.Assert(impact.PeerAdditions, HasLen, 0)
+	c.Assert(impact.UnplaceablePeers >= impact.RegionCount, IsTrue)
+
+	// rules are not changed by a plan.
+	var resp []*placement.Rule
+	err = readJSON(testDialClient, s.urlPrefix+"/rules/group/plan", &resp)
+	c.Assert(err, IsNil)
+	c.Assert(resp, HasLen, 0)
+
+	// invalid operations.
+	opt.Rule.StartKeyHex = "xx"
+	postData, err = json.Marshal([]placement.RuleOp{opt})
+	c.Assert(err, IsNil)
+	err = postJSON(testDialClient, s.urlPrefix+"/rules/plan", postData)
+	c.Assert(err, NotNil)
+}
+
+func (s *testRuleSuite) TestRuleGroup(c *C) {
+	c.Assert(postJSON(testDialClient, s.urlPrefix, []byte(`{"enable-placement-rules":"true"}`)), IsNil)
+	group1 := placement.RuleGroup{ID: "g1", Index: 1, Override: true}
+	group2 := placement.RuleGroup{ID: "g2", Index: 2}
+
+	//Set
+	for _, g := range []placement.RuleGroup{group1, group2} {
+		postData, err := json.Marshal(g)
+		c.Assert(err, IsNil)
+		err = postJSON(testDialClient, s.urlPrefix+"/rule_group", postData)
+		c.Assert(err, IsNil)
+	}
+	err := postJSON(testDialClient, s.urlPrefix+"/rule_group", []byte(`{"index":1}`))
+	c.Assert(err, NotNil)
+
+	//Get
+	var resp placement.RuleGroup
+	err = readJSON(testDialClient, s.urlPrefix+"/rule_group/g1", &resp)
+	c.Assert(err, IsNil)
+	c.Assert(resp, DeepEquals, group1)
+
+	//GetAll
+	var resp2 []placement.RuleGroup
+	err = readJSON(testDialClient, s.urlPrefix+"/rule_groups", &resp2)
+	c.Assert(err, IsNil)
+	c.Assert(resp2, DeepEquals, []placement.RuleGroup{group1, group2})
+
+	//Delete
+	resp3, err := doDelete(testDialClient, s.urlPrefix+"/rule_group/g1")
+	c.Assert(err, IsNil)
+	c.Assert(resp3.StatusCode, Equals, http.StatusOK)
+	resp2 = nil
+	err = readJSON(testDialClient, s.urlPrefix+"/rule_groups", &resp2)
+	c.Assert(err, IsNil)
+	c.Assert(resp2, DeepEquals, []placement.RuleGroup{group2})
+}
+
 func compareRule(c *C, r1 *placement.Rule, r2 *placement.Rule) {
 	c.Assert(r1.GroupID, Equals, r2.GroupID)
 	c.Assert(r1.ID, Equals, r2.ID)
@@ -362,15 +362,34 @@ func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rateVal, ok := input["rate"]
-	if !ok {
+	algorithmVal, hasAlgorithm := input["algorithm"]
+	var algorithm string
+	if hasAlgorithm {
+		var ok bool
+		algorithm, ok = algorithmVal.(string)
+		if !ok {
+			h.rd.JSON(w, http.StatusBadRequest, "badformat algorithm")
+			return
+		}
+		if _, err := storelimit.ParseAlgorithm(algorithm); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	rateVal, hasRate := input["rate"]
+	if !hasRate && !hasAlgorithm {
 		h.rd.JSON(w, http.StatusBadRequest, "rate unset")
 		return
 	}
-	ratePerMin, ok := rateVal.(float64)
-	if !ok || ratePerMin < 0 {
-		h.rd.JSON(w, http.StatusBadRequest, "badformat rate")
-		return
+	var ratePerMin float64
+	if hasRate {
+		var ok bool
+		ratePerMin, ok = rateVal.(float64)
+		if !ok || ratePerMin < 0 {
+			h.rd.JSON(w, http.StatusBadRequest, "badformat rate")
+			return
+		}
 	}
 
 	typeValues, err := getStoreLimitType(input)
@@ -379,12 +398,20 @@ func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for _, typ := range typeValues {
-		if err := h.SetStoreLimit(storeID, ratePerMin, typ); err != nil {
+	if hasAlgorithm {
+		if err := h.SetStoreLimitAlgorithm(storeID, algorithm); err != nil {
 			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
+	if hasRate {
+		for _, typ := range typeValues {
+			if err := h.SetStoreLimit(storeID, ratePerMin, typ); err != nil {
+				h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+	}
 
 	h.rd.JSON(w, http.StatusOK, nil)
 }
@@ -496,7 +496,7 @@ func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 	// Save to storage if meta is updated.
 	// Save to cache if meta or leader is updated, or contains any down/pending peer.
 	// Mark isNew if the region in cache does not have leader.
-	var saveKV, saveCache, isNew, statsChange bool
+	var saveKV, saveCache, isNew, statsChange, leaderChange bool
 	if origin == nil {
 		log.Debug("insert new region",
 			zap.Uint64("region-id", region.GetID()),
@@ -525,6 +525,7 @@ func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 			saveKV, saveCache = true, true
 		}
 		if region.GetLeader().GetId() != origin.GetLeader().GetId() {
+			leaderChange = true
 			if origin.GetLeader().GetId() == 0 {
 				isNew = true
 			} else {
@@ -646,10 +647,14 @@ func (c *RaftCluster) processRegionHeartbeat(region *core.RegionInfo) error {
 		}
 		regionEventCounter.WithLabelValues("update_kv").Inc()
 	}
-	if saveKV || statsChange {
+	if saveKV || statsChange || leaderChange {
 		select {
 		case c.changedRegions <- region:
 		default:
+			// the watchers of the region syncer rescan their regions.
+			if c.regionSyncer != nil {
+				c.regionSyncer.MarkChangesLost()
+			}
 		}
 	}
 
@@ -1411,6 +1416,16 @@ func (c *RaftCluster) IsOneWayMergeEnabled() bool {
 	return c.opt.IsOneWayMergeEnabled()
 }
 
+// IsHotRegionSplitEnabled returns if splitting hot regions by load is enabled.
+func (c *RaftCluster) IsHotRegionSplitEnabled() bool {
+	return c.opt.IsHotRegionSplitEnabled()
+}
+
+// GetHotRegionSplitPolicy returns the policy to split hot regions.
+func (c *RaftCluster) GetHotRegionSplitPolicy() pdpb.CheckPolicy {
+	return c.opt.GetHotRegionSplitPolicy()
+}
+
 // IsCrossTableMergeEnabled returns if across table merge is enabled.
 func (c *RaftCluster) IsCrossTableMergeEnabled() bool {
 	return c.opt.IsCrossTableMergeEnabled()
@@ -1678,6 +1693,11 @@ func (c *RaftCluster) GetStoreLimitByType(storeID uint64, typ storelimit.Type) f
 	return c.opt.GetStoreLimitByType(storeID, typ)
 }
 
+// GetStoreLimitAlgorithm returns the store limit algorithm for a given store ID.
+func (c *RaftCluster) GetStoreLimitAlgorithm(storeID uint64) storelimit.Algorithm {
+	return c.opt.GetStoreLimitAlgorithm(storeID)
+}
+
 // GetAllStoresLimit returns all store limit
 func (c *RaftCluster) GetAllStoresLimit() map[uint64]config.StoreLimitConfig {
 	return c.opt.GetAllStoresLimit()
@@ -1686,15 +1706,9 @@ func (c *RaftCluster) GetAllStoresLimit() map[uint64]config.StoreLimitConfig {
 // AddStoreLimit add a store limit for a given store ID.
 func (c *RaftCluster) AddStoreLimit(store *metapb.Store) {
 	cfg := c.opt.GetScheduleConfig().Clone()
-	sc := config.StoreLimitConfig{
-		AddPeer:    config.DefaultStoreLimit.GetDefaultStoreLimit(storelimit.AddPeer),
-		RemovePeer: config.DefaultStoreLimit.GetDefaultStoreLimit(storelimit.RemovePeer),
-	}
+	sc := config.DefaultStoreLimit.GetDefaultStoreLimitConfig()
 	if core.IsTiFlashStore(store) {
-		sc = config.StoreLimitConfig{
-			AddPeer:    config.DefaultTiFlashStoreLimit.GetDefaultStoreLimit(storelimit.AddPeer),
-			RemovePeer: config.DefaultTiFlashStoreLimit.GetDefaultStoreLimit(storelimit.RemovePeer),
-		}
+		sc = config.DefaultTiFlashStoreLimit.GetDefaultStoreLimitConfig()
 	}
 	storeID := store.GetId()
 	cfg.StoreLimit[storeID] = sc
@@ -1716,6 +1730,11 @@ func (c *RaftCluster) SetStoreLimit(storeID uint64, typ storelimit.Type, ratePer
 	c.opt.SetStoreLimit(storeID, typ, ratePerMin)
 }
 
+// SetStoreLimitAlgorithm sets the store limit algorithm for a given store ID.
+func (c *RaftCluster) SetStoreLimitAlgorithm(storeID uint64, algorithm string) {
+	c.opt.SetStoreLimitAlgorithm(storeID, algorithm)
+}
+
 // SetAllStoresLimit sets all store limit for a given type and rate.
 func (c *RaftCluster) SetAllStoresLimit(typ storelimit.Type, ratePerMin float64) {
 	c.opt.SetAllStoresLimit(typ, ratePerMin)
@@ -69,6 +69,9 @@ type coordinator struct {
 func newCoordinator(ctx context.Context, cluster *RaftCluster, hbStreams opt.HeartbeatStreams) *coordinator {
 	ctx, cancel := context.WithCancel(ctx)
 	opController := schedule.NewOperatorController(ctx, cluster, hbStreams)
+	if cluster.storage != nil {
+		opController.SetStorage(cluster.storage)
+	}
 	return &coordinator{
 		ctx:             ctx,
 		cancel:          cancel,
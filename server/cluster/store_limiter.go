@@ -35,9 +35,9 @@ type StoreLimiter struct {
 
 // NewStoreLimiter builds a store limiter object using the operator controller
 func NewStoreLimiter(opt opt.Options) *StoreLimiter {
-	defaultScene := map[storelimit.Type]*storelimit.Scene{
-		storelimit.AddPeer:    storelimit.DefaultScene(storelimit.AddPeer),
-		storelimit.RemovePeer: storelimit.DefaultScene(storelimit.RemovePeer),
+	defaultScene := make(map[storelimit.Type]*storelimit.Scene)
+	for _, limitType := range storelimit.TypeNameValue {
+		defaultScene[limitType] = storelimit.DefaultScene(limitType)
 	}
 
 	return &StoreLimiter{
@@ -57,18 +57,16 @@ func (s *StoreLimiter) Collect(stats *pdpb.StoreStats) {
 	s.state.Collect((*StatEntry)(stats))
 
 	state := s.state.State()
-	ratePeerAdd := s.calculateRate(storelimit.AddPeer, state)
-	ratePeerRemove := s.calculateRate(storelimit.RemovePeer, state)
-
-	if ratePeerAdd > 0 || ratePeerRemove > 0 {
-		if ratePeerAdd > 0 {
-			s.opt.SetAllStoresLimit(storelimit.AddPeer, ratePeerAdd)
-			log.Info("change store region add limit for cluster", zap.Stringer("state", state), zap.Float64("rate", ratePeerAdd))
-		}
-		if ratePeerRemove > 0 {
-			s.opt.SetAllStoresLimit(storelimit.RemovePeer, ratePeerRemove)
-			log.Info("change store region remove limit for cluster", zap.Stringer("state", state), zap.Float64("rate", ratePeerRemove))
+	var changed bool
+	for name, limitType := range storelimit.TypeNameValue {
+		rate := s.calculateRate(limitType, state)
+		if rate > 0 {
+			s.opt.SetAllStoresLimit(limitType, rate)
+			log.Info("change store limit for cluster", zap.String("type", name), zap.Stringer("state", state), zap.Float64("rate", rate))
+			changed = true
 		}
+	}
+	if changed {
 		s.current = state
 		collectClusterStateCurrent(state)
 	}
@@ -42,6 +42,8 @@ func (s *testStoreLimiterSuite) TestStoreLimitScene(c *C) {
 	limiter := NewStoreLimiter(s.opt)
 	c.Assert(limiter.scene[storelimit.AddPeer], DeepEquals, storelimit.DefaultScene(storelimit.AddPeer))
 	c.Assert(limiter.scene[storelimit.RemovePeer], DeepEquals, storelimit.DefaultScene(storelimit.RemovePeer))
+	c.Assert(limiter.scene[storelimit.SendSnapshot], DeepEquals, storelimit.DefaultScene(storelimit.SendSnapshot))
+	c.Assert(limiter.scene[storelimit.ReceiveSnapshot], DeepEquals, storelimit.DefaultScene(storelimit.ReceiveSnapshot))
 }
 
 func (s *testStoreLimiterSuite) TestReplaceStoreLimitScene(c *C) {
@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -83,6 +84,11 @@ type Config struct {
 	// TsoSaveInterval is the interval to save timestamp.
 	TsoSaveInterval typeutil.Duration `toml:"tso-save-interval" json:"tso-save-interval"`
 
+	// TsoUpdatePhysicalInterval is the interval to update the physical part of
+	// the timestamp. The physical part is also updated in advance if the
+	// logical part is used up quickly.
+	TsoUpdatePhysicalInterval typeutil.Duration `toml:"tso-update-physical-interval" json:"tso-update-physical-interval"`
+
 	Metric metricutil.MetricConfig `toml:"metric" json:"metric"`
 
 	Schedule ScheduleConfig `toml:"schedule" json:"schedule"`
@@ -197,6 +203,10 @@ const (
 
 	defaultMetricsPushInterval = 15 * time.Second
 
+	defaultTsoUpdatePhysicalInterval = 50 * time.Millisecond
+	minTsoUpdatePhysicalInterval     = 1 * time.Millisecond
+	maxTsoUpdatePhysicalInterval     = 10 * time.Second
+
 	defaultHeartbeatStreamRebindInterval = time.Minute
 
 	defaultLeaderPriorityCheckInterval = time.Minute
@@ -219,9 +229,10 @@ var (
 	defaultRuntimeServices = []string{}
 	defaultLocationLabels  = []string{}
 	// DefaultStoreLimit is the default store limit of add peer and remove peer.
-	DefaultStoreLimit StoreLimit = StoreLimit{AddPeer: 15, RemovePeer: 15}
+	// Snapshots are not limited by default.
+	DefaultStoreLimit StoreLimit = StoreLimit{AddPeer: 15, RemovePeer: 15, SendSnapshot: storelimit.Unlimited, ReceiveSnapshot: storelimit.Unlimited}
 	// DefaultTiFlashStoreLimit is the default TiFlash store limit of add peer and remove peer.
-	DefaultTiFlashStoreLimit StoreLimit = StoreLimit{AddPeer: 30, RemovePeer: 30}
+	DefaultTiFlashStoreLimit StoreLimit = StoreLimit{AddPeer: 30, RemovePeer: 30, SendSnapshot: storelimit.Unlimited, ReceiveSnapshot: storelimit.Unlimited}
 )
 
 // StoreLimit is the default limit of adding peer and removing peer when putting stores.
@@ -231,6 +242,10 @@ type StoreLimit struct {
 	AddPeer float64
 	// RemovePeer is the default rate of removing peers for store limit (per minute).
 	RemovePeer float64
+	// SendSnapshot is the default rate of sending snapshots for store limit (per minute).
+	SendSnapshot float64
+	// ReceiveSnapshot is the default rate of receiving snapshots for store limit (per minute).
+	ReceiveSnapshot float64
 }
 
 // SetDefaultStoreLimit sets the default store limit for a given type.
@@ -242,6 +257,10 @@ func (sl *StoreLimit) SetDefaultStoreLimit(typ storelimit.Type, ratePerMin float
 		sl.AddPeer = ratePerMin
 	case storelimit.RemovePeer:
 		sl.RemovePeer = ratePerMin
+	case storelimit.SendSnapshot:
+		sl.SendSnapshot = ratePerMin
+	case storelimit.ReceiveSnapshot:
+		sl.ReceiveSnapshot = ratePerMin
 	}
 }
 
@@ -254,11 +273,27 @@ func (sl *StoreLimit) GetDefaultStoreLimit(typ storelimit.Type) float64 {
 		return sl.AddPeer
 	case storelimit.RemovePeer:
 		return sl.RemovePeer
+	case storelimit.SendSnapshot:
+		return sl.SendSnapshot
+	case storelimit.ReceiveSnapshot:
+		return sl.ReceiveSnapshot
 	default:
 		panic("invalid type")
 	}
 }
 
+// GetDefaultStoreLimitConfig returns the default limit config of a store.
+func (sl *StoreLimit) GetDefaultStoreLimitConfig() StoreLimitConfig {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	return StoreLimitConfig{
+		AddPeer:         sl.AddPeer,
+		RemovePeer:      sl.RemovePeer,
+		SendSnapshot:    sl.SendSnapshot,
+		ReceiveSnapshot: sl.ReceiveSnapshot,
+	}
+}
+
 func adjustString(v *string, defValue string) {
 	if len(*v) == 0 {
 		*v = defValue
@@ -467,6 +502,11 @@ func (c *Config) Adjust(meta *toml.MetaData) error {
 
 	adjustDuration(&c.TsoSaveInterval, time.Duration(defaultLeaderLease)*time.Second)
 
+	adjustDuration(&c.TsoUpdatePhysicalInterval, defaultTsoUpdatePhysicalInterval)
+	if c.TsoUpdatePhysicalInterval.Duration < minTsoUpdatePhysicalInterval || c.TsoUpdatePhysicalInterval.Duration > maxTsoUpdatePhysicalInterval {
+		return errors.Errorf("tso-update-physical-interval should be between %v and %v", minTsoUpdatePhysicalInterval, maxTsoUpdatePhysicalInterval)
+	}
+
 	if c.nextRetryDelay == 0 {
 		c.nextRetryDelay = defaultNextRetryDelay
 	}
@@ -575,11 +615,27 @@ type ScheduleConfig struct {
 	// If the number of times a region hits the hot cache is greater than this
 	// threshold, it is considered a hot region.
 	HotRegionCacheHitsThreshold uint64 `toml:"hot-region-cache-hits-threshold" json:"hot-region-cache-hits-threshold"`
+	// HotRegionsWriteInterval is the interval to write the hot regions into
+	// the hot region history.
+	HotRegionsWriteInterval typeutil.Duration `toml:"hot-regions-write-interval" json:"hot-regions-write-interval"`
+	// HotRegionsReservedDays is the days to keep the hot region history,
+	// 0 means not to record the history.
+	HotRegionsReservedDays uint64 `toml:"hot-regions-reserved-days" json:"hot-regions-reserved-days"`
+	// EnableHotRegionSplit is the option to enable splitting the regions
+	// which stay hot alone on a store, such regions cannot be balanced by
+	// the hot region scheduler.
+	EnableHotRegionSplit bool `toml:"enable-hot-region-split" json:"enable-hot-region-split,string"`
+	// HotRegionSplitPolicy is the policy to split hot regions, there are some policies supported: ["approximate", "scan"], default: "approximate"
+	HotRegionSplitPolicy string `toml:"hot-region-split-policy" json:"hot-region-split-policy"`
 	// StoreBalanceRate is the maximum of balance rate for each store.
 	// WARN: StoreBalanceRate is deprecated.
 	StoreBalanceRate float64 `toml:"store-balance-rate" json:"store-balance-rate,omitempty"`
 	// StoreLimit is the limit of scheduling for stores.
-	StoreLimit map[uint64]StoreLimitConfig `toml:"store-limit" json:"store-limit"`
+	StoreLimit map[uint64]StoreLimitConfig `toml:"-" json:"store-limit"`
+	// StoreLimitTOML is the store-limit in the config file keyed by the store
+	// IDs, as the toml decoder only supports maps with string keys. It is
+	// merged into StoreLimit when adjusting.
+	StoreLimitTOML map[string]StoreLimitConfig `toml:"store-limit,omitempty" json:"-"`
 	// TolerantSizeRatio is the ratio of buffer size for balance scheduler.
 	TolerantSizeRatio float64 `toml:"tolerant-size-ratio" json:"tolerant-size-ratio"`
 	//
@@ -646,6 +702,11 @@ type ScheduleConfig struct {
 	// is overwritten, the value is fixed until it is deleted.
 	// Default: manual
 	StoreLimitMode string `toml:"store-limit-mode" json:"store-limit-mode"`
+
+	// StoreLimitAlgorithm is the default algorithm of store limit, it can be
+	// token-bucket or adaptive. It can be overwritten for each store.
+	// Default: token-bucket
+	StoreLimitAlgorithm string `toml:"store-limit-algorithm" json:"store-limit-algorithm"`
 }
 
 // Clone returns a cloned scheduling configuration.
@@ -673,6 +734,10 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 		EnableCrossTableMerge:        c.EnableCrossTableMerge,
 		HotRegionScheduleLimit:       c.HotRegionScheduleLimit,
 		HotRegionCacheHitsThreshold:  c.HotRegionCacheHitsThreshold,
+		HotRegionsWriteInterval:      c.HotRegionsWriteInterval,
+		HotRegionsReservedDays:       c.HotRegionsReservedDays,
+		EnableHotRegionSplit:         c.EnableHotRegionSplit,
+		HotRegionSplitPolicy:         c.HotRegionSplitPolicy,
 		StoreLimit:                   storeLimit,
 		TolerantSizeRatio:            c.TolerantSizeRatio,
 		LowSpaceRatio:                c.LowSpaceRatio,
@@ -691,6 +756,7 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 		EnableLocationReplacement:    c.EnableLocationReplacement,
 		EnableDebugMetrics:           c.EnableDebugMetrics,
 		StoreLimitMode:               c.StoreLimitMode,
+		StoreLimitAlgorithm:          c.StoreLimitAlgorithm,
 		Schedulers:                   schedulers,
 	}
 }
@@ -716,8 +782,16 @@ const (
 	// hot region.
 	defaultHotRegionCacheHitsThreshold = 3
 	defaultSchedulerMaxWaitingOperator = 5
+	defaultHotRegionsWriteInterval     = 10 * time.Minute
+	defaultHotRegionsReservedDays      = 7
+	defaultHotRegionSplitPolicy        = "approximate"
 	defaultLeaderSchedulePolicy        = "count"
 	defaultStoreLimitMode              = "manual"
+	defaultStoreLimitAlgorithm         = string(storelimit.TokenBucket)
+
+	// minHotRegionsWriteInterval keeps the hot region history from being
+	// flushed and cleaned up continuously.
+	minHotRegionsWriteInterval = time.Minute
 )
 
 func (c *ScheduleConfig) adjust(meta *configMetaData) error {
@@ -754,6 +828,13 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	if !meta.IsDefined("hot-region-cache-hits-threshold") {
 		adjustUint64(&c.HotRegionCacheHitsThreshold, defaultHotRegionCacheHitsThreshold)
 	}
+	adjustDuration(&c.HotRegionsWriteInterval, defaultHotRegionsWriteInterval)
+	if !meta.IsDefined("hot-regions-reserved-days") {
+		adjustUint64(&c.HotRegionsReservedDays, defaultHotRegionsReservedDays)
+	}
+	if !meta.IsDefined("hot-region-split-policy") {
+		adjustString(&c.HotRegionSplitPolicy, defaultHotRegionSplitPolicy)
+	}
 	if !meta.IsDefined("tolerant-size-ratio") {
 		adjustFloat64(&c.TolerantSizeRatio, defaultTolerantSizeRatio)
 	}
@@ -766,6 +847,9 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	if !meta.IsDefined("store-limit-mode") {
 		adjustString(&c.StoreLimitMode, defaultStoreLimitMode)
 	}
+	if !meta.IsDefined("store-limit-algorithm") {
+		adjustString(&c.StoreLimitAlgorithm, defaultStoreLimitAlgorithm)
+	}
 	adjustFloat64(&c.LowSpaceRatio, defaultLowSpaceRatio)
 	adjustFloat64(&c.HighSpaceRatio, defaultHighSpaceRatio)
 	adjustSchedulers(&c.Schedulers, defaultSchedulers)
@@ -779,10 +863,33 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	}
 
 	if c.StoreBalanceRate != 0 {
-		DefaultStoreLimit = StoreLimit{AddPeer: c.StoreBalanceRate, RemovePeer: c.StoreBalanceRate}
+		DefaultStoreLimit.SetDefaultStoreLimit(storelimit.AddPeer, c.StoreBalanceRate)
+		DefaultStoreLimit.SetDefaultStoreLimit(storelimit.RemovePeer, c.StoreBalanceRate)
 		c.StoreBalanceRate = 0
 	}
 
+	for key, limit := range c.StoreLimitTOML {
+		id, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return errors.Errorf("invalid store id %s in store-limit", key)
+		}
+		// The snapshot limits are missing in the store limits configured by
+		// older versions, 0 blocks all snapshots of the store, so use the
+		// default values.
+		limitMeta := meta.Child("store-limit", key)
+		if !limitMeta.IsDefined("send-snapshot") {
+			limit.SendSnapshot = DefaultStoreLimit.GetDefaultStoreLimit(storelimit.SendSnapshot)
+		}
+		if !limitMeta.IsDefined("receive-snapshot") {
+			limit.ReceiveSnapshot = DefaultStoreLimit.GetDefaultStoreLimit(storelimit.ReceiveSnapshot)
+		}
+		if c.StoreLimit == nil {
+			c.StoreLimit = make(map[uint64]StoreLimitConfig)
+		}
+		c.StoreLimit[id] = limit
+	}
+	c.StoreLimitTOML = nil
+
 	return c.Validate()
 }
 
@@ -819,7 +926,8 @@ func (c *ScheduleConfig) parseDeprecatedFlag(meta *configMetaData, name string,
 func (c *ScheduleConfig) MigrateDeprecatedFlags() {
 	c.DisableLearner = false
 	if c.StoreBalanceRate != 0 {
-		DefaultStoreLimit = StoreLimit{AddPeer: c.StoreBalanceRate, RemovePeer: c.StoreBalanceRate}
+		DefaultStoreLimit.SetDefaultStoreLimit(storelimit.AddPeer, c.StoreBalanceRate)
+		DefaultStoreLimit.SetDefaultStoreLimit(storelimit.RemovePeer, c.StoreBalanceRate)
 		c.StoreBalanceRate = 0
 	}
 	for _, b := range c.migrateConfigurationMap() {
@@ -844,6 +952,23 @@ func (c *ScheduleConfig) Validate() error {
 	if c.LowSpaceRatio <= c.HighSpaceRatio {
 		return errors.New("low-space-ratio should be larger than high-space-ratio")
 	}
+	if _, err := storelimit.ParseAlgorithm(c.StoreLimitAlgorithm); err != nil {
+		return err
+	}
+	if c.HotRegionsWriteInterval.Duration < minHotRegionsWriteInterval {
+		return errors.Errorf("hot-regions-write-interval should be at least %s, got %s", minHotRegionsWriteInterval, c.HotRegionsWriteInterval.Duration)
+	}
+	switch c.HotRegionSplitPolicy {
+	// an empty policy is left by the old versions and means the default one.
+	case "", "approximate", "scan":
+	default:
+		return errors.Errorf("hot-region-split-policy should be approximate or scan, got %s", c.HotRegionSplitPolicy)
+	}
+	for storeID, limit := range c.StoreLimit {
+		if _, err := storelimit.ParseAlgorithm(limit.Algorithm); err != nil {
+			return errors.Wrapf(err, "invalid store limit of store %d", storeID)
+		}
+	}
 	for _, scheduleConfig := range c.Schedulers {
 		if !schedule.IsSchedulerRegistered(scheduleConfig.Type) {
 			return errors.Errorf("create func of %v is not registered, maybe misspelled", scheduleConfig.Type)
@@ -880,8 +1005,57 @@ func (c *ScheduleConfig) Deprecated() error {
 
 // StoreLimitConfig is a config about scheduling rate limit of different types for a store.
 type StoreLimitConfig struct {
-	AddPeer    float64 `toml:"add-peer" json:"add-peer"`
-	RemovePeer float64 `toml:"remove-peer" json:"remove-peer"`
+	AddPeer         float64 `toml:"add-peer" json:"add-peer"`
+	RemovePeer      float64 `toml:"remove-peer" json:"remove-peer"`
+	SendSnapshot    float64 `toml:"send-snapshot" json:"send-snapshot"`
+	ReceiveSnapshot float64 `toml:"receive-snapshot" json:"receive-snapshot"`
+	// Algorithm overwrites the store-limit-algorithm of the store if it is not empty.
+	Algorithm string `toml:"algorithm" json:"algorithm,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The snapshot limits persisted by
+// older versions are missing, which are filled with the default values.
+func (c *StoreLimitConfig) UnmarshalJSON(data []byte) error {
+	type storeLimitConfig StoreLimitConfig
+	cfg := storeLimitConfig{
+		SendSnapshot:    DefaultStoreLimit.GetDefaultStoreLimit(storelimit.SendSnapshot),
+		ReceiveSnapshot: DefaultStoreLimit.GetDefaultStoreLimit(storelimit.ReceiveSnapshot),
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	*c = StoreLimitConfig(cfg)
+	return nil
+}
+
+// GetRate returns the rate of the specific type store limit (per minute).
+func (c StoreLimitConfig) GetRate(typ storelimit.Type) float64 {
+	switch typ {
+	case storelimit.AddPeer:
+		return c.AddPeer
+	case storelimit.RemovePeer:
+		return c.RemovePeer
+	case storelimit.SendSnapshot:
+		return c.SendSnapshot
+	case storelimit.ReceiveSnapshot:
+		return c.ReceiveSnapshot
+	default:
+		panic("no such limit type")
+	}
+}
+
+// SetRate sets the rate of the specific type store limit (per minute).
+func (c *StoreLimitConfig) SetRate(typ storelimit.Type, ratePerMin float64) {
+	switch typ {
+	case storelimit.AddPeer:
+		c.AddPeer = ratePerMin
+	case storelimit.RemovePeer:
+		c.RemovePeer = ratePerMin
+	case storelimit.SendSnapshot:
+		c.SendSnapshot = ratePerMin
+	case storelimit.ReceiveSnapshot:
+		c.ReceiveSnapshot = ratePerMin
+	}
 }
 
 // SchedulerConfigs is a slice of customized scheduler configuration.
@@ -24,8 +24,10 @@ import (
 
 	"github.com/BurntSushi/toml"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 
 	// Register schedulers.
 	_ "github.com/pingcap/pd/v4/server/schedulers"
@@ -99,6 +101,21 @@ func (s *testConfigSuite) TestValidation(c *C) {
 	c.Assert(cfg.Schedule.Validate(), IsNil)
 	cfg.Schedule.TolerantSizeRatio = -0.6
 	c.Assert(cfg.Schedule.Validate(), NotNil)
+	cfg.Schedule.TolerantSizeRatio = 0
+	c.Assert(cfg.Schedule.StoreLimitAlgorithm, Equals, "token-bucket")
+	cfg.Schedule.StoreLimitAlgorithm = "adaptive"
+	c.Assert(cfg.Schedule.Validate(), IsNil)
+	sc := cfg.Schedule.Clone()
+	sc.StoreLimit[1] = StoreLimitConfig{AddPeer: 1, RemovePeer: 1, Algorithm: "unknown"}
+	c.Assert(sc.Validate(), NotNil)
+	cfg.Schedule.StoreLimitAlgorithm = "unknown"
+	c.Assert(cfg.Schedule.Validate(), NotNil)
+	sc = cfg.Schedule.Clone()
+	sc.StoreLimitAlgorithm = "token-bucket"
+	sc.HotRegionsWriteInterval = typeutil.NewDuration(0)
+	c.Assert(sc.Validate(), NotNil)
+	sc.HotRegionsWriteInterval = typeutil.NewDuration(time.Minute)
+	c.Assert(sc.Validate(), IsNil)
 	// check quota
 	c.Assert(cfg.QuotaBackendBytes, Equals, defaultQuotaBackendBytes)
 }
@@ -133,6 +150,7 @@ leader-schedule-limit = 0
 	c.Assert(cfg.Schedule.LeaderScheduleLimit, Equals, uint64(0))
 	// When undefined, use default values.
 	c.Assert(cfg.PreVote, IsTrue)
+	c.Assert(cfg.TsoUpdatePhysicalInterval.Duration, Equals, defaultTsoUpdatePhysicalInterval)
 	c.Assert(cfg.Schedule.MaxMergeRegionKeys, Equals, uint64(defaultMaxMergeRegionKeys))
 	c.Assert(cfg.PDServerCfg.MetricStorage, Equals, "http://127.0.0.1:9090")
 
@@ -193,6 +211,17 @@ address = "localhost:9090"
 
 	c.Assert(cfg.Metric.PushInterval.Duration, Equals, 35*time.Second)
 	c.Assert(cfg.Metric.PushAddress, Equals, "localhost:9090")
+
+	// Check the tso update interval out of range.
+	cfgData = `
+tso-update-physical-interval = "100us"
+`
+	cfg = NewConfig()
+	meta, err = toml.Decode(cfgData, &cfg)
+	c.Assert(err, IsNil)
+	err = cfg.Adjust(&meta)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, "tso-update-physical-interval should be between.*")
 }
 
 func (s *testConfigSuite) TestMigrateFlags(c *C) {
@@ -228,6 +257,39 @@ disable-make-up-replica = false
 	c.Assert(err, NotNil)
 }
 
+func (s *testConfigSuite) TestStoreLimitFromFile(c *C) {
+	cfgData := `
+[schedule.store-limit.1]
+add-peer = 10.0
+remove-peer = 10.0
+
+[schedule.store-limit.2]
+add-peer = 20.0
+remove-peer = 20.0
+send-snapshot = 5.0
+receive-snapshot = 6.0
+`
+	cfg := NewConfig()
+	meta, err := toml.Decode(cfgData, &cfg)
+	c.Assert(err, IsNil)
+	c.Assert(cfg.Adjust(&meta), IsNil)
+	c.Assert(cfg.WarningMsgs, HasLen, 0)
+	c.Assert(cfg.Schedule.StoreLimit, HasLen, 2)
+	// the missing snapshot limits use the default values.
+	c.Assert(cfg.Schedule.StoreLimit[1], DeepEquals, StoreLimitConfig{
+		AddPeer:         10,
+		RemovePeer:      10,
+		SendSnapshot:    storelimit.Unlimited,
+		ReceiveSnapshot: storelimit.Unlimited,
+	})
+	c.Assert(cfg.Schedule.StoreLimit[2], DeepEquals, StoreLimitConfig{AddPeer: 20, RemovePeer: 20, SendSnapshot: 5, ReceiveSnapshot: 6})
+
+	cfg = NewConfig()
+	meta, err = toml.Decode("[schedule.store-limit.abc]\nadd-peer = 10.0\n", &cfg)
+	c.Assert(err, IsNil)
+	c.Assert(cfg.Adjust(&meta), NotNil)
+}
+
 func newTestScheduleOption() (*PersistOptions, error) {
 	cfg := NewConfig()
 	if err := cfg.Adjust(nil); err != nil {
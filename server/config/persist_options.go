@@ -22,6 +22,7 @@ import (
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
@@ -179,24 +180,24 @@ func (o *PersistOptions) SetSplitMergeInterval(splitMergeInterval time.Duration)
 // SetStoreLimit sets a store limit for a given type and rate.
 func (o *PersistOptions) SetStoreLimit(storeID uint64, typ storelimit.Type, ratePerMin float64) {
 	v := o.GetScheduleConfig().Clone()
-	var sc StoreLimitConfig
-	var rate float64
-	switch typ {
-	case storelimit.AddPeer:
-		if _, ok := v.StoreLimit[storeID]; !ok {
-			rate = DefaultStoreLimit.GetDefaultStoreLimit(storelimit.AddPeer)
-		} else {
-			rate = v.StoreLimit[storeID].RemovePeer
-		}
-		sc = StoreLimitConfig{AddPeer: ratePerMin, RemovePeer: rate}
-	case storelimit.RemovePeer:
-		if _, ok := v.StoreLimit[storeID]; !ok {
-			rate = DefaultStoreLimit.GetDefaultStoreLimit(storelimit.RemovePeer)
-		} else {
-			rate = v.StoreLimit[storeID].AddPeer
-		}
-		sc = StoreLimitConfig{AddPeer: rate, RemovePeer: ratePerMin}
+	sc, ok := v.StoreLimit[storeID]
+	if !ok {
+		sc = DefaultStoreLimit.GetDefaultStoreLimitConfig()
 	}
+	sc.SetRate(typ, ratePerMin)
+	v.StoreLimit[storeID] = sc
+	o.SetScheduleConfig(v)
+}
+
+// SetStoreLimitAlgorithm sets the store limit algorithm of a store. An empty
+// algorithm means following the store-limit-algorithm config.
+func (o *PersistOptions) SetStoreLimitAlgorithm(storeID uint64, algorithm string) {
+	v := o.GetScheduleConfig().Clone()
+	sc, ok := v.StoreLimit[storeID]
+	if !ok {
+		sc = DefaultStoreLimit.GetDefaultStoreLimitConfig()
+	}
+	sc.Algorithm = algorithm
 	v.StoreLimit[storeID] = sc
 	o.SetScheduleConfig(v)
 }
@@ -204,19 +205,10 @@ func (o *PersistOptions) SetStoreLimit(storeID uint64, typ storelimit.Type, rate
 // SetAllStoresLimit sets all store limit for a given type and rate.
 func (o *PersistOptions) SetAllStoresLimit(typ storelimit.Type, ratePerMin float64) {
 	v := o.GetScheduleConfig().Clone()
-	switch typ {
-	case storelimit.AddPeer:
-		DefaultStoreLimit.SetDefaultStoreLimit(storelimit.AddPeer, ratePerMin)
-		for storeID := range v.StoreLimit {
-			sc := StoreLimitConfig{AddPeer: ratePerMin, RemovePeer: v.StoreLimit[storeID].RemovePeer}
-			v.StoreLimit[storeID] = sc
-		}
-	case storelimit.RemovePeer:
-		DefaultStoreLimit.SetDefaultStoreLimit(storelimit.RemovePeer, ratePerMin)
-		for storeID := range v.StoreLimit {
-			sc := StoreLimitConfig{AddPeer: v.StoreLimit[storeID].AddPeer, RemovePeer: ratePerMin}
-			v.StoreLimit[storeID] = sc
-		}
+	DefaultStoreLimit.SetDefaultStoreLimit(typ, ratePerMin)
+	for storeID, sc := range v.StoreLimit {
+		sc.SetRate(typ, ratePerMin)
+		v.StoreLimit[storeID] = sc
 	}
 
 	o.SetScheduleConfig(v)
@@ -273,10 +265,7 @@ func (o *PersistOptions) GetStoreLimit(storeID uint64) StoreLimitConfig {
 		return limit
 	}
 	cfg := o.GetScheduleConfig().Clone()
-	sc := StoreLimitConfig{
-		AddPeer:    DefaultStoreLimit.GetDefaultStoreLimit(storelimit.AddPeer),
-		RemovePeer: DefaultStoreLimit.GetDefaultStoreLimit(storelimit.RemovePeer),
-	}
+	sc := DefaultStoreLimit.GetDefaultStoreLimitConfig()
 	cfg.StoreLimit[storeID] = sc
 	o.SetScheduleConfig(cfg)
 	return o.GetScheduleConfig().StoreLimit[storeID]
@@ -284,15 +273,7 @@ func (o *PersistOptions) GetStoreLimit(storeID uint64) StoreLimitConfig {
 
 // GetStoreLimitByType returns the limit of a store with a given type.
 func (o *PersistOptions) GetStoreLimitByType(storeID uint64, typ storelimit.Type) float64 {
-	limit := o.GetStoreLimit(storeID)
-	switch typ {
-	case storelimit.AddPeer:
-		return limit.AddPeer
-	case storelimit.RemovePeer:
-		return limit.RemovePeer
-	default:
-		panic("no such limit type")
-	}
+	return o.GetStoreLimit(storeID).GetRate(typ)
 }
 
 // GetAllStoresLimit returns the limit of all stores.
@@ -300,6 +281,20 @@ func (o *PersistOptions) GetAllStoresLimit() map[uint64]StoreLimitConfig {
 	return o.GetScheduleConfig().StoreLimit
 }
 
+// GetStoreLimitAlgorithm returns the limit algorithm of a store.
+func (o *PersistOptions) GetStoreLimitAlgorithm(storeID uint64) storelimit.Algorithm {
+	cfg := o.GetScheduleConfig()
+	name := cfg.StoreLimitAlgorithm
+	if limit, ok := cfg.StoreLimit[storeID]; ok && limit.Algorithm != "" {
+		name = limit.Algorithm
+	}
+	algorithm, err := storelimit.ParseAlgorithm(name)
+	if err != nil {
+		return storelimit.TokenBucket
+	}
+	return algorithm
+}
+
 // GetStoreLimitMode returns the limit mode of store.
 func (o *PersistOptions) GetStoreLimitMode() string {
 	return o.GetScheduleConfig().StoreLimitMode
@@ -330,6 +325,19 @@ func (o *PersistOptions) GetLeaderSchedulePolicy() core.SchedulePolicy {
 	return core.StringToSchedulePolicy(o.GetScheduleConfig().LeaderSchedulePolicy)
 }
 
+// IsHotRegionSplitEnabled returns if splitting hot regions by load is enabled.
+func (o *PersistOptions) IsHotRegionSplitEnabled() bool {
+	return o.GetScheduleConfig().EnableHotRegionSplit
+}
+
+// GetHotRegionSplitPolicy returns the policy to split hot regions.
+func (o *PersistOptions) GetHotRegionSplitPolicy() pdpb.CheckPolicy {
+	if o.GetScheduleConfig().HotRegionSplitPolicy == "scan" {
+		return pdpb.CheckPolicy_SCAN
+	}
+	return pdpb.CheckPolicy_APPROXIMATE
+}
+
 // GetKeyType is to get key type.
 func (o *PersistOptions) GetKeyType() core.KeyType {
 	return core.StringToKeyType(o.GetPDServerConfig().KeyType)
@@ -385,6 +393,16 @@ func (o *PersistOptions) GetHotRegionCacheHitsThreshold() int {
 	return int(o.GetScheduleConfig().HotRegionCacheHitsThreshold)
 }
 
+// GetHotRegionsWriteInterval gets the interval to write the hot region history.
+func (o *PersistOptions) GetHotRegionsWriteInterval() time.Duration {
+	return o.GetScheduleConfig().HotRegionsWriteInterval.Duration
+}
+
+// GetHotRegionsReservedDays gets the days to keep the hot region history.
+func (o *PersistOptions) GetHotRegionsReservedDays() uint64 {
+	return o.GetScheduleConfig().HotRegionsReservedDays
+}
+
 // GetSchedulers gets the scheduler configurations.
 func (o *PersistOptions) GetSchedulers() SchedulerConfigs {
 	return o.GetScheduleConfig().Schedulers
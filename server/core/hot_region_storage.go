@@ -0,0 +1,215 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"go.uber.org/zap"
+)
+
+const (
+	// HotReadRegionType is the type of the hot read regions in the history.
+	HotReadRegionType = "read"
+	// HotWriteRegionType is the type of the hot write regions in the history.
+	HotWriteRegionType = "write"
+
+	hotRegionHistoryPath = "hot_region"
+	// maxDeleteBatchSize bounds the keys deleted in a leveldb batch.
+	maxDeleteBatchSize = 1000
+)
+
+// HotRegionTypes are all types of the hot regions in the history.
+var HotRegionTypes = []string{HotReadRegionType, HotWriteRegionType}
+
+// HistoryHotRegion is a hot peer recorded in the hot region history.
+type HistoryHotRegion struct {
+	// UpdateTime is the unix time in milliseconds when the peer is recorded.
+	UpdateTime    int64   `json:"update_time"`
+	RegionID      uint64  `json:"region_id"`
+	StoreID       uint64  `json:"store_id"`
+	IsLeader      bool    `json:"is_leader"`
+	HotRegionType string  `json:"hot_region_type"`
+	HotDegree     int     `json:"hot_degree"`
+	FlowBytes     float64 `json:"flow_bytes"`
+	FlowKeys      float64 `json:"flow_keys"`
+}
+
+// HotRegionStorageHandler provides the hot regions and the options for
+// the hot region history.
+type HotRegionStorageHandler interface {
+	// PackHistoryHotRegions returns the current hot regions of the type.
+	PackHistoryHotRegions(hotRegionType string) ([]HistoryHotRegion, error)
+	// IsLeader returns if the server is the leader. Only the leader records
+	// the hot regions.
+	IsLeader() bool
+	// GetHotRegionsWriteInterval returns the interval to record the hot regions.
+	GetHotRegionsWriteInterval() time.Duration
+	// GetHotRegionsReservedDays returns the days to keep the history, 0
+	// means not to record the history.
+	GetHotRegionsReservedDays() uint64
+}
+
+// HotRegionStorage records the hot regions into a local leveldb periodically.
+type HotRegionStorage struct {
+	*kv.LeveldbKV
+	handler HotRegionStorageHandler
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewHotRegionStorage returns a hot region storage that records the hot
+// regions of the handler in the background.
+func NewHotRegionStorage(ctx context.Context, path string, handler HotRegionStorageHandler) (*HotRegionStorage, error) {
+	levelDB, err := kv.NewLeveldbKV(path)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	h := &HotRegionStorage{
+		LeveldbKV: levelDB,
+		handler:   handler,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	go h.backgroundFlush()
+	return h, nil
+}
+
+func (h *HotRegionStorage) backgroundFlush() {
+	// The interval may be changed online, so reset the timer every round.
+	timer := time.NewTimer(h.handler.GetHotRegionsWriteInterval())
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if h.handler.IsLeader() && h.handler.GetHotRegionsReservedDays() > 0 {
+				if err := h.flush(); err != nil {
+					log.Error("flush hot regions meet error", zap.Error(err))
+				}
+				before := time.Now().AddDate(0, 0, -int(h.handler.GetHotRegionsReservedDays()))
+				if err := h.DeleteHotRegions(before); err != nil {
+					log.Error("delete hot regions meet error", zap.Error(err))
+				}
+			}
+			timer.Reset(h.handler.GetHotRegionsWriteInterval())
+		case <-h.ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *HotRegionStorage) flush() error {
+	for _, typ := range HotRegionTypes {
+		regions, err := h.handler.PackHistoryHotRegions(typ)
+		if err != nil {
+			return err
+		}
+		if err := h.SaveHotRegions(regions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hotRegionPath(hotRegionType string, updateTime int64, regionID, storeID uint64) string {
+	return path.Join(hotRegionHistoryPath, hotRegionType,
+		fmt.Sprintf("%020d", updateTime), fmt.Sprintf("%020d", regionID), fmt.Sprintf("%020d", storeID))
+}
+
+// SaveHotRegions saves the hot regions into the storage.
+func (h *HotRegionStorage) SaveHotRegions(regions []HistoryHotRegion) error {
+	batch := new(leveldb.Batch)
+	for _, region := range regions {
+		value, err := json.Marshal(region)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		batch.Put([]byte(hotRegionPath(region.HotRegionType, region.UpdateTime, region.RegionID, region.StoreID)), value)
+	}
+	return errors.WithStack(h.Write(batch, nil))
+}
+
+// LoadHotRegions loads the hot regions of the types recorded in [start, end),
+// the result is sorted by the update time.
+func (h *HotRegionStorage) LoadHotRegions(hotRegionTypes []string, start, end time.Time) ([]*HistoryHotRegion, error) {
+	var ret []*HistoryHotRegion
+	for _, typ := range hotRegionTypes {
+		iter := h.NewIterator(&util.Range{
+			Start: []byte(hotRegionPath(typ, start.UnixNano()/int64(time.Millisecond), 0, 0)),
+			Limit: []byte(hotRegionPath(typ, end.UnixNano()/int64(time.Millisecond), 0, 0)),
+		}, nil)
+		for iter.Next() {
+			region := &HistoryHotRegion{}
+			if err := json.Unmarshal(iter.Value(), region); err != nil {
+				iter.Release()
+				return nil, errors.WithStack(err)
+			}
+			ret = append(ret, region)
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].UpdateTime < ret[j].UpdateTime })
+	return ret, nil
+}
+
+// DeleteHotRegions deletes the hot regions recorded before the time, the keys
+// are deleted in batches of at most maxDeleteBatchSize.
+func (h *HotRegionStorage) DeleteHotRegions(before time.Time) error {
+	batch := new(leveldb.Batch)
+	for _, typ := range HotRegionTypes {
+		iter := h.NewIterator(&util.Range{
+			Start: []byte(hotRegionPath(typ, 0, 0, 0)),
+			Limit: []byte(hotRegionPath(typ, before.UnixNano()/int64(time.Millisecond), 0, 0)),
+		}, nil)
+		for iter.Next() {
+			batch.Delete(append([]byte(nil), iter.Key()...))
+			if batch.Len() < maxDeleteBatchSize {
+				continue
+			}
+			if err := h.Write(batch, nil); err != nil {
+				iter.Release()
+				return errors.WithStack(err)
+			}
+			batch.Reset()
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return errors.WithStack(h.Write(batch, nil))
+}
+
+// Close stops recording and closes the storage.
+func (h *HotRegionStorage) Close() error {
+	h.cancel()
+	return errors.WithStack(h.LeveldbKV.Close())
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testHotRegionStorageSuite{})
+
+type testHotRegionStorageSuite struct{}
+
+type mockHotRegionStorageHandler struct {
+	regions map[string][]HistoryHotRegion
+}
+
+func (h *mockHotRegionStorageHandler) PackHistoryHotRegions(hotRegionType string) ([]HistoryHotRegion, error) {
+	return h.regions[hotRegionType], nil
+}
+
+func (h *mockHotRegionStorageHandler) IsLeader() bool {
+	return true
+}
+
+func (h *mockHotRegionStorageHandler) GetHotRegionsWriteInterval() time.Duration {
+	return time.Hour
+}
+
+func (h *mockHotRegionStorageHandler) GetHotRegionsReservedDays() uint64 {
+	return 7
+}
+
+func (s *testHotRegionStorageSuite) TestHotRegionStorage(c *C) {
+	dir, err := ioutil.TempDir("", "hot-region")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	toMillis := func(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+	handler := &mockHotRegionStorageHandler{regions: map[string][]HistoryHotRegion{
+		HotReadRegionType: {
+			{UpdateTime: toMillis(now.Add(-time.Minute)), RegionID: 1, StoreID: 1, IsLeader: true, HotRegionType: HotReadRegionType, FlowBytes: 100},
+		},
+		HotWriteRegionType: {
+			{UpdateTime: toMillis(now.Add(-2 * time.Minute)), RegionID: 2, StoreID: 1, IsLeader: true, HotRegionType: HotWriteRegionType, FlowKeys: 10},
+			{UpdateTime: toMillis(now.Add(-2 * time.Minute)), RegionID: 2, StoreID: 2, HotRegionType: HotWriteRegionType, FlowKeys: 10},
+		},
+	}}
+	storage, err := NewHotRegionStorage(context.Background(), dir, handler)
+	c.Assert(err, IsNil)
+	defer storage.Close()
+	c.Assert(storage.flush(), IsNil)
+
+	regions, err := storage.LoadHotRegions(HotRegionTypes, now.Add(-time.Hour), now)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 3)
+	// sorted by the update time
+	c.Assert(regions[0].HotRegionType, Equals, HotWriteRegionType)
+	c.Assert(regions[2], DeepEquals, &handler.regions[HotReadRegionType][0])
+
+	regions, err = storage.LoadHotRegions([]string{HotWriteRegionType}, now.Add(-time.Hour), now)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 2)
+	regions, err = storage.LoadHotRegions(HotRegionTypes, now.Add(-90*time.Second), now)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+
+	c.Assert(storage.DeleteHotRegions(now.Add(-90*time.Second)), IsNil)
+	regions, err = storage.LoadHotRegions(HotRegionTypes, now.Add(-time.Hour), now)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	c.Assert(regions[0].RegionID, Equals, uint64(1))
+
+	// the expired regions are deleted in batches.
+	expired := make([]HistoryHotRegion, 0, 2*maxDeleteBatchSize+1)
+	for i := 0; i < cap(expired); i++ {
+		expired = append(expired, HistoryHotRegion{UpdateTime: toMillis(now.Add(-time.Hour)), RegionID: uint64(i + 10), StoreID: 1, HotRegionType: HotReadRegionType})
+	}
+	c.Assert(storage.SaveHotRegions(expired), IsNil)
+	regions, err = storage.LoadHotRegions(HotRegionTypes, now.Add(-2*time.Hour), now)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, len(expired)+1)
+	c.Assert(storage.DeleteHotRegions(now.Add(-90*time.Second)), IsNil)
+	regions, err = storage.LoadHotRegions(HotRegionTypes, now.Add(-2*time.Hour), now)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+}
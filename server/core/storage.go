@@ -37,14 +37,22 @@ const (
 	schedulePath             = "schedule"
 	gcPath                   = "gc"
 	rulesPath                = "rules"
+	ruleGroupPath            = "rule_group"
 	replicationPath          = "replication_mode"
 	componentPath            = "component"
 	customScheduleConfigPath = "scheduler_config"
+	operatorHistoryPath      = "operator_history"
 )
 
+// RecoveredRegionsPath is the key written by pd-recover after restoring the
+// regions of a backup into the base storage.
+const RecoveredRegionsPath = "recovered_regions"
+
 const (
 	maxKVRangeLimit = 10000
 	minKVRangeLimit = 100
+	// maxKVBatchOps is less than the default max-txn-ops of etcd.
+	maxKVBatchOps = 100
 )
 
 // Storage wraps all kv operations, keep it stateless.
@@ -172,14 +180,52 @@ func (s *Storage) LoadRegionsOnce(f func(region *RegionInfo) []*RegionInfo) erro
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.regionLoaded == 0 {
-		if err := loadRegions(s.regionStorage, f); err != nil {
+		var count int
+		countFunc := func(region *RegionInfo) []*RegionInfo {
+			count++
+			return f(region)
+		}
+		if err := loadRegions(s.regionStorage, countFunc); err != nil {
 			return err
 		}
+		if count == 0 {
+			if err := s.loadRecoveredRegions(f); err != nil {
+				return err
+			}
+		}
 		s.regionLoaded = 1
 	}
 	return nil
 }
 
+// loadRecoveredRegions loads the regions restored by pd-recover in the base
+// storage. They are moved into the region storage and the mark is removed, so
+// the stale regions of the base storage are never loaded again, for example
+// by a member joined later.
+func (s *Storage) loadRecoveredRegions(f func(region *RegionInfo) []*RegionInfo) error {
+	recovered, err := s.Load(RecoveredRegionsPath)
+	if err != nil || recovered == "" {
+		return err
+	}
+	var saveErr error
+	saveFunc := func(region *RegionInfo) []*RegionInfo {
+		if err := s.regionStorage.SaveRegion(region.GetMeta()); err != nil && saveErr == nil {
+			saveErr = err
+		}
+		return f(region)
+	}
+	if err := loadRegions(s.Base, saveFunc); err != nil {
+		return err
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+	if err := s.regionStorage.FlushRegion(); err != nil {
+		return err
+	}
+	return s.Remove(RecoveredRegionsPath)
+}
+
 // SaveRegion saves one region to storage.
 func (s *Storage) SaveRegion(region *metapb.Region) error {
 	if atomic.LoadInt32(&s.useRegionStorage) > 0 {
@@ -235,12 +281,149 @@ func (s *Storage) DeleteRule(ruleKey string) error {
 	return s.Base.Remove(path.Join(rulesPath, ruleKey))
 }
 
+// SaveRulesBatch saves and removes rules in a single batch. The rules to save
+// are keyed by their rule keys. The batch should not exceed maxKVBatchOps.
+func (s *Storage) SaveRulesBatch(toSave map[string]interface{}, toDelete []string) error {
+	if n := len(toSave) + len(toDelete); n > maxKVBatchOps {
+		return errors.Errorf("too many rules in a batch: %d, the limit is %d", n, maxKVBatchOps)
+	}
+	ops := make([]kv.Op, 0, len(toSave)+len(toDelete))
+	for ruleKey, rule := range toSave {
+		value, err := json.Marshal(rule)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ops = append(ops, kv.SaveOp(path.Join(rulesPath, ruleKey), string(value)))
+	}
+	for _, ruleKey := range toDelete {
+		ops = append(ops, kv.RemoveOp(path.Join(rulesPath, ruleKey)))
+	}
+	return s.Batch(ops)
+}
+
 // LoadRules loads placement rules from storage.
 func (s *Storage) LoadRules(f func(k, v string)) (bool, error) {
-	// Range is ['rule/\x00', 'rule0'). 'rule0' is the upper bound of all rules because '0' is next char of '/' in
-	// ascii order.
-	nextKey := path.Join(rulesPath, "\x00")
-	endKey := rulesPath + "0"
+	return s.loadRangeByPrefix(rulesPath+"/", f)
+}
+
+// SaveRuleGroup stores a rule group config to storage.
+func (s *Storage) SaveRuleGroup(groupID string, group interface{}) error {
+	value, err := json.Marshal(group)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(ruleGroupPath, groupID), string(value))
+}
+
+// DeleteRuleGroup removes a rule group from storage.
+func (s *Storage) DeleteRuleGroup(groupID string) error {
+	return s.Base.Remove(path.Join(ruleGroupPath, groupID))
+}
+
+// LoadRuleGroups loads all rule groups from storage.
+func (s *Storage) LoadRuleGroups(f func(k, v string)) (bool, error) {
+	return s.loadRangeByPrefix(ruleGroupPath+"/", f)
+}
+
+// OperatorHistoryKey returns the key of an operator record. The records are
+// ordered by their finish time, the sequence distinguishes the records of the
+// same region finished at the same time.
+func OperatorHistoryKey(finishTime time.Time, regionID, seq uint64) string {
+	return fmt.Sprintf("%020d-%020d-%020d", finishTime.UnixNano(), regionID, seq)
+}
+
+func operatorHistoryTimeKey(t time.Time) string {
+	return path.Join(operatorHistoryPath, fmt.Sprintf("%020d", t.UnixNano()))
+}
+
+// SaveOperatorHistory stores operator records keyed by OperatorHistoryKey.
+func (s *Storage) SaveOperatorHistory(records map[string]interface{}) error {
+	ops := make([]kv.Op, 0, len(records))
+	for key, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ops = append(ops, kv.SaveOp(path.Join(operatorHistoryPath, key), string(value)))
+	}
+	for len(ops) > 0 {
+		n := len(ops)
+		if n > maxKVBatchOps {
+			n = maxKVBatchOps
+		}
+		if err := s.Batch(ops[:n]); err != nil {
+			return err
+		}
+		ops = ops[n:]
+	}
+	return nil
+}
+
+// LoadOperatorHistory loads operator records finished in [start, end).
+func (s *Storage) LoadOperatorHistory(start, end time.Time, f func(k, v string)) error {
+	nextKey, endKey := operatorHistoryTimeKey(start), operatorHistoryTimeKey(end)
+	for {
+		keys, values, err := s.LoadRange(nextKey, endKey, minKVRangeLimit)
+		if err != nil {
+			return err
+		}
+		for i := range keys {
+			f(strings.TrimPrefix(keys[i], operatorHistoryPath+"/"), values[i])
+		}
+		if len(keys) < minKVRangeLimit {
+			return nil
+		}
+		nextKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
+// CountOperatorHistory returns the count of the operator records.
+func (s *Storage) CountOperatorHistory() (int, error) {
+	var count int
+	_, err := s.loadRangeByPrefix(operatorHistoryPath+"/", func(k, v string) { count++ })
+	return count, err
+}
+
+// DeleteOperatorHistory removes operator records finished before the time,
+// and at least the oldest minCount records. It returns the count of the
+// removed records.
+func (s *Storage) DeleteOperatorHistory(before time.Time, minCount int) (int, error) {
+	startKey := operatorHistoryTimeKey(time.Unix(0, 0))
+	endKey := clientv3.GetPrefixRangeEnd(operatorHistoryPath + "/")
+	beforeKey := operatorHistoryTimeKey(before)
+	var removed int
+	for {
+		keys, _, err := s.LoadRange(startKey, endKey, maxKVBatchOps)
+		if err != nil {
+			return removed, err
+		}
+		ops := make([]kv.Op, 0, len(keys))
+		for _, key := range keys {
+			if removed+len(ops) >= minCount && key >= beforeKey {
+				break
+			}
+			ops = append(ops, kv.RemoveOp(key))
+		}
+		if len(ops) == 0 {
+			return removed, nil
+		}
+		if err := s.Batch(ops); err != nil {
+			return removed, err
+		}
+		removed += len(ops)
+		if len(ops) < maxKVBatchOps {
+			return removed, nil
+		}
+		startKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
+// loadRangeByPrefix iterates all key-value pairs in the storage that has the prefix.
+func (s *Storage) loadRangeByPrefix(prefix string, f func(k, v string)) (bool, error) {
+	// Append '\x00' to the prefix because etcd kv base joins key with root path,
+	// which trims the trailing '/'.
+	nextKey := path.Join(prefix, "\x00")
+	endKey := clientv3.GetPrefixRangeEnd(prefix)
 	for {
 		keys, values, err := s.LoadRange(nextKey, endKey, minKVRangeLimit)
 		if err != nil {
@@ -250,7 +433,7 @@ func (s *Storage) LoadRules(f func(k, v string)) (bool, error) {
 			return false, nil
 		}
 		for i := range keys {
-			f(strings.TrimPrefix(keys[i], rulesPath+"/"), values[i])
+			f(strings.TrimPrefix(keys[i], prefix), values[i])
 		}
 		if len(keys) < minKVRangeLimit {
 			return true, nil
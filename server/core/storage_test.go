@@ -14,9 +14,12 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"os"
 	"path"
 	"strings"
 	"time"
@@ -169,6 +172,38 @@ func (s *testKVSuite) TestLoadRegionsToCache(c *C) {
 	c.Assert(cache.GetRegionCount(), Equals, n)
 }
 
+func (s *testKVSuite) TestLoadRegionsFromBaseOnce(c *C) {
+	dir, err := ioutil.TempDir("", "region-storage")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	regionStorage, err := NewRegionStorage(context.Background(), dir)
+	c.Assert(err, IsNil)
+	defer regionStorage.Close()
+	storage := NewStorage(kv.NewMemoryKV()).SetRegionStorage(regionStorage)
+
+	// the regions of the base storage are not loaded without the mark.
+	n := 10
+	mustSaveRegions(c, storage, n)
+	storage.SwitchToRegionStorage()
+	cache := NewRegionsInfo()
+	c.Assert(storage.LoadRegionsOnce(cache.SetRegion), IsNil)
+	c.Assert(cache.GetRegionCount(), Equals, 0)
+
+	// the region storage is empty, load the regions restored by pd-recover.
+	c.Assert(storage.Save(RecoveredRegionsPath, "1"), IsNil)
+	storage.regionLoaded = 0
+	c.Assert(storage.LoadRegionsOnce(cache.SetRegion), IsNil)
+	c.Assert(cache.GetRegionCount(), Equals, n)
+	recovered, err := storage.Load(RecoveredRegionsPath)
+	c.Assert(err, IsNil)
+	c.Assert(recovered, Equals, "")
+
+	// the regions are moved into the region storage.
+	cache = NewRegionsInfo()
+	c.Assert(loadRegions(regionStorage, cache.SetRegion), IsNil)
+	c.Assert(cache.GetRegionCount(), Equals, n)
+}
+
 func (s *testKVSuite) TestLoadRegionsExceedRangeLimit(c *C) {
 	storage := NewStorage(&KVWithMaxRangeLimit{Base: kv.NewMemoryKV(), rangeLimit: 500})
 	cache := NewRegionsInfo()
@@ -251,6 +286,71 @@ func (s *testKVSuite) TestLoadMinServiceGCSafePoint(c *C) {
 	c.Assert(ssp.SafePoint, Equals, uint64(2))
 }
 
+func (s *testKVSuite) TestOperatorHistory(c *C) {
+	base := kv.NewMemoryKV()
+	storage := NewStorage(base)
+	start := time.Unix(1000, 0)
+	keyOf := func(i int) string {
+		return OperatorHistoryKey(start.Add(time.Duration(i)*time.Second), uint64(i), 0)
+	}
+	records := make(map[string]interface{})
+	for i := 0; i < 250; i++ {
+		records[keyOf(i)] = i
+	}
+	// the records of the same region finished at the same time are both kept.
+	records[OperatorHistoryKey(start.Add(time.Hour), 1, 1)] = 1000
+	records[OperatorHistoryKey(start.Add(time.Hour), 1, 2)] = 1001
+	c.Assert(storage.SaveOperatorHistory(records), IsNil)
+
+	// the history is read back by the storage of another server.
+	storage = NewStorage(base)
+	load := func(from, to time.Time) []int {
+		var values []int
+		err := storage.LoadOperatorHistory(from, to, func(k, v string) {
+			var i int
+			c.Assert(json.Unmarshal([]byte(v), &i), IsNil)
+			if i < 1000 {
+				c.Assert(k, Equals, keyOf(i))
+			}
+			values = append(values, i)
+		})
+		c.Assert(err, IsNil)
+		return values
+	}
+	values := load(start, start.Add(2*time.Hour))
+	c.Assert(values, HasLen, 252)
+	for i, v := range values[:250] {
+		c.Assert(v, Equals, i)
+	}
+	c.Assert(values[250:], DeepEquals, []int{1000, 1001})
+	// the end is exclusive.
+	values = load(start.Add(10*time.Second), start.Add(20*time.Second))
+	c.Assert(values, HasLen, 10)
+	c.Assert(values[0], Equals, 10)
+	count, err := storage.CountOperatorHistory()
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 252)
+
+	// delete by the finish time.
+	removed, err := storage.DeleteOperatorHistory(start.Add(200*time.Second), 0)
+	c.Assert(err, IsNil)
+	c.Assert(removed, Equals, 200)
+	values = load(start, start.Add(2*time.Hour))
+	c.Assert(values, HasLen, 52)
+	c.Assert(values[0], Equals, 200)
+
+	// delete by the count.
+	removed, err = storage.DeleteOperatorHistory(start, 42)
+	c.Assert(err, IsNil)
+	c.Assert(removed, Equals, 42)
+	values = load(start, start.Add(2*time.Hour))
+	c.Assert(values, HasLen, 10)
+	c.Assert(values[0], Equals, 242)
+	count, err = storage.CountOperatorHistory()
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 10)
+}
+
 type KVWithMaxRangeLimit struct {
 	kv.Base
 	rangeLimit int
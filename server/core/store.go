@@ -142,6 +142,12 @@ func (s *StoreInfo) IsTombstone() bool {
 	return s.GetState() == metapb.StoreState_Tombstone
 }
 
+// IsWitness checks if the store is labeled as a witness store, so that peers
+// on it can only be witnesses.
+func (s *StoreInfo) IsWitness() bool {
+	return IsWitnessStore(s.meta)
+}
+
 // DownTime returns the time elapsed since last heartbeat.
 func (s *StoreInfo) DownTime() time.Duration {
 	return time.Since(s.GetLastHeartbeatTS())
@@ -682,3 +688,14 @@ func IsTiFlashStore(store *metapb.Store) bool {
 	}
 	return false
 }
+
+// IsWitnessStore used to judge witness store by the `engine=witness` label.
+// All peers on a witness store are witnesses.
+func IsWitnessStore(store *metapb.Store) bool {
+	for _, l := range store.GetLabels() {
+		if l.GetKey() == "engine" && l.GetValue() == "witness" {
+			return true
+		}
+	}
+	return false
+}
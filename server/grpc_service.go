@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -25,8 +26,10 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -41,6 +44,16 @@ var (
 	// TODO: work as proxy.
 	ErrNotLeader  = status.Errorf(codes.Unavailable, "not leader")
 	ErrNotStarted = status.Errorf(codes.Unavailable, "server not started")
+	// ErrFollowerNotSynced is returned when the follower is lagging behind the
+	// leader and not possible to serve the follower read request.
+	ErrFollowerNotSynced = status.Errorf(codes.Unavailable, "follower is not synced with leader")
+	// ErrFollowerNoLeader is returned when the follower does not know the
+	// leader of the region, the region syncer does not sync the leaders, so
+	// the request should be served by the leader.
+	ErrFollowerNoLeader = status.Errorf(codes.Unavailable, "follower does not know the region leader")
+	// ErrFollowerNoStore is returned when the store is not loaded by the
+	// follower yet.
+	ErrFollowerNoStore = status.Errorf(codes.Unavailable, "follower does not know the store")
 )
 
 // GetMembers implements gRPC PDServer.
@@ -169,9 +182,13 @@ func (s *Server) AllocID(ctx context.Context, request *pdpb.AllocIDRequest) (*pd
 
 // GetStore implements gRPC PDServer.
 func (s *Server) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*pdpb.GetStoreResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	followerRead, err := s.validateFollowerRead(ctx, request.GetHeader(), false)
+	if err != nil {
 		return nil, err
 	}
+	if followerRead {
+		return s.getStoreFromFollower(request.GetStoreId())
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -190,6 +207,19 @@ func (s *Server) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*
 	}, nil
 }
 
+// getStoreFromFollower gets the store loaded by the follower for the follower
+// read, the store stats are only kept by the leader.
+func (s *Server) getStoreFromFollower(storeID uint64) (*pdpb.GetStoreResponse, error) {
+	store := s.basicCluster.GetStore(storeID)
+	if store == nil {
+		return nil, errors.WithStack(ErrFollowerNoStore)
+	}
+	return &pdpb.GetStoreResponse{
+		Header: s.header(),
+		Store:  store.GetMeta(),
+	}, nil
+}
+
 // checkStore returns an error response if the store exists and is in tombstone state.
 // It returns nil if it can't get the store.
 func checkStore(rc *cluster.RaftCluster, storeID uint64) *pdpb.Error {
@@ -224,7 +254,7 @@ func (s *Server) PutStore(ctx context.Context, request *pdpb.PutStoreRequest) (*
 	}
 
 	// NOTE: can be removed when placement rules feature is enabled by default.
-	if !s.GetConfig().Replication.EnablePlacementRules && core.IsTiFlashStore(store) {
+	if !s.GetConfig().Replication.EnablePlacementRules && (core.IsTiFlashStore(store) || core.IsWitnessStore(store)) {
 		return nil, status.Errorf(codes.FailedPrecondition, "placement rules is disabled")
 	}
 
@@ -414,15 +444,24 @@ func (s *Server) RegionHeartbeat(stream pdpb.PD_RegionHeartbeatServer) error {
 
 // GetRegion implements gRPC PDServer.
 func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	followerRead, err := s.validateFollowerRead(ctx, request.GetHeader(), true)
+	if err != nil {
 		return nil, err
 	}
 
-	rc := s.GetRaftCluster()
-	if rc == nil {
-		return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
+	var region *core.RegionInfo
+	if followerRead {
+		region = s.basicCluster.SearchRegion(request.GetRegionKey())
+		if region != nil && region.GetLeader() == nil {
+			return nil, errors.WithStack(ErrFollowerNoLeader)
+		}
+	} else {
+		rc := s.GetRaftCluster()
+		if rc == nil {
+			return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
+		}
+		region = rc.GetRegionByKey(request.GetRegionKey())
 	}
-	region := rc.GetRegionByKey(request.GetRegionKey())
 	if region == nil {
 		return &pdpb.GetRegionResponse{Header: s.header()}, nil
 	}
@@ -437,16 +476,25 @@ func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest)
 
 // GetPrevRegion implements gRPC PDServer
 func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	followerRead, err := s.validateFollowerRead(ctx, request.GetHeader(), true)
+	if err != nil {
 		return nil, err
 	}
 
-	rc := s.GetRaftCluster()
-	if rc == nil {
-		return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
+	var region *core.RegionInfo
+	if followerRead {
+		region = s.basicCluster.SearchPrevRegion(request.GetRegionKey())
+		if region != nil && region.GetLeader() == nil {
+			return nil, errors.WithStack(ErrFollowerNoLeader)
+		}
+	} else {
+		rc := s.GetRaftCluster()
+		if rc == nil {
+			return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
+		}
+		region = rc.GetPrevRegionByKey(request.GetRegionKey())
 	}
 
-	region := rc.GetPrevRegionByKey(request.GetRegionKey())
 	if region == nil {
 		return &pdpb.GetRegionResponse{Header: s.header()}, nil
 	}
@@ -484,15 +532,26 @@ func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDR
 
 // ScanRegions implements gRPC PDServer.
 func (s *Server) ScanRegions(ctx context.Context, request *pdpb.ScanRegionsRequest) (*pdpb.ScanRegionsResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	followerRead, err := s.validateFollowerRead(ctx, request.GetHeader(), true)
+	if err != nil {
 		return nil, err
 	}
 
-	rc := s.GetRaftCluster()
-	if rc == nil {
-		return &pdpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
+	var regions []*core.RegionInfo
+	if followerRead {
+		regions = s.basicCluster.ScanRange(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
+		for _, r := range regions {
+			if r.GetLeader() == nil {
+				return nil, errors.WithStack(ErrFollowerNoLeader)
+			}
+		}
+	} else {
+		rc := s.GetRaftCluster()
+		if rc == nil {
+			return &pdpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
+		}
+		regions = rc.ScanRegions(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
 	}
-	regions := rc.ScanRegions(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
 	resp := &pdpb.ScanRegionsResponse{Header: s.header()}
 	for _, r := range regions {
 		leader := r.GetLeader()
@@ -655,6 +714,14 @@ func (s *Server) ScatterRegion(ctx context.Context, request *pdpb.ScatterRegionR
 		return &pdpb.ScatterRegionResponse{Header: s.notBootstrappedHeader()}, nil
 	}
 
+	regionIDs, err := grpcutil.GetScatterRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(regionIDs) > 0 {
+		return s.scatterRegions(regionIDs, grpcutil.GetScatterGroup(ctx))
+	}
+
 	region := rc.GetRegion(request.GetRegionId())
 	if region == nil {
 		if request.GetRegion() == nil {
@@ -667,12 +734,16 @@ func (s *Server) ScatterRegion(ctx context.Context, request *pdpb.ScatterRegionR
 		return nil, errors.Errorf("region %d is a hot region", region.GetID())
 	}
 
-	op, err := rc.GetRegionScatter().Scatter(region)
-	if err != nil {
-		return nil, err
+	addOp := func(op *operator.Operator) error {
+		if !rc.GetOperatorController().AddOperator(op) {
+			return ErrAddOperator
+		}
+		return nil
 	}
-	if op != nil {
-		rc.GetOperatorController().AddOperator(op)
+	// a rejected operator is ignored as before, it only keeps the group
+	// distribution unchanged.
+	if err := rc.GetRegionScatter().ScatterWithGroup(region, grpcutil.GetScatterGroup(ctx), addOp); err != nil && err != ErrAddOperator {
+		return nil, err
 	}
 
 	return &pdpb.ScatterRegionResponse{
@@ -680,6 +751,30 @@ func (s *Server) ScatterRegion(ctx context.Context, request *pdpb.ScatterRegionR
 	}, nil
 }
 
+// scatterRegions scatters a batch of regions sent by a single request, the
+// failed regions are reported by the error of the response header.
+func (s *Server) scatterRegions(regionIDs []uint64, group string) (*pdpb.ScatterRegionResponse, error) {
+	_, failures, _, err := s.GetHandler().ScatterRegions(regionIDs, nil, nil, group, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(failures) == 0 {
+		return &pdpb.ScatterRegionResponse{Header: s.header()}, nil
+	}
+	msgs := make([]string, 0, len(failures))
+	for _, id := range regionIDs {
+		if msg, ok := failures[id]; ok {
+			msgs = append(msgs, fmt.Sprintf("region %d: %s", id, msg))
+		}
+	}
+	return &pdpb.ScatterRegionResponse{
+		Header: s.errorHeader(&pdpb.Error{
+			Type:    pdpb.ErrorType_UNKNOWN,
+			Message: strings.Join(msgs, "; "),
+		}),
+	}, nil
+}
+
 // GetGCSafePoint implements gRPC PDServer.
 func (s *Server) GetGCSafePoint(ctx context.Context, request *pdpb.GetGCSafePointRequest) (*pdpb.GetGCSafePointResponse, error) {
 	if err := s.validateRequest(request.GetHeader()); err != nil {
@@ -849,6 +944,35 @@ func (s *Server) validateRequest(header *pdpb.RequestHeader) error {
 	return nil
 }
 
+// validateFollowerRead checks if the request can be served by the server. It
+// returns true if the server is a follower and the request is a follower read
+// request. If needRegions is true, the follower should be synced with the
+// leader by the region syncer within the max staleness of the request.
+func (s *Server) validateFollowerRead(ctx context.Context, header *pdpb.RequestHeader, needRegions bool) (bool, error) {
+	if s.IsClosed() {
+		return false, errors.WithStack(ErrNotStarted)
+	}
+	if s.member.IsLeader() {
+		return false, s.validateRequest(header)
+	}
+	maxStaleness, ok := grpcutil.IsFollowerRead(ctx)
+	if !ok {
+		return false, errors.WithStack(ErrNotLeader)
+	}
+	if header.GetClusterId() != s.clusterID {
+		return false, status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+	}
+	if needRegions {
+		lastSyncTime := s.cluster.GetRegionSyncer().GetLastSyncTime()
+		if lastSyncTime.IsZero() || time.Since(lastSyncTime) > maxStaleness {
+			followerReadCounter.WithLabelValues("not-synced").Inc()
+			return false, errors.WithStack(ErrFollowerNotSynced)
+		}
+	}
+	followerReadCounter.WithLabelValues("served").Inc()
+	return true, nil
+}
+
 func (s *Server) header() *pdpb.ResponseHeader {
 	return &pdpb.ResponseHeader{ClusterId: s.clusterID}
 }
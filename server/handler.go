@@ -40,6 +40,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxScatterRegions is the max count of regions scattered by a key range once.
+const maxScatterRegions = 10000
+
 var (
 	// SchedulerConfigHandlerPath is the api router path of the schedule config handler.
 	SchedulerConfigHandlerPath = "/api/v1/scheduler-config"
@@ -204,6 +207,68 @@ func (h *Handler) GetHotKeysReadStores() map[uint64]float64 {
 	return rc.GetStoresKeysReadStat()
 }
 
+// PackHistoryHotRegions returns the current hot peers of the type to be
+// recorded in the hot region history.
+func (h *Handler) PackHistoryHotRegions(hotRegionType string) ([]core.HistoryHotRegion, error) {
+	rc := h.s.GetRaftCluster()
+	if rc == nil {
+		return nil, nil
+	}
+	var stats map[uint64][]*statistics.HotPeerStat
+	switch hotRegionType {
+	case core.HotReadRegionType:
+		stats = rc.RegionReadStats()
+	case core.HotWriteRegionType:
+		stats = rc.RegionWriteStats()
+	default:
+		return nil, errors.Errorf("unknown hot region type %s", hotRegionType)
+	}
+	minHotDegree := h.opt.GetHotRegionCacheHitsThreshold()
+	updateTime := time.Now().UnixNano() / int64(time.Millisecond)
+	var regions []core.HistoryHotRegion
+	for _, peers := range stats {
+		for _, peer := range peers {
+			if peer.HotDegree < minHotDegree {
+				continue
+			}
+			regions = append(regions, core.HistoryHotRegion{
+				UpdateTime:    updateTime,
+				RegionID:      peer.RegionID,
+				StoreID:       peer.StoreID,
+				IsLeader:      peer.IsLeader(),
+				HotRegionType: hotRegionType,
+				HotDegree:     peer.HotDegree,
+				FlowBytes:     peer.GetByteRate(),
+				FlowKeys:      peer.GetKeyRate(),
+			})
+		}
+	}
+	return regions, nil
+}
+
+// IsLeader returns whether the server is the leader.
+func (h *Handler) IsLeader() bool {
+	return h.s.GetMember().IsLeader()
+}
+
+// GetHotRegionsWriteInterval gets the interval to record the hot region history.
+func (h *Handler) GetHotRegionsWriteInterval() time.Duration {
+	return h.opt.GetHotRegionsWriteInterval()
+}
+
+// GetHotRegionsReservedDays gets the days to keep the hot region history.
+func (h *Handler) GetHotRegionsReservedDays() uint64 {
+	return h.opt.GetHotRegionsReservedDays()
+}
+
+// GetHistoryHotRegions gets the hot regions of the types recorded in [start, end).
+func (h *Handler) GetHistoryHotRegions(hotRegionTypes []string, start, end time.Time) ([]*core.HistoryHotRegion, error) {
+	if h.s.hotRegionStorage == nil {
+		return nil, ErrServerNotStarted
+	}
+	return h.s.hotRegionStorage.LoadHotRegions(hotRegionTypes, start, end)
+}
+
 // AddScheduler adds a scheduler.
 func (h *Handler) AddScheduler(name string, args ...string) error {
 	c, err := h.GetRaftCluster()
@@ -294,6 +359,11 @@ func (h *Handler) AddEvictLeaderScheduler(storeID uint64) error {
 	return h.AddScheduler(schedulers.EvictLeaderType, strconv.FormatUint(storeID, 10))
 }
 
+// AddEvictSlowStoreScheduler adds an evict-slow-store-scheduler.
+func (h *Handler) AddEvictSlowStoreScheduler() error {
+	return h.AddScheduler(schedulers.EvictSlowStoreType)
+}
+
 // AddShuffleLeaderScheduler adds a shuffle-leader-scheduler.
 func (h *Handler) AddShuffleLeaderScheduler() error {
 	return h.AddScheduler(schedulers.ShuffleLeaderType)
@@ -417,6 +487,15 @@ func (h *Handler) GetHistory(start time.Time) ([]operator.OpHistory, error) {
 	return c.GetHistory(start), nil
 }
 
+// GetOperatorRecords returns the records of operators finished in [start, end).
+func (h *Handler) GetOperatorRecords(start, end time.Time) ([]*operator.OpRecord, error) {
+	c, err := h.GetOperatorController()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetRecords(start, end)
+}
+
 // SetAllStoresLimit is used to set limit of all stores.
 func (h *Handler) SetAllStoresLimit(ratePerMin float64, limitType storelimit.Type) error {
 	c, err := h.GetRaftCluster()
@@ -446,6 +525,19 @@ func (h *Handler) SetStoreLimit(storeID uint64, ratePerMin float64, limitType st
 	return nil
 }
 
+// SetStoreLimitAlgorithm is used to set the limit algorithm of a store.
+func (h *Handler) SetStoreLimitAlgorithm(storeID uint64, algorithm string) error {
+	if _, err := storelimit.ParseAlgorithm(algorithm); err != nil {
+		return err
+	}
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return err
+	}
+	c.SetStoreLimitAlgorithm(storeID, algorithm)
+	return nil
+}
+
 // AddTransferLeaderOperator adds an operator to transfer leader to the store.
 func (h *Handler) AddTransferLeaderOperator(regionID uint64, storeID uint64) error {
 	c, err := h.GetRaftCluster()
@@ -762,6 +854,62 @@ func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
 	return nil
 }
 
+// ScatterRegions scatters the regions specified by IDs or by a key range as a
+// group. It returns the count of operators added and the reasons of the
+// regions which are failed to scatter. At most limit regions of the key range
+// are scattered once, limit is capped by maxScatterRegions, the start key of
+// the remaining regions is returned to continue with if there are more.
+func (h *Handler) ScatterRegions(regionIDs []uint64, startKey, endKey []byte, group string, limit int) (int, map[uint64]string, []byte, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	var (
+		regions []*core.RegionInfo
+		nextKey []byte
+	)
+	failures := make(map[uint64]string)
+	if len(regionIDs) > 0 {
+		for _, id := range regionIDs {
+			region := c.GetRegion(id)
+			if region == nil {
+				failures[id] = ErrRegionNotFound(id).Error()
+				continue
+			}
+			regions = append(regions, region)
+		}
+	} else {
+		if limit <= 0 || limit > maxScatterRegions {
+			limit = maxScatterRegions
+		}
+		regions = c.ScanRegions(startKey, endKey, limit+1)
+		if len(regions) > limit {
+			nextKey = regions[limit].GetStartKey()
+			regions = regions[:limit]
+		}
+	}
+
+	var added int
+	addOp := func(op *operator.Operator) error {
+		if !c.GetOperatorController().AddOperator(op) {
+			return errors.WithStack(ErrAddOperator)
+		}
+		added++
+		return nil
+	}
+	for _, region := range regions {
+		if c.IsRegionHot(region) {
+			failures[region.GetID()] = errors.Errorf("region %d is a hot region", region.GetID()).Error()
+			continue
+		}
+		if err := c.GetRegionScatter().ScatterWithGroup(region, group, addOp); err != nil {
+			failures[region.GetID()] = err.Error()
+		}
+	}
+	return added, failures, nextKey, nil
+}
+
 // GetDownPeerRegions gets the region with down peer.
 func (h *Handler) GetDownPeerRegions() ([]*core.RegionInfo, error) {
 	c := h.s.GetRaftCluster()
@@ -87,6 +87,13 @@ func (s *testEtcdKVSuite) TestEtcdKV(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v, Equals, "")
 
+	err = kv.Batch([]Op{SaveOp(keys[1], "new2"), RemoveOp(keys[2]), SaveOp(keys[3], "new4")})
+	c.Assert(err, IsNil)
+	ks, vs, err = kv.LoadRange(keys[0], "test/zzz", 100)
+	c.Assert(err, IsNil)
+	c.Assert(ks, DeepEquals, []string{keys[0], keys[1], keys[3], keys[4]})
+	c.Assert(vs, DeepEquals, []string{vals[0], "new2", "new4", vals[4]})
+
 	etcd.Close()
 	cleanConfig(cfg)
 }
@@ -19,4 +19,24 @@ type Base interface {
 	LoadRange(key, endKey string, limit int) (keys []string, values []string, err error)
 	Save(key, value string) error
 	Remove(key string) error
+	// Batch applies all operations atomically, either all of them take
+	// effect or none of them do.
+	Batch(ops []Op) error
+}
+
+// Op is a save or remove operation used in a batch.
+type Op struct {
+	Key    string
+	Value  string
+	Remove bool // when it is true, Value is ignored and the key is removed
+}
+
+// SaveOp returns an Op that saves the key-value pair.
+func SaveOp(key, value string) Op {
+	return Op{Key: key, Value: value}
+}
+
+// RemoveOp returns an Op that removes the key.
+func RemoveOp(key string) Op {
+	return Op{Key: key, Remove: true}
 }
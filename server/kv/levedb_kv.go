@@ -72,6 +72,19 @@ func (kv *LeveldbKV) Remove(key string) error {
 	return errors.WithStack(kv.Delete([]byte(key), nil))
 }
 
+// Batch applies the operations in a leveldb batch.
+func (kv *LeveldbKV) Batch(ops []Op) error {
+	batch := new(leveldb.Batch)
+	for _, op := range ops {
+		if op.Remove {
+			batch.Delete([]byte(op.Key))
+		} else {
+			batch.Put([]byte(op.Key), []byte(op.Value))
+		}
+	}
+	return errors.WithStack(kv.Write(batch, nil))
+}
+
 // SaveRegions stores some regions.
 func (kv *LeveldbKV) SaveRegions(regions map[string]*metapb.Region) error {
 	batch := new(leveldb.Batch)
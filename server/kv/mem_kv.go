@@ -79,3 +79,17 @@ func (kv *memoryKV) Remove(key string) error {
 	kv.tree.Delete(memoryKVItem{key, ""})
 	return nil
 }
+
+func (kv *memoryKV) Batch(ops []Op) error {
+	kv.Lock()
+	defer kv.Unlock()
+
+	for _, op := range ops {
+		if op.Remove {
+			kv.tree.Delete(memoryKVItem{op.Key, ""})
+		} else {
+			kv.tree.ReplaceOrInsert(memoryKVItem{op.Key, op.Value})
+		}
+	}
+	return nil
+}
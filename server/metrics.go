@@ -41,6 +41,14 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
 		}, []string{"address", "store"})
 
+	followerReadCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "follower_read_total",
+			Help:      "Counter of the read requests served by the follower.",
+		}, []string{"result"})
+
 	metadataGauge = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "pd",
@@ -71,6 +79,7 @@ func init() {
 	prometheus.MustRegister(timeJumpBackCounter)
 	prometheus.MustRegister(regionHeartbeatCounter)
 	prometheus.MustRegister(regionHeartbeatLatency)
+	prometheus.MustRegister(followerReadCounter)
 	prometheus.MustRegister(metadataGauge)
 	prometheus.MustRegister(etcdStateGauge)
 	prometheus.MustRegister(tsoHandleDuration)
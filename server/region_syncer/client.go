@@ -15,6 +15,7 @@ package syncer
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -33,11 +34,15 @@ import (
 const (
 	keepaliveTime    = 10 * time.Second
 	keepaliveTimeout = 3 * time.Second
+	// storeReloadInterval is the interval to reload the stores of the
+	// follower, the stores are not synced by the region syncer.
+	storeReloadInterval = 30 * time.Second
 )
 
 // StopSyncWithLeader stop to sync the region with leader.
 func (s *RegionSyncer) StopSyncWithLeader() {
 	s.reset()
+	atomic.StoreInt64(&s.lastSyncTime, 0)
 	s.Lock()
 	close(s.closed)
 	s.closed = make(chan struct{})
@@ -115,6 +120,35 @@ func (s *RegionSyncer) syncRegion(conn *grpc.ClientConn) (ClientStream, error) {
 	return syncStream, nil
 }
 
+// GetLastSyncTime returns the time when the follower is caught up with the
+// leader last time, it returns the zero time if the follower is not syncing
+// with the leader.
+func (s *RegionSyncer) GetLastSyncTime() time.Time {
+	t := atomic.LoadInt64(&s.lastSyncTime)
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, t)
+}
+
+// reloadStores loads the stores from the storage periodically, so the
+// follower can serve the stores without reading the storage every time.
+func (s *RegionSyncer) reloadStores(closed chan struct{}) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(storeReloadInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.server.GetStorage().LoadStores(s.server.GetBasicCluster().PutStore); err != nil {
+			log.Warn("failed to load stores", zap.Error(err))
+		}
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // StartSyncWithLeader starts to sync with leader.
 func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 	s.wg.Add(1)
@@ -128,6 +162,8 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 		if err != nil {
 			log.Warn("failed to load regions.", zap.Error(err))
 		}
+		s.wg.Add(1)
+		go s.reloadStores(closed)
 		// establish client.
 		var conn *grpc.ClientConn
 		for {
@@ -165,6 +201,7 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 				continue
 			}
 			log.Info("server starts to synchronize with leader", zap.String("server", s.server.Name()), zap.String("leader", s.server.GetLeader().GetName()), zap.Uint64("request-index", s.history.GetNextIndex()))
+			caughtUp := false
 			for {
 				resp, err := stream.Recv()
 				if err != nil {
@@ -206,6 +243,16 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 						s.history.Record(region)
 					}
 				}
+				// the leader sends the history regions before the keepalive
+				// messages, after that it sends the changed regions in time,
+				// so the follower is caught up with the leader once it applies
+				// a response.
+				if len(regions) == 0 {
+					caughtUp = true
+				}
+				if caughtUp {
+					atomic.StoreInt64(&s.lastSyncTime, time.Now().UnixNano())
+				}
 			}
 		}
 	}()
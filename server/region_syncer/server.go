@@ -14,9 +14,12 @@
 package syncer
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/ratelimit"
@@ -38,8 +41,22 @@ const (
 	maxSyncRegionBatchSize   = 100
 	syncerKeepAliveInterval  = 10 * time.Second
 	defaultHistoryBufferSize = 10000
+	// streamBufferSize is the number of the responses buffered for a stream,
+	// the stream is closed if the buffer is full.
+	streamBufferSize = 1024
 )
 
+const (
+	// LatestIndex is the start index of the requests which only sync the
+	// latest records, the history records are not sent.
+	LatestIndex = math.MaxUint64
+	// ResyncIndex is the start index of the responses which tell a watcher
+	// that some changes are lost, the watcher should rescan its regions.
+	ResyncIndex = math.MaxUint64 - 1
+)
+
+var errStreamTooSlow = status.Errorf(codes.ResourceExhausted, "the stream is too slow to receive the regions")
+
 // ClientStream is the client side of the region syncer.
 type ClientStream interface {
 	Recv() (*pdpb.SyncRegionResponse, error)
@@ -67,7 +84,7 @@ type Server interface {
 // RegionSyncer is used to sync the region information without raft.
 type RegionSyncer struct {
 	sync.RWMutex
-	streams            map[string]ServerStream
+	streams            map[string]*regionStream
 	regionSyncerCtx    context.Context
 	regionSyncerCancel context.CancelFunc
 	server             Server
@@ -76,6 +93,12 @@ type RegionSyncer struct {
 	history            *historyBuffer
 	limit              *ratelimit.Bucket
 	securityConfig     *grpcutil.SecurityConfig
+	// lastSyncTime is the unix nano time when the follower is caught up with
+	// the leader last time, 0 means it is not syncing with the leader.
+	lastSyncTime int64
+	// changesLost is 1 if some changed regions are not notified to the
+	// syncer, the watchers are told to rescan their regions.
+	changesLost int32
 }
 
 // NewRegionSyncer returns a region syncer.
@@ -85,7 +108,7 @@ type RegionSyncer struct {
 // no longer etcd but go-leveldb.
 func NewRegionSyncer(s Server) *RegionSyncer {
 	return &RegionSyncer{
-		streams:        make(map[string]ServerStream),
+		streams:        make(map[string]*regionStream),
 		server:         s,
 		closed:         make(chan struct{}),
 		history:        newHistoryBuffer(defaultHistoryBufferSize, s.GetStorage().GetRegionStorage()),
@@ -97,8 +120,6 @@ func NewRegionSyncer(s Server) *RegionSyncer {
 // RunServer runs the server of the region syncer.
 // regionNotifier is used to get the changed regions.
 func (s *RegionSyncer) RunServer(regionNotifier <-chan *core.RegionInfo, quit chan struct{}) {
-	var requests []*metapb.Region
-	var stats []*pdpb.RegionStat
 	ticker := time.NewTicker(syncerKeepAliveInterval)
 	for {
 		select {
@@ -106,8 +127,10 @@ func (s *RegionSyncer) RunServer(regionNotifier <-chan *core.RegionInfo, quit ch
 			log.Info("region syncer has been stopped")
 			return
 		case first := <-regionNotifier:
-			requests = append(requests, first.GetMeta())
-			stats := append(stats, first.GetStat())
+			// the response is buffered by the streams, so a new batch is
+			// allocated each time.
+			requests := []*metapb.Region{first.GetMeta()}
+			stats := []*pdpb.RegionStat{first.GetStat()}
 			startIndex := s.history.GetNextIndex()
 			s.history.Record(first)
 			pending := len(regionNotifier)
@@ -131,35 +154,76 @@ func (s *RegionSyncer) RunServer(regionNotifier <-chan *core.RegionInfo, quit ch
 			}
 			s.broadcast(alive)
 		}
-		requests = requests[:0]
+		if atomic.CompareAndSwapInt32(&s.changesLost, 1, 0) {
+			s.broadcast(&pdpb.SyncRegionResponse{
+				Header:     &pdpb.ResponseHeader{ClusterId: s.server.ClusterID()},
+				StartIndex: ResyncIndex,
+			})
+		}
 	}
 }
 
+// MarkChangesLost marks that some changed regions are not notified to the
+// syncer, e.g. the notifier is full. The watchers are told to rescan their
+// regions, while the followers are fixed by the following heartbeats.
+func (s *RegionSyncer) MarkChangesLost() {
+	atomic.StoreInt32(&s.changesLost, 1)
+}
+
 // Sync firstly tries to sync the history records to client.
 // then to sync the latest records.
+// The client only sends one request in a stream, and the stream is closed if
+// the client is too slow to receive the latest records, then the client can
+// resume from its next index with a new stream.
 func (s *RegionSyncer) Sync(stream pdpb.PD_SyncRegionsServer) error {
-	for {
-		request, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		clusterID := request.GetHeader().GetClusterId()
-		if clusterID != s.server.ClusterID() {
-			return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.server.ClusterID(), clusterID)
-		}
-		log.Info("establish sync region stream",
-			zap.String("requested-server", request.GetMember().GetName()),
-			zap.String("url", request.GetMember().GetClientUrls()[0]))
+	request, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	clusterID := request.GetHeader().GetClusterId()
+	if clusterID != s.server.ClusterID() {
+		return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.server.ClusterID(), clusterID)
+	}
+	name := request.GetMember().GetName()
+	log.Info("establish sync region stream",
+		zap.String("requested-server", name),
+		zap.Strings("url", request.GetMember().GetClientUrls()))
 
-		err = s.syncHistoryRegion(request, stream)
-		if err != nil {
+	startKey, endKey, watch, err := grpcutil.GetWatchRange(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	var rs *regionStream
+	if watch {
+		// bind the watcher before sending the history, so no change is lost
+		// between them.
+		rs = newWatchStream(stream, startKey, endKey)
+		s.bindStream(name, rs)
+		defer s.unbindStream(name, rs)
+		if err := s.syncWatcherHistory(request, rs); err != nil {
 			return err
 		}
-		s.bindStream(request.GetMember().GetName(), stream)
+	} else {
+		if err := s.syncHistoryRegion(request, stream); err != nil {
+			return err
+		}
+		rs = newRegionStream(stream)
+		s.bindStream(name, rs)
+		defer s.unbindStream(name, rs)
 	}
+	go func() {
+		// the stream is done once the client closes it.
+		for {
+			if _, err := stream.Recv(); err != nil {
+				rs.close(nil)
+				return
+			}
+		}
+	}()
+	return rs.run()
 }
 
 func (s *RegionSyncer) syncHistoryRegion(request *pdpb.SyncRegionRequest, stream pdpb.PD_SyncRegionsServer) error {
@@ -172,6 +236,10 @@ func (s *RegionSyncer) syncHistoryRegion(request *pdpb.SyncRegionRequest, stream
 				zap.String("requested-server", name), zap.String("server", s.server.Name()), zap.Uint64("last-index", startIndex))
 			return nil
 		}
+		// the client only watches the latest records.
+		if startIndex == LatestIndex {
+			return nil
+		}
 		// do full synchronization
 		if startIndex == 0 {
 			regions := s.server.GetRegions()
@@ -226,20 +294,61 @@ func (s *RegionSyncer) syncHistoryRegion(request *pdpb.SyncRegionRequest, stream
 	return stream.Send(resp)
 }
 
+// syncWatcherHistory sends the history records in the range of the watcher.
+// If the records from its index are not kept, the watcher is told to rescan
+// its regions.
+func (s *RegionSyncer) syncWatcherHistory(request *pdpb.SyncRegionRequest, rs *regionStream) error {
+	startIndex := request.GetStartIndex()
+	if startIndex == LatestIndex || startIndex == s.history.GetNextIndex() {
+		return nil
+	}
+	resp := &pdpb.SyncRegionResponse{
+		Header:     &pdpb.ResponseHeader{ClusterId: s.server.ClusterID()},
+		StartIndex: ResyncIndex,
+	}
+	if records := s.history.RecordsFrom(startIndex); len(records) > 0 {
+		resp.StartIndex = startIndex
+		for _, r := range records {
+			resp.Regions = append(resp.Regions, r.GetMeta())
+			resp.RegionStats = append(resp.RegionStats, r.GetStat())
+		}
+		// only the next index is sent if no history region is in the range.
+		if resp = rs.filter(resp); resp == nil {
+			resp = &pdpb.SyncRegionResponse{
+				Header:     &pdpb.ResponseHeader{ClusterId: s.server.ClusterID()},
+				StartIndex: startIndex + uint64(len(records)),
+			}
+		}
+	}
+	return errors.WithStack(rs.stream.Send(resp))
+}
+
 // bindStream binds the established server stream.
-func (s *RegionSyncer) bindStream(name string, stream ServerStream) {
+func (s *RegionSyncer) bindStream(name string, stream *regionStream) {
 	s.Lock()
 	defer s.Unlock()
+	if old, ok := s.streams[name]; ok {
+		old.close(nil)
+	}
 	s.streams[name] = stream
 }
 
+// unbindStream unbinds the server stream if it is still bound with the name.
+func (s *RegionSyncer) unbindStream(name string, stream *regionStream) {
+	s.Lock()
+	defer s.Unlock()
+	if s.streams[name] == stream {
+		delete(s.streams, name)
+	}
+}
+
 func (s *RegionSyncer) broadcast(regions *pdpb.SyncRegionResponse) {
 	var failed []string
 	s.RLock()
 	for name, sender := range s.streams {
-		err := sender.Send(regions)
-		if err != nil {
-			log.Error("region syncer send data meet error", zap.Error(err))
+		if !sender.send(regions) {
+			log.Warn("region syncer stream is too slow, close it", zap.String("stream", name))
+			sender.close(errStreamTooSlow)
 			failed = append(failed, name)
 		}
 	}
@@ -253,3 +362,105 @@ func (s *RegionSyncer) broadcast(regions *pdpb.SyncRegionResponse) {
 		s.Unlock()
 	}
 }
+
+// regionStream sends the latest records to a bound stream. The records are
+// buffered so that a slow stream does not block the others.
+type regionStream struct {
+	stream    ServerStream
+	responses chan *pdpb.SyncRegionResponse
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+	// watch is true if the stream is sent by a watcher, only the regions in
+	// [startKey, endKey) are sent to it.
+	watch            bool
+	startKey, endKey []byte
+}
+
+func newRegionStream(stream ServerStream) *regionStream {
+	return &regionStream{
+		stream:    stream,
+		responses: make(chan *pdpb.SyncRegionResponse, streamBufferSize),
+		done:      make(chan struct{}),
+	}
+}
+
+func newWatchStream(stream ServerStream, startKey, endKey []byte) *regionStream {
+	r := newRegionStream(stream)
+	r.watch, r.startKey, r.endKey = true, startKey, endKey
+	return r
+}
+
+// filter returns the response sent to a watcher, it returns nil if no region
+// of the response is in the range. The regions out of the range are removed
+// and the start index is moved forward, so the start index plus the count of
+// the regions is still the next index.
+func (r *regionStream) filter(resp *pdpb.SyncRegionResponse) *pdpb.SyncRegionResponse {
+	regions := resp.GetRegions()
+	if !r.watch || len(regions) == 0 {
+		return resp
+	}
+	stats := resp.GetRegionStats()
+	hasStats := len(stats) == len(regions)
+	filtered := &pdpb.SyncRegionResponse{Header: resp.GetHeader()}
+	for i, region := range regions {
+		if !r.inRange(region) {
+			continue
+		}
+		filtered.Regions = append(filtered.Regions, region)
+		if hasStats {
+			filtered.RegionStats = append(filtered.RegionStats, stats[i])
+		}
+	}
+	if len(filtered.Regions) == 0 {
+		return nil
+	}
+	filtered.StartIndex = resp.GetStartIndex() + uint64(len(regions)-len(filtered.Regions))
+	return filtered
+}
+
+func (r *regionStream) inRange(region *metapb.Region) bool {
+	return (len(r.endKey) == 0 || bytes.Compare(region.GetStartKey(), r.endKey) < 0) &&
+		(len(region.GetEndKey()) == 0 || bytes.Compare(region.GetEndKey(), r.startKey) > 0)
+}
+
+// send buffers the response, it returns false if the buffer is full. The
+// resync responses are only sent to the watchers.
+func (r *regionStream) send(resp *pdpb.SyncRegionResponse) bool {
+	if resp.GetStartIndex() == ResyncIndex && !r.watch {
+		return true
+	}
+	if resp = r.filter(resp); resp == nil {
+		return true
+	}
+	select {
+	case r.responses <- resp:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the stream, err is returned by run.
+func (r *regionStream) close(err error) {
+	r.closeOnce.Do(func() {
+		r.err = err
+		close(r.done)
+	})
+}
+
+// run sends the buffered responses until the stream is closed.
+func (r *regionStream) run() error {
+	for {
+		select {
+		case resp := <-r.responses:
+			if err := r.stream.Send(resp); err != nil {
+				log.Error("region syncer send data meet error", zap.Error(err))
+				r.close(err)
+				return errors.WithStack(err)
+			}
+		case <-r.done:
+			return r.err
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+)
+
+var _ = Suite(&testRegionStreamSuite{})
+
+type testRegionStreamSuite struct{}
+
+type mockServerStream struct {
+	responses chan *pdpb.SyncRegionResponse
+}
+
+func (s *mockServerStream) Send(resp *pdpb.SyncRegionResponse) error {
+	select {
+	case s.responses <- resp:
+	default:
+	}
+	return nil
+}
+
+func (s *testRegionStreamSuite) TestRegionStream(c *C) {
+	syncer := &RegionSyncer{streams: make(map[string]*regionStream)}
+	stream := &mockServerStream{responses: make(chan *pdpb.SyncRegionResponse, 1)}
+	rs := newRegionStream(stream)
+	syncer.bindStream("s", rs)
+	errCh := make(chan error, 1)
+	go func() { errCh <- rs.run() }()
+	syncer.broadcast(&pdpb.SyncRegionResponse{StartIndex: 1})
+	c.Assert((<-stream.responses).GetStartIndex(), Equals, uint64(1))
+
+	rs.close(nil)
+	c.Assert(<-errCh, IsNil)
+	syncer.unbindStream("s", rs)
+	c.Assert(syncer.streams, HasLen, 0)
+}
+
+func (s *testRegionStreamSuite) TestSlowStream(c *C) {
+	syncer := &RegionSyncer{streams: make(map[string]*regionStream)}
+	// the stream does not run, so the responses are not sent.
+	slow := newRegionStream(&mockServerStream{})
+	syncer.bindStream("slow", slow)
+	for i := 0; i < streamBufferSize; i++ {
+		syncer.broadcast(&pdpb.SyncRegionResponse{StartIndex: uint64(i)})
+	}
+	c.Assert(syncer.streams, HasLen, 1)
+	syncer.broadcast(&pdpb.SyncRegionResponse{StartIndex: streamBufferSize})
+	c.Assert(syncer.streams, HasLen, 0)
+	c.Assert(slow.run(), Equals, errStreamTooSlow)
+
+	// the replaced stream is closed.
+	old, rs := newRegionStream(&mockServerStream{}), newRegionStream(&mockServerStream{})
+	syncer.bindStream("s", old)
+	syncer.bindStream("s", rs)
+	c.Assert(old.run(), IsNil)
+	syncer.unbindStream("s", old)
+	c.Assert(syncer.streams["s"], Equals, rs)
+}
+
+func (s *testRegionStreamSuite) TestWatchStream(c *C) {
+	syncer := &RegionSyncer{streams: make(map[string]*regionStream)}
+	follower := newRegionStream(&mockServerStream{})
+	watcher := newWatchStream(&mockServerStream{}, []byte("b"), []byte("d"))
+	syncer.bindStream("follower", follower)
+	syncer.bindStream("watcher", watcher)
+
+	regions := []*metapb.Region{
+		{Id: 1, StartKey: []byte(""), EndKey: []byte("a")},
+		{Id: 2, StartKey: []byte("a"), EndKey: []byte("c")},
+		{Id: 3, StartKey: []byte("c"), EndKey: []byte("d")},
+		{Id: 4, StartKey: []byte("d"), EndKey: []byte("")},
+	}
+	syncer.broadcast(&pdpb.SyncRegionResponse{StartIndex: 10, Regions: regions})
+	// the regions out of the range are not sent to the watcher.
+	syncer.broadcast(&pdpb.SyncRegionResponse{StartIndex: 14, Regions: regions[3:]})
+	syncer.broadcast(&pdpb.SyncRegionResponse{StartIndex: ResyncIndex})
+	c.Assert(follower.responses, HasLen, 2)
+	c.Assert(watcher.responses, HasLen, 2)
+
+	resp := <-watcher.responses
+	c.Assert(resp.GetRegions(), DeepEquals, regions[1:3])
+	// the start index plus the count of the regions is the next index.
+	c.Assert(resp.GetStartIndex(), Equals, uint64(12))
+	c.Assert((<-watcher.responses).GetStartIndex(), Equals, uint64(ResyncIndex))
+	c.Assert((<-follower.responses).GetRegions(), HasLen, 4)
+}
@@ -23,8 +23,17 @@ var (
 			Name:      "event_count",
 			Help:      "Counter of checker events.",
 		}, []string{"type", "name"})
+
+	loadSplitCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "checker",
+			Name:      "load_split_count",
+			Help:      "Counter of the split operators created by load.",
+		}, []string{"flow", "policy"})
 )
 
 func init() {
 	prometheus.MustRegister(checkerCounter)
+	prometheus.MustRegister(loadSplitCounter)
 }
@@ -107,6 +107,10 @@ func (c *RuleChecker) fixRulePeer(region *core.RegionInfo, fit *placement.Region
 			return op, nil
 		}
 	}
+	op, err := c.fixLeaderPreference(region, fit, rf)
+	if err != nil || op != nil {
+		return op, err
+	}
 	return c.fixBetterLocation(region, fit, rf)
 }
 
@@ -136,7 +140,7 @@ func (c *RuleChecker) fixLooseMatchPeer(region *core.RegionInfo, fit *placement.
 		checkerCounter.WithLabelValues("rule_checker", "fix-peer-role").Inc()
 		return operator.CreatePromoteLearnerOperator("fix-peer-role", c.cluster, region, peer)
 	}
-	if region.GetLeader().GetId() == peer.GetId() && rf.Rule.Role == placement.Follower {
+	if region.GetLeader().GetId() == peer.GetId() && (rf.Rule.Role == placement.Follower || rf.Rule.Role == placement.Witness) {
 		checkerCounter.WithLabelValues("rule_checker", "fix-leader-role").Inc()
 		for _, p := range region.GetPeers() {
 			if c.allowLeader(fit, p) {
@@ -170,6 +174,37 @@ func (c *RuleChecker) allowLeader(fit *placement.RegionFit, peer *metapb.Peer) b
 	return false
 }
 
+// fixLeaderPreference transfers the leader to a more preferred store if the
+// leader belongs to the rule and the rule has leader preferences.
+func (c *RuleChecker) fixLeaderPreference(region *core.RegionInfo, fit *placement.RegionFit, rf *placement.RuleFit) (*operator.Operator, error) {
+	if len(rf.Rule.LeaderPreferences) == 0 {
+		return nil, nil
+	}
+	leader := region.GetLeader()
+	if fit.GetRuleFit(leader.GetId()) != rf {
+		return nil, nil
+	}
+	bestScore := placement.PreferenceScore(c.cluster.GetStore(leader.GetStoreId()), rf.Rule.LeaderPreferences)
+	var target *metapb.Peer
+	for _, p := range region.GetPeers() {
+		if p.GetId() == leader.GetId() || !c.allowLeader(fit, p) {
+			continue
+		}
+		s := c.cluster.GetStore(p.GetStoreId())
+		if !placement.MatchLabelConstraints(s, rf.Rule.LabelConstraints) {
+			continue
+		}
+		if score := placement.PreferenceScore(s, rf.Rule.LeaderPreferences); score > bestScore {
+			bestScore, target = score, p
+		}
+	}
+	if target == nil {
+		return nil, nil
+	}
+	checkerCounter.WithLabelValues("rule_checker", "fix-leader-preference").Inc()
+	return operator.CreateTransferLeaderOperator("fix-leader-preference", c.cluster, region, leader.GetStoreId(), target.GetStoreId(), 0)
+}
+
 func (c *RuleChecker) fixBetterLocation(region *core.RegionInfo, fit *placement.RegionFit, rf *placement.RuleFit) (*operator.Operator, error) {
 	if len(rf.Rule.LocationLabels) == 0 || rf.Rule.Count <= 1 {
 		return nil, nil
@@ -250,9 +285,10 @@ func SelectStoreToAddPeerByRule(scope string, cluster opt.Cluster, region *core.
 	fs := []filter.Filter{
 		filter.StoreStateFilter{ActionScope: scope, MoveRegion: true},
 		filter.NewStorageThresholdFilter(scope),
-		filter.NewLabelConstaintFilter(scope, rf.Rule.LabelConstraints),
+		filter.NewLabelConstaintFilter(scope, rf.Rule.GetLabelConstraints()),
 		filter.NewExcludedFilter(scope, nil, region.GetStoreIds()),
 		filter.NewSpecialUseFilter(scope),
+		newWitnessFilter(scope, rf.Rule.Role == placement.Witness),
 	}
 	fs = append(fs, filters...)
 	store := selector.NewReplicaSelector(getRuleFitStores(cluster, rf), rf.Rule.LocationLabels).
@@ -266,6 +302,15 @@ func SelectStoreToReplacePeerByRule(scope string, cluster opt.Cluster, region *c
 	return SelectStoreToAddPeerByRule(scope, cluster, region, rf2, filters...)
 }
 
+// newWitnessFilter creates a filter that only keeps witness stores for witness
+// peers, and only keeps other stores for other peers.
+func newWitnessFilter(scope string, isWitness bool) filter.Filter {
+	if isWitness {
+		return filter.NewEngineFilter(scope, filter.EngineWitness)
+	}
+	return filter.NewLabelConstaintFilter(scope, []placement.LabelConstraint{{Key: filter.EngineKey, Op: "notIn", Values: []string{filter.EngineWitness}}})
+}
+
 func getRuleFitStores(cluster opt.Cluster, fit *placement.RuleFit) []*core.StoreInfo {
 	var stores []*core.StoreInfo
 	for _, p := range fit.Peers {
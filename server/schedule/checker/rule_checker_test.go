@@ -179,6 +179,81 @@ func (s *testRuleCheckerSuite) TestFixRoleLeader(c *C) {
 	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Equals, uint64(3))
 }
 
+func (s *testRuleCheckerSuite) TestFixWitness(c *C) {
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderStore(3, 1)
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"engine": "witness"})
+	s.cluster.AddLeaderStore(5, 1)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID: "pd",
+		ID:      "witness",
+		Index:   100,
+		Role:    placement.Witness,
+		Count:   1,
+	})
+
+	// add the missing witness to the witness store.
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2, 3)
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-peer")
+	c.Assert(op.Len(), Equals, 2)
+	c.Assert(op.Step(0).(operator.AddWitness).ToStore, Equals, uint64(4))
+	c.Assert(op.Step(1).(operator.PromoteWitness).ToStore, Equals, uint64(4))
+
+	// promote the witness learner.
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2, 3, 4)
+	r := s.cluster.GetRegion(1)
+	p := r.GetStorePeer(4)
+	p.IsLearner = true
+	r = r.Clone(core.WithLearners([]*metapb.Peer{p}))
+	op = s.rc.Check(r)
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "fix-peer-role")
+	c.Assert(op.Step(0).(operator.PromoteWitness).ToStore, Equals, uint64(4))
+
+	// witness should never be the leader.
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 4, 1, 2, 3)
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "fix-peer-role")
+	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Not(Equals), uint64(4))
+}
+
+func (s *testRuleCheckerSuite) TestFixLeaderPreference(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z3"})
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2, 3)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID: "pd",
+		ID:      "default",
+		Role:    placement.Voter,
+		Count:   3,
+		LeaderPreferences: []placement.LabelPreference{
+			{Key: "zone", Values: []string{"z3", "z2"}},
+		},
+	})
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "fix-leader-preference")
+	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Equals, uint64(3))
+
+	// the most preferred store is not available, fall back to the next one.
+	s.cluster.SetStoreBusy(3, true)
+	s.cluster.AddLeaderRegionWithRange(2, "", "", 1, 2, 3)
+	op = s.rc.Check(s.cluster.GetRegion(2))
+	c.Assert(op, NotNil)
+	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Equals, uint64(2))
+
+	// the leader is on the most preferred store.
+	s.cluster.SetStoreBusy(3, false)
+	s.cluster.AddLeaderRegionWithRange(3, "", "", 3, 1, 2)
+	op = s.rc.Check(s.cluster.GetRegion(3))
+	c.Assert(op, IsNil)
+}
+
 func (s *testRuleCheckerSuite) TestBetterReplacement(c *C) {
 	s.cluster.AddLabelsStore(1, 1, map[string]string{"host": "host1"})
 	s.cluster.AddLabelsStore(2, 1, map[string]string{"host": "host1"})
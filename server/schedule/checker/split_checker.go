@@ -0,0 +1,115 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/cache"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/statistics"
+	"go.uber.org/zap"
+)
+
+const (
+	// splitMinHotDegree is the hot degree a region needs to be split, so
+	// that only regions staying hot for several reporting intervals are split.
+	splitMinHotDegree = 10
+	// splitCacheTTL is the time to skip a region after a split is issued,
+	// which gives the hot cache time to see the new regions.
+	splitCacheTTL = 10 * time.Minute
+)
+
+// SplitChecker splits the regions which stay hot alone on a store. Such a
+// region cannot be balanced by moving it, it only moves the hotspot.
+type SplitChecker struct {
+	cluster    opt.Cluster
+	splitCache *cache.TTLUint64
+}
+
+// NewSplitChecker creates a split checker.
+func NewSplitChecker(ctx context.Context, cluster opt.Cluster) *SplitChecker {
+	return &SplitChecker{
+		cluster:    cluster,
+		splitCache: cache.NewIDTTL(ctx, time.Minute, splitCacheTTL),
+	}
+}
+
+// Check verifies if a region is needed to be split by load, creating an
+// Operator if need.
+func (s *SplitChecker) Check(region *core.RegionInfo) *operator.Operator {
+	checkerCounter.WithLabelValues("split_checker", "check").Inc()
+
+	if !s.cluster.IsRegionHot(region) {
+		checkerCounter.WithLabelValues("split_checker", "no-need").Inc()
+		return nil
+	}
+
+	if s.splitCache.Exists(region.GetID()) {
+		checkerCounter.WithLabelValues("split_checker", "recently-split").Inc()
+		return nil
+	}
+
+	// skip region has down peers or pending peers or learner peers
+	if !opt.IsRegionHealthy(s.cluster, region) {
+		checkerCounter.WithLabelValues("split_checker", "special-peer").Inc()
+		return nil
+	}
+
+	var flow string
+	switch {
+	case s.isAloneHot(region, s.cluster.RegionWriteStats()):
+		flow = "write"
+	case s.isAloneHot(region, s.cluster.RegionReadStats()):
+		flow = "read"
+	default:
+		checkerCounter.WithLabelValues("split_checker", "not-alone").Inc()
+		return nil
+	}
+
+	policy := s.cluster.GetHotRegionSplitPolicy()
+	log.Debug("try to split hot region", zap.Uint64("region-id", region.GetID()), zap.String("flow", flow), zap.Stringer("policy", policy))
+	op := operator.CreateSplitRegionOperator("hot-split-region", region, operator.OpHotRegion, policy, nil)
+	s.splitCache.Put(region.GetID())
+	checkerCounter.WithLabelValues("split_checker", "new-operator").Inc()
+	loadSplitCounter.WithLabelValues(flow, policy.String()).Inc()
+	return op
+}
+
+// isAloneHot checks if the region stays hot on a store, and it is the only
+// hot region of the store.
+func (s *SplitChecker) isAloneHot(region *core.RegionInfo, stats map[uint64][]*statistics.HotPeerStat) bool {
+	minHotDegree := s.cluster.GetHotRegionCacheHitsThreshold()
+	for _, peer := range region.GetPeers() {
+		var isHot bool
+		hotCount := 0
+		for _, stat := range stats[peer.GetStoreId()] {
+			if stat.HotDegree < minHotDegree {
+				continue
+			}
+			hotCount++
+			if stat.RegionID == region.GetID() && stat.HotDegree >= splitMinHotDegree {
+				isHot = true
+			}
+		}
+		if isHot && hotCount == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"context"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/statistics"
+)
+
+var _ = Suite(&testSplitCheckerSuite{})
+
+type testSplitCheckerSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testSplitCheckerSuite) SetUpTest(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *testSplitCheckerSuite) TearDownTest(c *C) {
+	s.cancel()
+}
+
+func (s *testSplitCheckerSuite) TestSplitHotRegion(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.HotRegionSplitPolicy = pdpb.CheckPolicy_SCAN
+	tc := mockcluster.NewCluster(opt)
+	tc.AddRegionStore(1, 1)
+	tc.AddRegionStore(2, 1)
+	tc.AddRegionStore(3, 1)
+	sc := NewSplitChecker(s.ctx, tc)
+
+	writeRegion := func(regionID uint64, rounds int) {
+		for i := 0; i < rounds; i++ {
+			tc.AddLeaderRegionWithWriteInfo(regionID, 1, 10*1024*1024*statistics.RegionHeartBeatReportInterval, 0,
+				statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+		}
+	}
+
+	// not hot for enough rounds
+	writeRegion(1, splitMinHotDegree)
+	c.Assert(sc.Check(tc.GetRegion(1)), IsNil)
+
+	// the only hot region of the stores
+	writeRegion(1, 1)
+	op := sc.Check(tc.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Kind(), Equals, operator.OpHotRegion|operator.OpSplit)
+	c.Assert(op.Step(0).(operator.SplitRegion).Policy, Equals, pdpb.CheckPolicy_SCAN)
+	// skip the region recently split
+	c.Assert(sc.Check(tc.GetRegion(1)), IsNil)
+
+	// the stores have another hot region, it can be balanced by moving.
+	writeRegion(2, splitMinHotDegree+1)
+	c.Assert(sc.Check(tc.GetRegion(2)), IsNil)
+}
@@ -31,6 +31,7 @@ type CheckerController struct {
 	replicaChecker *checker.ReplicaChecker
 	ruleChecker    *checker.RuleChecker
 	mergeChecker   *checker.MergeChecker
+	splitChecker   *checker.SplitChecker
 }
 
 // NewCheckerController create a new CheckerController.
@@ -43,6 +44,7 @@ func NewCheckerController(ctx context.Context, cluster opt.Cluster, ruleManager
 		replicaChecker: checker.NewReplicaChecker(cluster),
 		ruleChecker:    checker.NewRuleChecker(cluster, ruleManager),
 		mergeChecker:   checker.NewMergeChecker(ctx, cluster),
+		splitChecker:   checker.NewSplitChecker(ctx, cluster),
 	}
 }
 
@@ -78,6 +80,14 @@ func (c *CheckerController) CheckRegion(region *core.RegionInfo) (bool, []*opera
 			return checkerIsBusy, ops
 		}
 	}
+
+	if c.splitChecker != nil && c.cluster.IsHotRegionSplitEnabled() &&
+		opController.OperatorCount(operator.OpSplit) < c.cluster.GetHotRegionScheduleLimit() {
+		checkerIsBusy = false
+		if op := c.splitChecker.Check(region); op != nil {
+			return checkerIsBusy, []*operator.Operator{op}
+		}
+	}
 	return checkerIsBusy, nil
 }
 
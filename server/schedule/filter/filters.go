@@ -138,7 +138,7 @@ func (f *storeLimitFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
 }
 
 func (f *storeLimitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
-	return store.IsAvailable(storelimit.AddPeer)
+	return store.IsAvailable(storelimit.AddPeer) && store.IsAvailable(storelimit.ReceiveSnapshot)
 }
 
 type stateFilter struct{ scope string }
@@ -399,6 +399,7 @@ func (f StoreStateFilter) Target(opts opt.Options, store *core.StoreInfo) bool {
 		(store.IsDisconnected() ||
 			store.IsBlocked() ||
 			store.IsBusy() ||
+			store.IsWitness() ||
 			opts.CheckLabelProperty(opt.RejectLeader, store.GetLabels())) {
 		return false
 	}
@@ -421,7 +422,8 @@ func (f StoreStateFilter) filterMoveRegion(opt opt.Options, isSource bool, store
 		return false
 	}
 
-	if (isSource && !store.IsAvailable(storelimit.RemovePeer)) || (!isSource && !store.IsAvailable(storelimit.AddPeer)) {
+	if (isSource && !store.IsAvailable(storelimit.RemovePeer)) ||
+		(!isSource && (!store.IsAvailable(storelimit.AddPeer) || !store.IsAvailable(storelimit.ReceiveSnapshot))) {
 		return false
 	}
 
@@ -508,6 +510,40 @@ func (f *ruleFitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
 	return placement.CompareRegionFit(f.oldFit, newFit) <= 0
 }
 
+type leaderPreferenceFilter struct {
+	scope       string
+	preferences []placement.LabelPreference
+	leaderScore int
+}
+
+// NewLeaderPreferenceFilter creates a filter that rejects target stores which
+// are less preferred to hold the leader than the current leader store,
+// according to the leader preferences of the rule the leader belongs to.
+func NewLeaderPreferenceFilter(scope string, fitter RegionFitter, region *core.RegionInfo, leaderStore *core.StoreInfo) Filter {
+	preferences := fitter.FitRegion(region).GetLeaderPreferences(region.GetLeader())
+	return &leaderPreferenceFilter{
+		scope:       scope,
+		preferences: preferences,
+		leaderScore: placement.PreferenceScore(leaderStore, preferences),
+	}
+}
+
+func (f *leaderPreferenceFilter) Scope() string {
+	return f.scope
+}
+
+func (f *leaderPreferenceFilter) Type() string {
+	return "leader-preference-filter"
+}
+
+func (f *leaderPreferenceFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
+	return true
+}
+
+func (f *leaderPreferenceFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
+	return placement.PreferenceScore(store, f.preferences) >= f.leaderScore
+}
+
 type engineFilter struct {
 	scope      string
 	constraint placement.LabelConstraint
@@ -618,7 +654,10 @@ const (
 	EngineKey = "engine"
 	// EngineTiFlash is the tiflash value of the engine label.
 	EngineTiFlash = "tiflash"
+	// EngineWitness is the witness value of the engine label. Only witness
+	// peers are placed on witness stores.
+	EngineWitness = "witness"
 )
 
 var allSpecialUses = []string{SpecialUseHotRegion, SpecialUseReserved}
-var allSpeicalEngines = []string{EngineTiFlash}
+var allSpeicalEngines = []string{EngineTiFlash, EngineWitness}
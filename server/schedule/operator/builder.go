@@ -311,19 +311,30 @@ func (b *Builder) execTransferLeader(id uint64) {
 }
 
 func (b *Builder) execPromoteLearner(p *metapb.Peer) {
-	b.steps = append(b.steps, PromoteLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+	if b.isWitness(p.GetStoreId()) {
+		b.steps = append(b.steps, PromoteWitness{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+	} else {
+		b.steps = append(b.steps, PromoteLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+	}
 	b.currentPeers.Set(&metapb.Peer{Id: p.GetId(), StoreId: p.GetStoreId()})
 	b.toPromote.Delete(p.GetStoreId())
 }
 
 func (b *Builder) execAddPeer(p *metapb.Peer) {
-	if b.isLigthWeight {
-		b.steps = append(b.steps, AddLightLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+	if b.isWitness(p.GetStoreId()) {
+		b.steps = append(b.steps, AddWitness{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+		if !p.GetIsLearner() {
+			b.steps = append(b.steps, PromoteWitness{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+		}
 	} else {
-		b.steps = append(b.steps, AddLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
-	}
-	if !p.GetIsLearner() {
-		b.steps = append(b.steps, PromoteLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+		if b.isLigthWeight {
+			b.steps = append(b.steps, AddLightLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+		} else {
+			b.steps = append(b.steps, AddLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+		}
+		if !p.GetIsLearner() {
+			b.steps = append(b.steps, PromoteLearner{ToStore: p.GetStoreId(), PeerID: p.GetId()})
+		}
 	}
 	b.currentPeers.Set(p)
 	if b.peerAddStep == nil {
@@ -339,6 +350,11 @@ func (b *Builder) execRemovePeer(p *metapb.Peer) {
 	b.toRemove.Delete(p.GetStoreId())
 }
 
+func (b *Builder) isWitness(storeID uint64) bool {
+	store := b.cluster.GetStore(storeID)
+	return store != nil && store.IsWitness()
+}
+
 // check if a peer can become leader.
 func (b *Builder) allowLeader(peer *metapb.Peer) bool {
 	if peer.GetStoreId() == b.currentLeader {
@@ -43,6 +43,7 @@ func (s *testBuilderSuite) SetUpTest(c *C) {
 	s.cluster.AddLabelsStore(8, 0, map[string]string{"zone": "z2", "host": "h1"})
 	s.cluster.AddLabelsStore(9, 0, map[string]string{"zone": "z2", "host": "h2"})
 	s.cluster.AddLabelsStore(10, 0, map[string]string{"zone": "z3", "host": "h1", "noleader": "true"})
+	s.cluster.AddLabelsStore(11, 0, map[string]string{"zone": "z3", "host": "h2", "engine": "witness"})
 }
 
 func (s *testBuilderSuite) TestNewBuilder(c *C) {
@@ -217,6 +218,14 @@ func (s *testBuilderSuite) TestBuild(c *C) {
 				RemovePeer{FromStore: 1},
 			},
 		},
+		{ // demote witness voter by replacing it with a learner
+			[]*metapb.Peer{{Id: 1, StoreId: 1}, {Id: 2, StoreId: 2}, {Id: 3, StoreId: 11}},
+			[]*metapb.Peer{{StoreId: 1}, {StoreId: 2}, {StoreId: 11, IsLearner: true}},
+			[]OpStep{
+				RemovePeer{FromStore: 11},
+				AddWitness{ToStore: 11},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -251,6 +260,8 @@ func (s *testBuilderSuite) TestBuild(c *C) {
 				c.Assert(step.(AddLightLearner).ToStore, Equals, tc.steps[i].(AddLightLearner).ToStore)
 			case PromoteLearner:
 				c.Assert(step.(PromoteLearner).ToStore, Equals, tc.steps[i].(PromoteLearner).ToStore)
+			case AddWitness:
+				c.Assert(step.(AddWitness).ToStore, Equals, tc.steps[i].(AddWitness).ToStore)
 			}
 		}
 	}
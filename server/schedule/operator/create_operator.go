@@ -147,17 +147,20 @@ func isRegionMatch(a, b *core.RegionInfo) bool {
 }
 
 // CreateScatterRegionOperator creates an operator that scatters the specified region.
-func CreateScatterRegionOperator(desc string, cluster Cluster, origin *core.RegionInfo, targetPeers map[uint64]*metapb.Peer) (*Operator, error) {
-	// randomly pick a leader.
-	var ids []uint64
-	for id, peer := range targetPeers {
-		if !peer.IsLearner {
-			ids = append(ids, id)
+// The leader is picked randomly if targetLeader is 0.
+func CreateScatterRegionOperator(desc string, cluster Cluster, origin *core.RegionInfo, targetPeers map[uint64]*metapb.Peer, targetLeader uint64) (*Operator, error) {
+	leader := targetLeader
+	if leader == 0 {
+		// randomly pick a leader.
+		var ids []uint64
+		for id, peer := range targetPeers {
+			if !peer.IsLearner {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			leader = ids[rand.Intn(len(ids))]
 		}
-	}
-	var leader uint64
-	if len(ids) > 0 {
-		leader = ids[rand.Intn(len(ids))]
 	}
 	return NewBuilder(desc, cluster, origin).
 		SetPeers(targetPeers).
@@ -40,6 +40,9 @@ type StoreInfluence struct {
 	LeaderSize  int64
 	LeaderCount int64
 	StepCost    map[storelimit.Type]int64
+	// StepSize is the total size of the regions of the steps that cost the
+	// store limit, which is used by the size aware limit algorithms.
+	StepSize map[storelimit.Type]int64
 }
 
 // ResourceProperty returns delta size of leader/region by influence.
@@ -76,11 +79,39 @@ func (s *StoreInfluence) addStepCost(limitType storelimit.Type, cost int64) {
 	s.StepCost[limitType] += cost
 }
 
+// GetStepSize returns the specific type step size
+func (s StoreInfluence) GetStepSize(limitType storelimit.Type) int64 {
+	if s.StepSize == nil {
+		return 0
+	}
+	return s.StepSize[limitType]
+}
+
+func (s *StoreInfluence) addStepSize(limitType storelimit.Type, size int64) {
+	if s.StepSize == nil {
+		s.StepSize = make(map[storelimit.Type]int64)
+	}
+	s.StepSize[limitType] += size
+}
+
+// AdjustSnapshotCost adjusts the step cost of snapshot store limits when a peer
+// of the region is added to the store. The snapshot is generated and sent by
+// the leader store of the region.
+func (m OpInfluence) AdjustSnapshotCost(region *core.RegionInfo, toStore uint64) {
+	regionSize := region.GetApproximateSize()
+	m.GetStoreInfluence(toStore).AdjustStepCost(storelimit.ReceiveSnapshot, regionSize)
+	if leader := region.GetLeader(); leader != nil {
+		m.GetStoreInfluence(leader.GetStoreId()).AdjustStepCost(storelimit.SendSnapshot, regionSize)
+	}
+}
+
 // AdjustStepCost adjusts the step cost of specific type store limit according to region size
 func (s *StoreInfluence) AdjustStepCost(limitType storelimit.Type, regionSize int64) {
 	if regionSize > storelimit.SmallRegionThreshold {
 		s.addStepCost(limitType, storelimit.RegionInfluence[limitType])
+		s.addStepSize(limitType, regionSize)
 	} else if regionSize <= storelimit.SmallRegionThreshold && regionSize > core.EmptyRegionApproximateSize {
 		s.addStepCost(limitType, storelimit.SmallRegionInfluence[limitType])
+		s.addStepSize(limitType, regionSize)
 	}
 }
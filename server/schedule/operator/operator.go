@@ -326,6 +326,8 @@ func (o *Operator) History() []OpHistory {
 			addPeerStores = append(addPeerStores, s.ToStore)
 		case AddLightLearner:
 			addPeerStores = append(addPeerStores, s.ToStore)
+		case AddWitness:
+			addPeerStores = append(addPeerStores, s.ToStore)
 		case RemovePeer:
 			removePeerStores = append(removePeerStores, s.FromStore)
 		}
@@ -150,13 +150,19 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 	storeOpInfluence[1] = &StoreInfluence{}
 	storeOpInfluence[2] = &StoreInfluence{}
 
+	// The leader store 1 sends the snapshot and store 2 receives it.
 	AddPeer{ToStore: 2, PeerID: 2}.Influence(opInfluence, region)
+	c.Assert(*storeOpInfluence[1], DeepEquals, StoreInfluence{
+		StepCost: map[storelimit.Type]int64{storelimit.SendSnapshot: 1000},
+		StepSize: map[storelimit.Type]int64{storelimit.SendSnapshot: 50},
+	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  0,
 		LeaderCount: 0,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000, storelimit.ReceiveSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.AddPeer: 50, storelimit.ReceiveSnapshot: 50},
 	})
 
 	TransferLeader{FromStore: 1, ToStore: 2}.Influence(opInfluence, region)
@@ -165,14 +171,16 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -1,
 		RegionSize:  0,
 		RegionCount: 0,
-		StepCost:    nil,
+		StepCost:    map[storelimit.Type]int64{storelimit.SendSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.SendSnapshot: 50},
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000, storelimit.ReceiveSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.AddPeer: 50, storelimit.ReceiveSnapshot: 50},
 	})
 
 	RemovePeer{FromStore: 1}.Influence(opInfluence, region)
@@ -181,14 +189,16 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -1,
 		RegionSize:  -50,
 		RegionCount: -1,
-		StepCost:    map[storelimit.Type]int64{storelimit.RemovePeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.RemovePeer: 1000, storelimit.SendSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.RemovePeer: 50, storelimit.SendSnapshot: 50},
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000, storelimit.ReceiveSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.AddPeer: 50, storelimit.ReceiveSnapshot: 50},
 	})
 
 	MergeRegion{IsPassive: false}.Influence(opInfluence, region)
@@ -197,14 +207,16 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -1,
 		RegionSize:  -50,
 		RegionCount: -1,
-		StepCost:    map[storelimit.Type]int64{storelimit.RemovePeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.RemovePeer: 1000, storelimit.SendSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.RemovePeer: 50, storelimit.SendSnapshot: 50},
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000, storelimit.ReceiveSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.AddPeer: 50, storelimit.ReceiveSnapshot: 50},
 	})
 
 	MergeRegion{IsPassive: true}.Influence(opInfluence, region)
@@ -213,14 +225,16 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -2,
 		RegionSize:  -50,
 		RegionCount: -2,
-		StepCost:    map[storelimit.Type]int64{storelimit.RemovePeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.RemovePeer: 1000, storelimit.SendSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.RemovePeer: 50, storelimit.SendSnapshot: 50},
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 0,
-		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000},
+		StepCost:    map[storelimit.Type]int64{storelimit.AddPeer: 1000, storelimit.ReceiveSnapshot: 1000},
+		StepSize:    map[storelimit.Type]int64{storelimit.AddPeer: 50, storelimit.ReceiveSnapshot: 50},
 	})
 }
 
@@ -0,0 +1,118 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+)
+
+// OpRecord is the persisted form of a finished operator.
+type OpRecord struct {
+	RegionID uint64 `json:"region_id"`
+	// Desc is the name of the scheduler or checker that created the operator.
+	Desc       string            `json:"desc"`
+	Brief      string            `json:"brief"`
+	Kind       string            `json:"kind"`
+	Steps      []string          `json:"steps"`
+	Stores     []uint64          `json:"stores"`
+	Status     string            `json:"status"`
+	CreateTime time.Time         `json:"create_time"`
+	StartTime  time.Time         `json:"start_time"`
+	FinishTime time.Time         `json:"finish_time"`
+	WaitTime   typeutil.Duration `json:"wait_time"`
+	RunTime    typeutil.Duration `json:"run_time"`
+}
+
+// Record returns the record of an ended operator.
+func (o *Operator) Record(finishTime time.Time) *OpRecord {
+	steps := make([]string, 0, len(o.steps))
+	for _, step := range o.steps {
+		steps = append(steps, step.String())
+	}
+	record := &OpRecord{
+		RegionID:   o.regionID,
+		Desc:       o.desc,
+		Brief:      o.brief,
+		Kind:       o.kind.String(),
+		Steps:      steps,
+		Stores:     o.relatedStores(),
+		Status:     OpStatusToString(o.Status()),
+		CreateTime: o.GetCreateTime(),
+		StartTime:  o.GetStartTime(),
+		FinishTime: finishTime,
+	}
+	if o.HasStarted() {
+		record.WaitTime = typeutil.NewDuration(record.StartTime.Sub(record.CreateTime))
+		record.RunTime = typeutil.NewDuration(finishTime.Sub(record.StartTime))
+	} else {
+		record.WaitTime = typeutil.NewDuration(finishTime.Sub(record.CreateTime))
+	}
+	return record
+}
+
+// relatedStores returns the sorted IDs of stores touched by the steps.
+func (o *Operator) relatedStores() []uint64 {
+	set := make(map[uint64]struct{})
+	for _, step := range o.steps {
+		switch s := step.(type) {
+		case TransferLeader:
+			set[s.FromStore] = struct{}{}
+			set[s.ToStore] = struct{}{}
+		case AddPeer:
+			set[s.ToStore] = struct{}{}
+		case AddLearner:
+			set[s.ToStore] = struct{}{}
+		case AddLightPeer:
+			set[s.ToStore] = struct{}{}
+		case AddLightLearner:
+			set[s.ToStore] = struct{}{}
+		case AddWitness:
+			set[s.ToStore] = struct{}{}
+		case PromoteLearner:
+			set[s.ToStore] = struct{}{}
+		case PromoteWitness:
+			set[s.ToStore] = struct{}{}
+		case RemovePeer:
+			set[s.FromStore] = struct{}{}
+		}
+	}
+	stores := make([]uint64, 0, len(set))
+	for id := range set {
+		stores = append(stores, id)
+	}
+	sort.Slice(stores, func(i, j int) bool { return stores[i] < stores[j] })
+	return stores
+}
+
+// HasStore checks if the record touches the store.
+func (r *OpRecord) HasStore(storeID uint64) bool {
+	for _, id := range r.Stores {
+		if id == storeID {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchKind checks if the record has all flags of the kind.
+func (r *OpRecord) MatchKind(kind OpKind) bool {
+	k, err := ParseOperatorKind(r.Kind)
+	if err != nil {
+		return false
+	}
+	return k&kind == kind
+}
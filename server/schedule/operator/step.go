@@ -113,6 +113,7 @@ func (ap AddPeer) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
 	to.RegionSize += regionSize
 	to.RegionCount++
 	to.AdjustStepCost(storelimit.AddPeer, regionSize)
+	opInfluence.AdjustSnapshotCost(region, ap.ToStore)
 }
 
 // CheckSafety checks if the step meets the safety properties.
@@ -176,6 +177,7 @@ func (al AddLearner) Influence(opInfluence OpInfluence, region *core.RegionInfo)
 	to.RegionSize += regionSize
 	to.RegionCount++
 	to.AdjustStepCost(storelimit.AddPeer, regionSize)
+	opInfluence.AdjustSnapshotCost(region, al.ToStore)
 }
 
 // PromoteLearner is an OpStep that promotes a region learner peer to normal voter.
@@ -437,3 +439,103 @@ func (al AddLightLearner) Influence(opInfluence OpInfluence, region *core.Region
 	to.RegionSize += region.GetApproximateSize()
 	to.RegionCount++
 }
+
+// AddWitness is an OpStep that adds a region learner peer on a witness store.
+// It sends the same AddLearnerNode conf change as AddLearner, and is charged
+// the full region size and snapshot cost. A witness voter is demoted by
+// removing it and adding a new learner, as raft does not support demoting a
+// voter in place.
+type AddWitness struct {
+	ToStore, PeerID uint64
+}
+
+// ConfVerChanged returns true if the conf version has been changed by this step
+func (aw AddWitness) ConfVerChanged(region *core.RegionInfo) bool {
+	if p := region.GetStorePeer(aw.ToStore); p != nil {
+		return p.GetId() == aw.PeerID
+	}
+	return false
+}
+
+func (aw AddWitness) String() string {
+	return fmt.Sprintf("add witness peer %v on store %v", aw.PeerID, aw.ToStore)
+}
+
+// IsFinish checks if current step is finished.
+func (aw AddWitness) IsFinish(region *core.RegionInfo) bool {
+	if p := region.GetStoreLearner(aw.ToStore); p != nil {
+		if p.GetId() != aw.PeerID {
+			log.Warn("obtain unexpected peer", zap.String("expect", aw.String()), zap.Uint64("obtain-learner", p.GetId()))
+			return false
+		}
+		return region.GetPendingLearner(p.GetId()) == nil
+	}
+	return false
+}
+
+// CheckSafety checks if the step meets the safety properties.
+func (aw AddWitness) CheckSafety(region *core.RegionInfo) error {
+	peer := region.GetStorePeer(aw.ToStore)
+	if peer == nil {
+		return nil
+	}
+	if peer.GetId() != aw.PeerID {
+		return errors.Errorf("peer %d has already existed in store %d, the operator is trying to add peer %d on the same store", peer.GetId(), aw.ToStore, aw.PeerID)
+	}
+	if !peer.IsLearner {
+		return errors.New("peer already is a voter")
+	}
+	return nil
+}
+
+// Influence calculates the store difference that current step makes.
+func (aw AddWitness) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
+	to := opInfluence.GetStoreInfluence(aw.ToStore)
+
+	regionSize := region.GetApproximateSize()
+	to.RegionSize += regionSize
+	to.RegionCount++
+	to.AdjustStepCost(storelimit.AddPeer, regionSize)
+	opInfluence.AdjustSnapshotCost(region, aw.ToStore)
+}
+
+// PromoteWitness is an OpStep that promotes a learner peer on a witness store
+// to voter with the same AddNode conf change as PromoteLearner.
+type PromoteWitness struct {
+	ToStore, PeerID uint64
+}
+
+// ConfVerChanged returns true if the conf version has been changed by this step
+func (pw PromoteWitness) ConfVerChanged(region *core.RegionInfo) bool {
+	if p := region.GetStoreVoter(pw.ToStore); p != nil {
+		return p.GetId() == pw.PeerID
+	}
+	return false
+}
+
+func (pw PromoteWitness) String() string {
+	return fmt.Sprintf("promote witness peer %v on store %v to voter", pw.PeerID, pw.ToStore)
+}
+
+// IsFinish checks if current step is finished.
+func (pw PromoteWitness) IsFinish(region *core.RegionInfo) bool {
+	if p := region.GetStoreVoter(pw.ToStore); p != nil {
+		if p.GetId() != pw.PeerID {
+			log.Warn("obtain unexpected peer", zap.String("expect", pw.String()), zap.Uint64("obtain-voter", p.GetId()))
+		}
+		return p.GetId() == pw.PeerID
+	}
+	return false
+}
+
+// CheckSafety checks if the step meets the safety properties.
+func (pw PromoteWitness) CheckSafety(region *core.RegionInfo) error {
+	peer := region.GetStorePeer(pw.ToStore)
+	if peer == nil {
+		return errors.New("peer does not exist")
+	}
+	return nil
+}
+
+// Influence calculates the store difference that current step makes.
+func (pw PromoteWitness) Influence(opInfluence OpInfluence, region *core.RegionInfo) {}
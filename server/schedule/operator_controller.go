@@ -17,7 +17,9 @@ import (
 	"container/heap"
 	"container/list"
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -50,6 +52,13 @@ var (
 	PushOperatorTickInterval = 500 * time.Millisecond
 	// StoreBalanceBaseTime represents the base time of balance rate.
 	StoreBalanceBaseTime float64 = 60
+	// recordKeepTime is how long the persisted operator records are kept.
+	recordKeepTime = 24 * time.Hour
+	// maxRecords is the max count of the persisted operator records, it keeps
+	// the records in etcd small.
+	maxRecords = 10000
+	// maxPendingRecords bounds the records waiting to be persisted.
+	maxPendingRecords = 10000
 )
 
 // OperatorController is used to limit the speed of scheduling.
@@ -62,10 +71,21 @@ type OperatorController struct {
 	histories       *list.List
 	counts          map[operator.OpKind]uint64
 	opRecords       *OperatorRecords
-	storesLimit     map[uint64]map[storelimit.Type]*storelimit.StoreLimit
+	storesLimit     map[uint64]map[storelimit.Type]storelimit.StoreLimit
 	wop             WaitingOperator
 	wopStatus       *WaitingOperatorStatus
 	opNotifierQueue operatorQueue
+	// recordsMu protects storage and records. It is separated from the main
+	// lock because operators can be buried with the main lock held.
+	recordsMu sync.Mutex
+	storage   *core.Storage
+	// records are finished operators not persisted yet.
+	records []*operator.OpRecord
+	// recordSeq distinguishes the records with the same finish time and region.
+	recordSeq uint64
+	// recordCount is the count of the persisted records, -1 means it needs to
+	// be loaded from the storage. It is only accessed by persistRecords.
+	recordCount int
 }
 
 // NewOperatorController creates a OperatorController.
@@ -78,7 +98,7 @@ func NewOperatorController(ctx context.Context, cluster opt.Cluster, hbStreams o
 		histories:       list.New(),
 		counts:          make(map[operator.OpKind]uint64),
 		opRecords:       NewOperatorRecords(ctx),
-		storesLimit:     make(map[uint64]map[storelimit.Type]*storelimit.StoreLimit),
+		storesLimit:     make(map[uint64]map[storelimit.Type]storelimit.StoreLimit),
 		wop:             NewRandBuckets(),
 		wopStatus:       NewWaitingOperatorStatus(),
 		opNotifierQueue: make(operatorQueue, 0),
@@ -182,7 +202,7 @@ func (oc *OperatorController) checkStaleOperator(op *operator.Operator, step ope
 func (oc *OperatorController) getNextPushOperatorTime(step operator.OpStep, now time.Time) time.Time {
 	nextTime := slowNotifyInterval
 	switch step.(type) {
-	case operator.TransferLeader, operator.PromoteLearner:
+	case operator.TransferLeader, operator.PromoteLearner, operator.PromoteWitness:
 		nextTime = fastNotifyInterval
 	}
 	return now.Add(nextTime)
@@ -460,7 +480,7 @@ func (oc *OperatorController) addOperatorLocked(op *operator.Operator) bool {
 			if stepCost == 0 {
 				continue
 			}
-			storeLimit.Take(stepCost)
+			storeLimit.Take(storeLimit.Cost(stepCost, opInfluence.GetStoreInfluence(storeID).GetStepSize(v)))
 			storeLimitCostCounter.WithLabelValues(strconv.FormatUint(storeID, 10), n).Add(float64(stepCost) / float64(storelimit.RegionInfluence[v]))
 		}
 	}
@@ -570,6 +590,7 @@ func (oc *OperatorController) buryOperator(op *operator.Operator, extraFileds ..
 	}
 
 	oc.opRecords.Put(op)
+	oc.pushRecord(op.Record(time.Now()))
 }
 
 // GetOperatorStatus gets the operator and its status with the specify id.
@@ -697,6 +718,34 @@ func (oc *OperatorController) SendScheduleCommand(region *core.RegionInfo, step
 			},
 		}
 		oc.hbStreams.SendMsg(region, cmd)
+	case operator.AddWitness:
+		if region.GetStorePeer(st.ToStore) != nil {
+			// The newly added peer is pending.
+			return
+		}
+		cmd := &pdpb.RegionHeartbeatResponse{
+			ChangePeer: &pdpb.ChangePeer{
+				ChangeType: eraftpb.ConfChangeType_AddLearnerNode,
+				Peer: &metapb.Peer{
+					Id:        st.PeerID,
+					StoreId:   st.ToStore,
+					IsLearner: true,
+				},
+			},
+		}
+		oc.hbStreams.SendMsg(region, cmd)
+	case operator.PromoteWitness:
+		cmd := &pdpb.RegionHeartbeatResponse{
+			ChangePeer: &pdpb.ChangePeer{
+				// reuse AddNode type
+				ChangeType: eraftpb.ConfChangeType_AddNode,
+				Peer: &metapb.Peer{
+					Id:      st.PeerID,
+					StoreId: st.ToStore,
+				},
+			},
+		}
+		oc.hbStreams.SendMsg(region, cmd)
 	case operator.RemovePeer:
 		cmd := &pdpb.RegionHeartbeatResponse{
 			ChangePeer: &pdpb.ChangePeer{
@@ -739,13 +788,112 @@ func (oc *OperatorController) pushHistory(op *operator.Operator) {
 // PruneHistory prunes a part of operators' history.
 func (oc *OperatorController) PruneHistory() {
 	oc.Lock()
-	defer oc.Unlock()
 	p := oc.histories.Back()
 	for p != nil && time.Since(p.Value.(operator.OpHistory).FinishTime) > historyKeepTime {
 		prev := p.Prev()
 		oc.histories.Remove(p)
 		p = prev
 	}
+	oc.Unlock()
+	oc.persistRecords()
+}
+
+// SetStorage sets the storage to persist the finished operators. Records are
+// kept in memory only if the storage is not set.
+func (oc *OperatorController) SetStorage(storage *core.Storage) {
+	oc.recordsMu.Lock()
+	defer oc.recordsMu.Unlock()
+	oc.storage = storage
+	oc.recordCount = -1
+}
+
+func (oc *OperatorController) pushRecord(record *operator.OpRecord) {
+	oc.recordsMu.Lock()
+	defer oc.recordsMu.Unlock()
+	oc.records = append(oc.records, record)
+	// drop the oldest records if they cannot be persisted in time.
+	if len(oc.records) > maxPendingRecords {
+		oc.records = oc.records[len(oc.records)-maxPendingRecords:]
+	}
+}
+
+// persistRecords saves the pending records and removes the outdated ones.
+func (oc *OperatorController) persistRecords() {
+	oc.recordsMu.Lock()
+	storage, records := oc.storage, oc.records
+	if storage == nil {
+		oc.recordsMu.Unlock()
+		return
+	}
+	oc.records = nil
+	toSave := make(map[string]interface{}, len(records))
+	for _, r := range records {
+		oc.recordSeq++
+		toSave[core.OperatorHistoryKey(r.FinishTime, r.RegionID, oc.recordSeq)] = r
+	}
+	oc.recordsMu.Unlock()
+
+	if len(records) > 0 {
+		if err := storage.SaveOperatorHistory(toSave); err != nil {
+			log.Error("failed to persist operator records", zap.Int("count", len(records)), zap.Error(err))
+			// put them back and retry next time, a part of them may be saved
+			// so the count is reloaded.
+			oc.recordsMu.Lock()
+			oc.records = append(records, oc.records...)
+			if len(oc.records) > maxPendingRecords {
+				oc.records = oc.records[len(oc.records)-maxPendingRecords:]
+			}
+			oc.recordsMu.Unlock()
+			oc.recordCount = -1
+		} else if oc.recordCount >= 0 {
+			oc.recordCount += len(records)
+		}
+	}
+	if oc.recordCount < 0 {
+		count, err := storage.CountOperatorHistory()
+		if err != nil {
+			log.Error("failed to count operator records", zap.Error(err))
+			return
+		}
+		oc.recordCount = count
+	}
+	removed, err := storage.DeleteOperatorHistory(time.Now().Add(-recordKeepTime), oc.recordCount-maxRecords)
+	oc.recordCount -= removed
+	if err != nil {
+		log.Error("failed to prune operator records", zap.Error(err))
+	}
+}
+
+// GetRecords returns the records of operators finished in [start, end),
+// ordered by finish time.
+func (oc *OperatorController) GetRecords(start, end time.Time) ([]*operator.OpRecord, error) {
+	oc.recordsMu.Lock()
+	storage := oc.storage
+	pending := make([]*operator.OpRecord, 0, len(oc.records))
+	for _, r := range oc.records {
+		if !r.FinishTime.Before(start) && r.FinishTime.Before(end) {
+			pending = append(pending, r)
+		}
+	}
+	oc.recordsMu.Unlock()
+
+	var records []*operator.OpRecord
+	if storage != nil {
+		err := storage.LoadOperatorHistory(start, end, func(k, v string) {
+			r := &operator.OpRecord{}
+			if err1 := json.Unmarshal([]byte(v), r); err1 != nil {
+				log.Warn("failed to decode operator record", zap.String("key", k), zap.Error(err1))
+				return
+			}
+			records = append(records, r)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	records = append(records, pending...)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].FinishTime.Before(records[j].FinishTime) })
+	return records, nil
 }
 
 // GetHistory gets operators' history.
@@ -886,7 +1034,8 @@ func (oc *OperatorController) exceedStoreLimit(ops ...*operator.Operator) bool {
 			if stepCost == 0 {
 				continue
 			}
-			if oc.getOrCreateStoreLimit(storeID, v).Available() < stepCost {
+			storeLimit := oc.getOrCreateStoreLimit(storeID, v)
+			if !storeLimit.Allow(storeLimit.Cost(stepCost, opInfluence.GetStoreInfluence(storeID).GetStepSize(v))) {
 				return true
 			}
 		}
@@ -895,33 +1044,52 @@ func (oc *OperatorController) exceedStoreLimit(ops ...*operator.Operator) bool {
 }
 
 // newStoreLimit is used to create the limit of a store.
-func (oc *OperatorController) newStoreLimit(storeID uint64, ratePerSec float64, limitType storelimit.Type) {
-	log.Info("create or update a store limit", zap.Uint64("store-id", storeID), zap.String("type", limitType.String()), zap.Float64("rate", ratePerSec))
+func (oc *OperatorController) newStoreLimit(storeID uint64, algorithm storelimit.Algorithm, ratePerSec float64, limitType storelimit.Type) {
+	log.Info("create or update a store limit", zap.Uint64("store-id", storeID), zap.String("type", limitType.String()), zap.String("algorithm", string(algorithm)), zap.Float64("rate", ratePerSec))
 	if oc.storesLimit[storeID] == nil {
-		oc.storesLimit[storeID] = make(map[storelimit.Type]*storelimit.StoreLimit)
+		oc.storesLimit[storeID] = make(map[storelimit.Type]storelimit.StoreLimit)
 	}
-	oc.storesLimit[storeID][limitType] = storelimit.NewStoreLimit(ratePerSec, storelimit.RegionInfluence[limitType])
+	oc.storesLimit[storeID][limitType] = storelimit.NewStoreLimit(algorithm, ratePerSec, limitType)
 }
 
 // getOrCreateStoreLimit is used to get or create the limit of a store.
-func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64, limitType storelimit.Type) *storelimit.StoreLimit {
+func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64, limitType storelimit.Type) storelimit.StoreLimit {
+	algorithm := oc.cluster.GetStoreLimitAlgorithm(storeID)
+	ratePerSec := oc.cluster.GetStoreLimitByType(storeID, limitType) / StoreBalanceBaseTime
 	if oc.storesLimit[storeID][limitType] == nil {
-		ratePerSec := oc.cluster.GetStoreLimitByType(storeID, limitType) / StoreBalanceBaseTime
-		oc.newStoreLimit(storeID, ratePerSec, limitType)
+		oc.newStoreLimit(storeID, algorithm, ratePerSec, limitType)
 		oc.cluster.AttachAvailableFunc(storeID, limitType, func() bool {
 			oc.RLock()
 			defer oc.RUnlock()
-			if oc.storesLimit[storeID][limitType] == nil {
+			storeLimit := oc.storesLimit[storeID][limitType]
+			if storeLimit == nil {
 				return true
 			}
-			return oc.storesLimit[storeID][limitType].Available() >= storelimit.RegionInfluence[limitType]
+			return storeLimit.Allow(storeLimit.RegionCost())
 		})
 	}
-	ratePerSec := oc.cluster.GetStoreLimitByType(storeID, limitType) / StoreBalanceBaseTime
-	if ratePerSec != oc.storesLimit[storeID][limitType].Rate() {
-		oc.newStoreLimit(storeID, ratePerSec, limitType)
+	storeLimit := oc.storesLimit[storeID][limitType]
+	if ratePerSec != storeLimit.Rate() || algorithm != storeLimit.Algorithm() {
+		oc.newStoreLimit(storeID, algorithm, ratePerSec, limitType)
+		storeLimit = oc.storesLimit[storeID][limitType]
+	}
+	if store := oc.cluster.GetStore(storeID); store != nil {
+		storeLimit.Adjust(pendingSnapshotCount(store, limitType), oc.cluster.GetMaxSnapshotCount())
+	}
+	return storeLimit
+}
+
+// pendingSnapshotCount returns the count of the snapshots that are related to
+// the specific type store limit and still in progress on the store.
+func pendingSnapshotCount(store *core.StoreInfo, limitType storelimit.Type) uint64 {
+	switch limitType {
+	case storelimit.AddPeer, storelimit.ReceiveSnapshot:
+		return uint64(store.GetReceivingSnapCount())
+	case storelimit.RemovePeer, storelimit.SendSnapshot:
+		return uint64(store.GetSendingSnapCount())
+	default:
+		return 0
 	}
-	return oc.storesLimit[storeID][limitType]
 }
 
 // GetLeaderSchedulePolicy is to get leader schedule policy.
@@ -945,14 +1113,14 @@ func (oc *OperatorController) CollectStoreLimitMetrics() {
 			storeID := store.GetID()
 			storeIDStr := strconv.FormatUint(storeID, 10)
 			for n, v := range storelimit.TypeNameValue {
-				var storeLimit *storelimit.StoreLimit
+				var storeLimit storelimit.StoreLimit
 				if oc.storesLimit[storeID] == nil || oc.storesLimit[storeID][v] == nil {
 					// Set to 0 to represent the store limit of the specific type is not initialized.
 					storeLimitRateGauge.WithLabelValues(storeIDStr, n).Set(0)
 					continue
 				}
 				storeLimit = oc.storesLimit[storeID][v]
-				storeLimitAvailableGauge.WithLabelValues(storeIDStr, n).Set(float64(storeLimit.Available()) / float64(storelimit.RegionInfluence[v]))
+				storeLimitAvailableGauge.WithLabelValues(storeIDStr, n).Set(float64(storeLimit.Available()) / float64(storeLimit.RegionCost()))
 				storeLimitRateGauge.WithLabelValues(storeIDStr, n).Set(storeLimit.Rate() * StoreBalanceBaseTime)
 			}
 		}
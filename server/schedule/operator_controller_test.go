@@ -401,6 +401,51 @@ func (t *testOperatorControllerSuite) TestStoreLimit(c *C) {
 	c.Assert(oc.RemoveOperator(op), IsFalse)
 }
 
+func (t *testOperatorControllerSuite) TestAdaptiveStoreLimit(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	for i := uint64(1); i <= 20; i++ {
+		tc.AddLeaderRegion(i, 1)
+	}
+	tc.PutRegion(tc.GetRegion(20).Clone(core.SetApproximateSize(200)))
+
+	// 0.1 operator per second, which fills about 9.6MB per second.
+	tc.SetStoreLimit(2, storelimit.AddPeer, 6)
+	tc.SetStoreLimitAlgorithm(2, string(storelimit.Adaptive))
+	c.Assert(tc.GetStoreLimitAlgorithm(2), Equals, storelimit.Adaptive)
+	// Each step is charged by the region size (10MB), the capacity is 96MB.
+	for i := uint64(1); i <= 9; i++ {
+		op := operator.NewOperator("test", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: i})
+		c.Assert(oc.AddOperator(op), IsTrue)
+		checkRemoveOperatorSuccess(c, oc, op)
+	}
+	op := operator.NewOperator("test", "test", 10, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 10})
+	c.Assert(oc.AddOperator(op), IsFalse)
+
+	// A region larger than the capacity is allowed when the bucket is full.
+	tc.SetStoreLimit(3, storelimit.AddPeer, 6)
+	tc.SetStoreLimitAlgorithm(3, string(storelimit.Adaptive))
+	op = operator.NewOperator("test", "test", 20, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 3, PeerID: 20})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	checkRemoveOperatorSuccess(c, oc, op)
+	op = operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 3, PeerID: 21})
+	c.Assert(oc.AddOperator(op), IsFalse)
+
+	// Switching back to the token bucket, each small region costs 1/5 operator.
+	tc.SetStoreLimitAlgorithm(2, string(storelimit.TokenBucket))
+	for i := uint64(1); i <= 5; i++ {
+		op = operator.NewOperator("test", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: i})
+		c.Assert(oc.AddOperator(op), IsTrue)
+		checkRemoveOperatorSuccess(c, oc, op)
+	}
+	op = operator.NewOperator("test", "test", 6, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 6})
+	c.Assert(oc.AddOperator(op), IsFalse)
+}
+
 // #1652
 func (t *testOperatorControllerSuite) TestDispatchOutdatedRegion(c *C) {
 	cluster := mockcluster.NewCluster(mockoption.NewScheduleOptions())
@@ -34,6 +34,7 @@ type Options interface {
 
 	// store limit
 	GetStoreLimitByType(storeID uint64, typ storelimit.Type) float64
+	GetStoreLimitAlgorithm(storeID uint64) storelimit.Algorithm
 	SetAllStoresLimit(typ storelimit.Type, ratePerMin float64)
 
 	GetMaxSnapshotCount() uint64
@@ -44,6 +45,8 @@ type Options interface {
 	GetSplitMergeInterval() time.Duration
 	IsOneWayMergeEnabled() bool
 	IsCrossTableMergeEnabled() bool
+	IsHotRegionSplitEnabled() bool
+	GetHotRegionSplitPolicy() pdpb.CheckPolicy
 
 	GetMaxReplicas() int
 	GetLocationLabels() []string
@@ -76,6 +76,17 @@ func CompareRegionFit(a, b *RegionFit) int {
 	}
 }
 
+// GetLeaderPreferences returns the leader preferences of the rule that the
+// leader peer belongs to. Stores that can hold the leader should be ranked by
+// these preferences.
+func (f *RegionFit) GetLeaderPreferences(leader *metapb.Peer) []LabelPreference {
+	rf := f.GetRuleFit(leader.GetId())
+	if rf == nil {
+		return nil
+	}
+	return rf.Rule.LeaderPreferences
+}
+
 // RuleFit is the result of fitting status of a Rule.
 type RuleFit struct {
 	Rule *Rule
@@ -88,6 +99,9 @@ type RuleFit struct {
 	// IsolationLevel indicates at which level of labeling these Peers are
 	// isolated. A larger value indicates a higher isolation level.
 	IsolationLevel int
+	// PreferenceScore indicates how much the stores of these Peers are
+	// preferred by the label preferences of the Rule. A larger value is better.
+	PreferenceScore int
 }
 
 // IsSatisfied returns if the rule is properly satisfied.
@@ -109,6 +123,10 @@ func compareRuleFit(a, b *RuleFit) int {
 		return -1
 	case a.IsolationLevel > b.IsolationLevel:
 		return 1
+	case a.PreferenceScore < b.PreferenceScore:
+		return -1
+	case a.PreferenceScore > b.PreferenceScore:
+		return 1
 	default:
 		return 0
 	}
@@ -140,7 +158,7 @@ func fitRule(peers []*fitPeer, rule *Rule) *RuleFit {
 	// Ignore peers that does not match label constraints, and that cannot be
 	// transformed to expected role type.
 	peers = filterPeersBy(peers,
-		func(p *fitPeer) bool { return MatchLabelConstraints(p.store, rule.LabelConstraints) },
+		func(p *fitPeer) bool { return MatchLabelConstraints(p.store, rule.GetLabelConstraints()) },
 		func(p *fitPeer) bool { return p.matchRoleLoose(rule.Role) })
 
 	if len(peers) <= rule.Count {
@@ -162,6 +180,7 @@ func newRuleFit(rule *Rule, peers []*fitPeer) *RuleFit {
 	rf := &RuleFit{Rule: rule, IsolationLevel: isolationLevel(peers, rule.LocationLabels)}
 	for _, p := range peers {
 		rf.Peers = append(rf.Peers, p.Peer)
+		rf.PreferenceScore += PreferenceScore(p.store, rule.LabelPreferences)
 		if !p.matchRoleStrict(rule.Role) {
 			rf.PeersWithDifferentRole = append(rf.PeersWithDifferentRole, p.Peer)
 		}
@@ -171,8 +190,9 @@ func newRuleFit(rule *Rule, peers []*fitPeer) *RuleFit {
 
 type fitPeer struct {
 	*metapb.Peer
-	store    *core.StoreInfo
-	isLeader bool
+	store     *core.StoreInfo
+	isLeader  bool
+	isWitness bool
 }
 
 func (p *fitPeer) matchRoleStrict(role PeerRoleType) bool {
@@ -185,11 +205,18 @@ func (p *fitPeer) matchRoleStrict(role PeerRoleType) bool {
 		return !p.IsLearner && !p.isLeader
 	case Learner:
 		return p.IsLearner
+	case Witness: // Witness matches a Follower on witness store, never the Leader.
+		return !p.IsLearner && !p.isLeader
 	}
 	return false
 }
 
 func (p *fitPeer) matchRoleLoose(role PeerRoleType) bool {
+	// Peers on witness stores can only be witnesses, and witnesses can only
+	// be placed on witness stores.
+	if p.isWitness != (role == Witness) {
+		return false
+	}
 	// non-learner cannot become learner. All other roles can migrate to
 	// others by scheduling. For example, Leader->Follower, Learner->Leader
 	// are possible, but Voter->Learner is impossible.
@@ -199,10 +226,12 @@ func (p *fitPeer) matchRoleLoose(role PeerRoleType) bool {
 func prepareFitPeers(stores core.StoreSetInformer, region *core.RegionInfo) []*fitPeer {
 	var peers []*fitPeer
 	for _, p := range region.GetPeers() {
+		store := stores.GetStore(p.GetStoreId())
 		peers = append(peers, &fitPeer{
-			Peer:     p,
-			store:    stores.GetStore(p.GetStoreId()),
-			isLeader: region.GetLeader().GetId() == p.GetId(),
+			Peer:      p,
+			store:     store,
+			isLeader:  region.GetLeader().GetId() == p.GetId(),
+			isWitness: store != nil && store.IsWitness(),
 		})
 	}
 	// Sort peers to keep the match result deterministic.
@@ -162,3 +162,83 @@ func (s *testFitSuite) TestFitByLocation(c *C) {
 		c.Assert(ruleFit.IsolationLevel, Equals, cc.expectedIsolationLevel)
 	}
 }
+
+func (s *testFitSuite) TestFitByPreference(c *C) {
+	stores := core.NewBasicCluster()
+	for id, zone := range map[uint64]string{1: "z1", 2: "z1", 3: "z2", 4: "z3", 5: "z3"} {
+		stores.PutStore(core.NewStoreInfoWithLabel(id, 0, map[string]string{"zone": zone, "host": fmt.Sprintf("h%d", id)}))
+	}
+	region := core.NewRegionInfo(&metapb.Region{Peers: []*metapb.Peer{
+		{Id: 1, StoreId: 1}, {Id: 2, StoreId: 2}, {Id: 3, StoreId: 3}, {Id: 4, StoreId: 4}, {Id: 5, StoreId: 5},
+	}}, &metapb.Peer{Id: 1, StoreId: 1})
+
+	// without preference, peers with smaller IDs are selected.
+	rule := &Rule{GroupID: "pd", ID: "default", Role: Voter, Count: 3, LocationLabels: []string{"host"}}
+	fit := FitRegion(stores, region, []*Rule{rule})
+	c.Assert(fit.RuleFits[0].PreferenceScore, Equals, 0)
+	c.Assert(s.peerStores(fit.RuleFits[0]), DeepEquals, []uint64{1, 2, 3})
+
+	// prefer z3 then z2.
+	rule.LabelPreferences = []LabelPreference{{Key: "zone", Values: []string{"z3", "z2"}}}
+	fit = FitRegion(stores, region, []*Rule{rule})
+	c.Assert(fit.RuleFits[0].PreferenceScore, Equals, 5)
+	c.Assert(s.peerStores(fit.RuleFits[0]), DeepEquals, []uint64{3, 4, 5})
+	c.Assert(fit.OrphanPeers, HasLen, 2)
+
+	// isolation level is more important than preference.
+	rule.LocationLabels = []string{"zone", "host"}
+	fit = FitRegion(stores, region, []*Rule{rule})
+	c.Assert(fit.RuleFits[0].IsolationLevel, Equals, 2)
+	c.Assert(fit.RuleFits[0].PreferenceScore, Equals, 3)
+	c.Assert(s.peerStores(fit.RuleFits[0]), DeepEquals, []uint64{1, 3, 4})
+
+	// leader preference.
+	rule.LeaderPreferences = []LabelPreference{{Key: "zone", Values: []string{"z2"}}}
+	fit = FitRegion(stores, region, []*Rule{rule})
+	c.Assert(fit.GetLeaderPreferences(region.GetStorePeer(1)), DeepEquals, rule.LeaderPreferences)
+	c.Assert(fit.GetLeaderPreferences(region.GetStorePeer(2)), IsNil)
+}
+
+func (s *testFitSuite) TestFitWitness(c *C) {
+	stores := core.NewBasicCluster()
+	for id := uint64(1); id <= 3; id++ {
+		stores.PutStore(core.NewStoreInfoWithLabel(id, 0, nil))
+	}
+	stores.PutStore(core.NewStoreInfoWithLabel(4, 0, map[string]string{"engine": "witness"}))
+	peers := []*metapb.Peer{{Id: 1, StoreId: 1}, {Id: 2, StoreId: 2}, {Id: 3, StoreId: 3}, {Id: 4, StoreId: 4}}
+	voter := &Rule{GroupID: "pd", ID: "default", Role: Voter, Count: 4}
+	witness := &Rule{GroupID: "pd", ID: "witness", Role: Witness, Count: 1}
+
+	// peers on witness store do not match other roles.
+	region := core.NewRegionInfo(&metapb.Region{Peers: peers}, peers[0])
+	fit := FitRegion(stores, region, []*Rule{voter})
+	c.Assert(s.peerStores(fit.RuleFits[0]), DeepEquals, []uint64{1, 2, 3})
+	c.Assert(fit.OrphanPeers, HasLen, 1)
+	c.Assert(fit.OrphanPeers[0].GetStoreId(), Equals, uint64(4))
+
+	voter.Count = 3
+	fit = FitRegion(stores, region, []*Rule{voter, witness})
+	c.Assert(fit.IsSatisfied(), IsTrue)
+	c.Assert(s.peerStores(fit.RuleFits[1]), DeepEquals, []uint64{4})
+
+	// witness is never chosen as leader.
+	region = core.NewRegionInfo(&metapb.Region{Peers: peers}, peers[3])
+	fit = FitRegion(stores, region, []*Rule{voter, witness})
+	c.Assert(fit.RuleFits[1].PeersWithDifferentRole, HasLen, 1)
+	c.Assert(fit.IsSatisfied(), IsFalse)
+
+	// witness cannot be placed on other stores.
+	region = core.NewRegionInfo(&metapb.Region{Peers: peers[:3]}, peers[0])
+	fit = FitRegion(stores, region, []*Rule{witness})
+	c.Assert(fit.RuleFits[0].Peers, HasLen, 0)
+	c.Assert(fit.OrphanPeers, HasLen, 3)
+}
+
+func (s *testFitSuite) peerStores(rf *RuleFit) []uint64 {
+	var ids []uint64
+	for _, p := range rf.Peers {
+		ids = append(ids, p.GetStoreId())
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
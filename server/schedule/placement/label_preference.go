@@ -0,0 +1,73 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pkg/errors"
+)
+
+// LabelPreference is a soft constraint used to rank stores. Unlike
+// LabelConstraint, a store that does not match the preference can still be
+// selected, it is just less preferred.
+type LabelPreference struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`           // ordered label values, the former one is more preferred
+	Weight int      `json:"weight,omitempty"` // weight of the preference, 1 is used if it is not set
+}
+
+func (p *LabelPreference) validate() error {
+	if p.Key == "" {
+		return errors.New("label preference key should not be empty")
+	}
+	if len(p.Values) == 0 {
+		return errors.Errorf("label preference %s should have at least one value", p.Key)
+	}
+	if p.Weight < 0 {
+		return errors.Errorf("invalid label preference weight %v", p.Weight)
+	}
+	return nil
+}
+
+// score returns weight*(len(values)-i) if the store label value is the i-th
+// value of the preference, and 0 if the store does not match any value.
+func (p *LabelPreference) score(store *core.StoreInfo) int {
+	label := store.GetLabelValue(p.Key)
+	if label == "" {
+		return 0
+	}
+	weight := p.Weight
+	if weight == 0 {
+		weight = 1
+	}
+	for i, v := range p.Values {
+		if v == label {
+			return weight * (len(p.Values) - i)
+		}
+	}
+	return 0
+}
+
+// PreferenceScore returns how much a store is preferred by the preferences
+// list. A larger value means the store is more preferred.
+func PreferenceScore(store *core.StoreInfo, preferences []LabelPreference) int {
+	if store == nil {
+		return 0
+	}
+	var score int
+	for i := range preferences {
+		score += preferences[i].score(store)
+	}
+	return score
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server/core"
+)
+
+var _ = Suite(&testLabelPreferenceSuite{})
+
+type testLabelPreferenceSuite struct{}
+
+func (s *testLabelPreferenceSuite) TestPreferenceScore(c *C) {
+	stores := []*core.StoreInfo{
+		core.NewStoreInfoWithLabel(1, 0, map[string]string{"zone": "z1", "disk": "ssd"}),
+		core.NewStoreInfoWithLabel(2, 0, map[string]string{"zone": "z2", "disk": "hdd"}),
+		core.NewStoreInfoWithLabel(3, 0, map[string]string{"zone": "z3", "disk": "ssd"}),
+		core.NewStoreInfoWithLabel(4, 0, map[string]string{"disk": "ssd"}),
+		nil,
+	}
+	preferences := []LabelPreference{
+		{Key: "zone", Values: []string{"z1", "z2"}, Weight: 10},
+		{Key: "disk", Values: []string{"ssd"}},
+	}
+	expected := []int{21, 10, 1, 1, 0}
+	for i, store := range stores {
+		c.Assert(PreferenceScore(store, preferences), Equals, expected[i])
+	}
+	c.Assert(PreferenceScore(stores[0], nil), Equals, 0)
+}
+
+func (s *testLabelPreferenceSuite) TestValidate(c *C) {
+	c.Assert((&LabelPreference{Key: "zone", Values: []string{"z1"}}).validate(), IsNil)
+	c.Assert((&LabelPreference{Key: "zone", Values: []string{"z1"}, Weight: 3}).validate(), IsNil)
+	c.Assert((&LabelPreference{Values: []string{"z1"}}).validate(), NotNil)
+	c.Assert((&LabelPreference{Key: "zone"}).validate(), NotNil)
+	c.Assert((&LabelPreference{Key: "zone", Values: []string{"z1"}, Weight: -1}).validate(), NotNil)
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"github.com/pingcap/pd/v4/server/core"
+)
+
+// maxPlanSampleRegions is the max number of affected region IDs kept in a
+// PlanImpact.
+const maxPlanSampleRegions = 32
+
+// RulePlan contains the rules that would take effect after applying a batch
+// of rule operations. It is used to estimate the impact of the operations
+// without really applying them.
+type RulePlan struct {
+	ruleList ruleList
+}
+
+// Plan builds a RulePlan by applying the operations to a copy of current
+// rules. Current rules are not changed.
+func (m *RuleManager) Plan(todo []RuleOp) (*RulePlan, error) {
+	if err := m.checkRuleOps(todo); err != nil {
+		return nil, err
+	}
+	m.RLock()
+	defer m.RUnlock()
+	_, ruleList, err := m.applyRuleOpsLocked(todo)
+	if err != nil {
+		return nil, err
+	}
+	return &RulePlan{ruleList: ruleList}, nil
+}
+
+// FitRegion fits a region to the planned rules.
+func (p *RulePlan) FitRegion(stores core.StoreSetInformer, region *core.RegionInfo) *RegionFit {
+	rules := p.ruleList.getRulesForApplyRegion(region.GetStartKey(), region.GetEndKey())
+	return FitRegion(stores, region, rules)
+}
+
+// PlanImpact is the estimated impact of applying a RulePlan to a cluster.
+type PlanImpact struct {
+	RegionCount           int            `json:"region_count"`             // count of checked regions
+	AffectedCount         int            `json:"affected_count"`           // count of regions that are not satisfied by the planned rules
+	NewUnsatisfiedCount   int            `json:"new_unsatisfied_count"`    // count of regions that lack peers or have peers in wrong roles only after applying the plan
+	NewOverSatisfiedCount int            `json:"new_over_satisfied_count"` // count of regions that have orphan peers only after applying the plan
+	SplitCount            int            `json:"split_count"`              // count of regions that need to be split because they cross rule ranges
+	PeerAdditions         map[uint64]int `json:"peer_additions"`           // estimated count of peers to add to each store
+	PeerRemovals          map[uint64]int `json:"peer_removals"`            // count of peers to remove from each store
+	UnplaceablePeers      int            `json:"unplaceable_peers"`        // count of peers that cannot be placed to any store
+	SampleRegions         []uint64       `json:"sample_regions"`           // IDs of some affected regions
+}
+
+// EstimateImpact fits every region to both current rules and the planned
+// rules, and estimates how many peers need to be added to or removed from
+// each store to satisfy the planned rules. The target store of a new peer is
+// estimated by choosing the store that matches the label constraints and has
+// the least regions, including the peers already planned to add.
+func (m *RuleManager) EstimateImpact(stores core.StoreSetInformer, regions []*core.RegionInfo, plan *RulePlan) *PlanImpact {
+	impact := &PlanImpact{
+		RegionCount:   len(regions),
+		PeerAdditions: make(map[uint64]int),
+		PeerRemovals:  make(map[uint64]int),
+	}
+	for _, region := range regions {
+		oldFit, newFit := m.FitRegion(stores, region), plan.FitRegion(stores, region)
+		if !isFitUnsatisfied(oldFit) && isFitUnsatisfied(newFit) {
+			impact.NewUnsatisfiedCount++
+		}
+		if len(oldFit.OrphanPeers) == 0 && len(newFit.OrphanPeers) > 0 {
+			impact.NewOverSatisfiedCount++
+		}
+		if newFit.IsSatisfied() {
+			continue
+		}
+		impact.AffectedCount++
+		if len(impact.SampleRegions) < maxPlanSampleRegions {
+			impact.SampleRegions = append(impact.SampleRegions, region.GetID())
+		}
+		if len(newFit.RuleFits) == 0 {
+			impact.SplitCount++
+			continue
+		}
+		excluded := region.GetStoreIds()
+		for _, rf := range newFit.RuleFits {
+			for i := len(rf.Peers); i < rf.Rule.Count; i++ {
+				store := impact.pickStore(stores, rf.Rule, excluded)
+				if store == nil {
+					impact.UnplaceablePeers++
+					continue
+				}
+				excluded[store.GetID()] = struct{}{}
+				impact.PeerAdditions[store.GetID()]++
+			}
+		}
+		for _, p := range newFit.OrphanPeers {
+			impact.PeerRemovals[p.GetStoreId()]++
+		}
+	}
+	return impact
+}
+
+func (impact *PlanImpact) pickStore(stores core.StoreSetInformer, rule *Rule, excluded map[uint64]struct{}) *core.StoreInfo {
+	var best *core.StoreInfo
+	var bestCount int
+	for _, s := range stores.GetStores() {
+		if _, ok := excluded[s.GetID()]; ok || !s.IsUp() || !MatchLabelConstraints(s, rule.GetLabelConstraints()) {
+			continue
+		}
+		if s.IsWitness() != (rule.Role == Witness) {
+			continue
+		}
+		count := s.GetRegionCount() + impact.PeerAdditions[s.GetID()]
+		if best == nil || count < bestCount || (count == bestCount && s.GetID() < best.GetID()) {
+			best, bestCount = s, count
+		}
+	}
+	return best
+}
+
+// isFitUnsatisfied returns true if the region lacks peers or has peers in
+// wrong roles. Unlike RegionFit.IsSatisfied, orphan peers are not counted.
+func isFitUnsatisfied(fit *RegionFit) bool {
+	if len(fit.RuleFits) == 0 {
+		return true
+	}
+	for _, rf := range fit.RuleFits {
+		if !rf.IsSatisfied() {
+			return true
+		}
+	}
+	return false
+}
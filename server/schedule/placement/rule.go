@@ -31,28 +31,40 @@ const (
 	Follower PeerRoleType = "follower"
 	// Learner matches a learner.
 	Learner PeerRoleType = "learner"
+	// Witness matches a follower on a store labeled `engine=witness`, and
+	// such stores can only hold witnesses. It is NOT a distinct raft role:
+	// PD only uses the store label to choose where the peer goes. The peer is
+	// added and promoted with the same AddLearnerNode/AddNode conf changes as
+	// a normal voter, it receives a full snapshot, and scheduling charges it
+	// the full region size and snapshot cost. Whether the store keeps less
+	// data than a normal replica is up to the store, not PD.
+	Witness PeerRoleType = "witness"
 )
 
 func validateRole(s PeerRoleType) bool {
-	return s == Voter || s == Leader || s == Follower || s == Learner
+	return s == Voter || s == Leader || s == Follower || s == Learner || s == Witness
 }
 
 // Rule is the placement rule that can be checked against a region. When
 // applying rules (apply means schedule regions to match selected rules), the
-// apply order is defined by the tuple [GroupID, Index, ID].
+// apply order is defined by the tuple [GroupIndex, GroupID, Index, ID].
 type Rule struct {
-	GroupID          string            `json:"group_id"`                    // mark the source that add the rule
-	ID               string            `json:"id"`                          // unique ID within a group
-	Index            int               `json:"index,omitempty"`             // rule apply order in a group, rule with less ID is applied first when indexes are equal
-	Override         bool              `json:"override,omitempty"`          // when it is true, all rules with less indexes are disabled
-	StartKey         []byte            `json:"-"`                           // range start key
-	StartKeyHex      string            `json:"start_key"`                   // hex format start key, for marshal/unmarshal
-	EndKey           []byte            `json:"-"`                           // range end key
-	EndKeyHex        string            `json:"end_key"`                     // hex format end key, for marshal/unmarshal
-	Role             PeerRoleType      `json:"role"`                        // expected role of the peers
-	Count            int               `json:"count"`                       // expected count of the peers
-	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"` // used to select stores to place peers
-	LocationLabels   []string          `json:"location_labels,omitempty"`   // used to make peers isolated physically
+	GroupID           string            `json:"group_id"`                     // mark the source that add the rule
+	ID                string            `json:"id"`                           // unique ID within a group
+	Index             int               `json:"index,omitempty"`              // rule apply order in a group, rule with less ID is applied first when indexes are equal
+	Override          bool              `json:"override,omitempty"`           // when it is true, all rules with less indexes are disabled
+	StartKey          []byte            `json:"-"`                            // range start key
+	StartKeyHex       string            `json:"start_key"`                    // hex format start key, for marshal/unmarshal
+	EndKey            []byte            `json:"-"`                            // range end key
+	EndKeyHex         string            `json:"end_key"`                      // hex format end key, for marshal/unmarshal
+	Role              PeerRoleType      `json:"role"`                         // expected role of the peers
+	Count             int               `json:"count"`                        // expected count of the peers
+	LabelConstraints  []LabelConstraint `json:"label_constraints,omitempty"`  // used to select stores to place peers
+	LocationLabels    []string          `json:"location_labels,omitempty"`    // used to make peers isolated physically
+	LabelPreferences  []LabelPreference `json:"label_preferences,omitempty"`  // used to rank stores to place peers, soft constraints
+	LeaderPreferences []LabelPreference `json:"leader_preferences,omitempty"` // used to rank stores to place the leader, soft constraints
+
+	group *RuleGroup // only set at runtime, no need to {,un}marshal or persist.
 }
 
 func (r Rule) String() string {
@@ -70,9 +82,59 @@ func (r Rule) StoreKey() string {
 	return hex.EncodeToString([]byte(r.GroupID)) + "-" + hex.EncodeToString([]byte(r.ID))
 }
 
-// Rules are ordered by (GroupID, Index, ID).
+// GetLabelConstraints returns the label constraints used to select stores.
+// The `engine` label is exclusive, so witness rules imply `engine=witness`
+// unless they specify the engine themselves.
+func (r *Rule) GetLabelConstraints() []LabelConstraint {
+	if r.Role != Witness {
+		return r.LabelConstraints
+	}
+	for _, c := range r.LabelConstraints {
+		if c.Key == "engine" {
+			return r.LabelConstraints
+		}
+	}
+	constraints := make([]LabelConstraint, 0, len(r.LabelConstraints)+1)
+	constraints = append(constraints, r.LabelConstraints...)
+	return append(constraints, LabelConstraint{Key: "engine", Op: In, Values: []string{"witness"}})
+}
+
+func (r *Rule) groupIndex() int {
+	if r.group != nil {
+		return r.group.Index
+	}
+	return 0
+}
+
+func (r *Rule) groupOverride() bool {
+	return r.group != nil && r.group.Override
+}
+
+// RuleGroup defines properties of a rule group.
+type RuleGroup struct {
+	ID       string `json:"id"`                 // unique ID of the group, matches GroupID of rules
+	Index    int    `json:"index,omitempty"`    // group apply order, group with less index is applied first
+	Override bool   `json:"override,omitempty"` // when it is true, all groups with less indexes are disabled
+}
+
+func (g *RuleGroup) String() string {
+	b, _ := json.Marshal(g)
+	return string(b)
+}
+
+// isDefault returns if the group has the same properties as a group that is
+// never configured.
+func (g *RuleGroup) isDefault() bool {
+	return g.Index == 0 && !g.Override
+}
+
+// Rules are ordered by (GroupIndex, GroupID, Index, ID).
 func compareRule(a, b *Rule) int {
 	switch {
+	case a.groupIndex() < b.groupIndex():
+		return -1
+	case a.groupIndex() > b.groupIndex():
+		return 1
 	case a.GroupID < b.GroupID:
 		return -1
 	case a.GroupID > b.GroupID:
@@ -100,7 +162,11 @@ func prepareRulesForApply(rules []*Rule) []*Rule {
 	var i, j int
 	for i = 1; i < len(rules); i++ {
 		if rules[j].GroupID != rules[i].GroupID {
-			res = append(res, rules[j:i]...)
+			if rules[i].groupOverride() {
+				res = res[:0] // override all previous groups
+			} else {
+				res = append(res, rules[j:i]...)
+			}
 			j = i
 		}
 		if rules[i].Override {
@@ -17,6 +17,9 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/pingcap/log"
@@ -32,14 +35,16 @@ type RuleManager struct {
 	sync.RWMutex
 	initialized bool
 	rules       map[[2]string]*Rule
+	ruleGroups  map[string]*RuleGroup
 	ruleList    ruleList
 }
 
 // NewRuleManager creates a RuleManager instance.
 func NewRuleManager(store *core.Storage) *RuleManager {
 	return &RuleManager{
-		store: store,
-		rules: make(map[[2]string]*Rule),
+		store:      store,
+		rules:      make(map[[2]string]*Rule),
+		ruleGroups: make(map[string]*RuleGroup),
 	}
 }
 
@@ -55,11 +60,16 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 	if err := m.loadRules(); err != nil {
 		return err
 	}
+	if err := m.loadGroups(); err != nil {
+		return err
+	}
 	if len(m.rules) == 0 {
 		// migrate from old config.
 		defaultRule := &Rule{
 			GroupID:        "pd",
 			ID:             "default",
+			StartKey:       []byte{},
+			EndKey:         []byte{},
 			Role:           Voter,
 			Count:          maxReplica,
 			LocationLabels: locationLabels,
@@ -121,6 +131,36 @@ func (m *RuleManager) loadRules() error {
 	return nil
 }
 
+func (m *RuleManager) loadGroups() error {
+	var toDelete []string
+	_, err := m.store.LoadRuleGroups(func(k, v string) {
+		var g RuleGroup
+		if err := json.Unmarshal([]byte(v), &g); err != nil {
+			log.Error("failed to unmarshal rule group", zap.String("group-id", k), zap.String("group-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		if g.ID != k {
+			log.Error("mismatch group id, need to delete", zap.String("group-id", k), zap.String("group-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		m.ruleGroups[g.ID] = &g
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range toDelete {
+		if err = m.store.DeleteRuleGroup(d); err != nil {
+			return err
+		}
+	}
+	for _, r := range m.rules {
+		r.group = m.ruleGroups[r.GroupID]
+	}
+	return nil
+}
+
 // check and adjust rule from client or storage.
 func (m *RuleManager) adjustRule(r *Rule) error {
 	var err error
@@ -152,6 +192,19 @@ func (m *RuleManager) adjustRule(r *Rule) error {
 			return errors.Errorf("invalid op %s", c.Op)
 		}
 	}
+	for i := range r.LabelPreferences {
+		if err := r.LabelPreferences[i].validate(); err != nil {
+			return err
+		}
+	}
+	if len(r.LeaderPreferences) > 0 && (r.Role == Learner || r.Role == Witness) {
+		return errors.Errorf("%s rule should not have leader preferences", r.Role)
+	}
+	for i := range r.LeaderPreferences {
+		if err := r.LeaderPreferences[i].validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -170,6 +223,7 @@ func (m *RuleManager) SetRule(rule *Rule) error {
 	}
 	m.Lock()
 	defer m.Unlock()
+	rule.group = m.ruleGroups[rule.GroupID]
 	old := m.rules[rule.Key()]
 	m.rules[rule.Key()] = rule
 
@@ -221,6 +275,244 @@ func (m *RuleManager) DeleteRule(group, id string) error {
 	return nil
 }
 
+// RuleOpType indicates the operation type.
+type RuleOpType string
+
+const (
+	// RuleOpAdd adds or updates a placement rule.
+	RuleOpAdd RuleOpType = "add"
+	// RuleOpDel deletes a placement rule, only `GroupID` and `ID` of the rule
+	// are required.
+	RuleOpDel RuleOpType = "del"
+)
+
+// RuleOp is for batching placement rule actions. The action type is
+// distinguished by the field `Action`.
+type RuleOp struct {
+	*Rule                       // information of the placement rule to add/delete
+	Action           RuleOpType `json:"action"`                        // the operation type
+	DeleteByIDPrefix bool       `json:"delete_by_id_prefix,omitempty"` // if action == delete, delete by the prefix of id
+}
+
+func (r RuleOp) String() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// MaxBatchSize is the max number of the rules changed by a batch. The changes
+// are persisted in one etcd transaction, which is limited by max-txn-ops.
+const MaxBatchSize = 100
+
+// ValidationError is returned if the rule operations are rejected before
+// applying them, the rules are not changed.
+type ValidationError struct {
+	err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+// IsValidationError returns true if the error is a ValidationError.
+func IsValidationError(err error) bool {
+	_, ok := errors.Cause(err).(*ValidationError)
+	return ok
+}
+
+// Batch executes a series of actions at once. The rules are validated as a
+// whole after all actions are applied, and the changes are persisted in one
+// transaction. Nothing is changed if any step fails.
+func (m *RuleManager) Batch(todo []RuleOp) error {
+	if err := m.checkRuleOps(todo); err != nil {
+		return &ValidationError{err: err}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	rules, ruleList, err := m.applyRuleOpsLocked(todo)
+	if err != nil {
+		return &ValidationError{err: err}
+	}
+
+	toSave := make(map[string]interface{})
+	var toDelete []string
+	for k, r := range rules {
+		if m.rules[k] != r {
+			toSave[r.StoreKey()] = r
+		}
+	}
+	for k, r := range m.rules {
+		if _, ok := rules[k]; !ok {
+			toDelete = append(toDelete, r.StoreKey())
+		}
+	}
+	if n := len(toSave) + len(toDelete); n > MaxBatchSize {
+		return &ValidationError{err: errors.Errorf("too many rules changed in a batch: %d, the limit is %d", n, MaxBatchSize)}
+	}
+	if err := m.store.SaveRulesBatch(toSave, toDelete); err != nil {
+		return err
+	}
+
+	m.rules, m.ruleList = rules, ruleList
+	log.Info("placement rules updated in batch", zap.String("batch", fmt.Sprint(todo)))
+	return nil
+}
+
+// checkRuleOps validates and adjusts the rules in the operations.
+func (m *RuleManager) checkRuleOps(todo []RuleOp) error {
+	for _, t := range todo {
+		if t.Rule == nil {
+			return errors.New("rule should not be empty")
+		}
+		switch t.Action {
+		case RuleOpAdd:
+			if err := m.adjustRule(t.Rule); err != nil {
+				return err
+			}
+		case RuleOpDel:
+			if t.GroupID == "" {
+				return errors.New("group ID should not be empty")
+			}
+			if t.ID == "" && !t.DeleteByIDPrefix {
+				return errors.New("ID should not be empty")
+			}
+		default:
+			return errors.Errorf("unknown action type: %s", t.Action)
+		}
+	}
+	return nil
+}
+
+// applyRuleOpsLocked applies the operations to a copy of current rules and
+// builds the rule list from it. Current rules are not changed.
+func (m *RuleManager) applyRuleOpsLocked(todo []RuleOp) (map[[2]string]*Rule, ruleList, error) {
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	for k, r := range m.rules {
+		rules[k] = r
+	}
+	for _, t := range todo {
+		switch t.Action {
+		case RuleOpAdd:
+			t.group = m.ruleGroups[t.GroupID]
+			rules[t.Key()] = t.Rule
+		case RuleOpDel:
+			if !t.DeleteByIDPrefix {
+				delete(rules, t.Key())
+				continue
+			}
+			for k, r := range rules {
+				if r.GroupID == t.GroupID && strings.HasPrefix(r.ID, t.ID) {
+					delete(rules, k)
+				}
+			}
+		}
+	}
+
+	ruleList, err := buildRuleList(rules)
+	if err != nil {
+		return nil, ruleList, err
+	}
+	return rules, ruleList, nil
+}
+
+// GetRuleGroup returns a RuleGroup configuration.
+func (m *RuleManager) GetRuleGroup(id string) *RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	return m.ruleGroups[id]
+}
+
+// GetRuleGroups returns all RuleGroup configuration.
+func (m *RuleManager) GetRuleGroups() []*RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	groups := make([]*RuleGroup, 0, len(m.ruleGroups))
+	for _, g := range m.ruleGroups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Index != groups[j].Index {
+			return groups[i].Index < groups[j].Index
+		}
+		return groups[i].ID < groups[j].ID
+	})
+	return groups
+}
+
+// SetRuleGroup inserts or updates a RuleGroup. Setting a group with default
+// properties removes its configuration.
+func (m *RuleManager) SetRuleGroup(group *RuleGroup) error {
+	if group.ID == "" {
+		return errors.New("group ID should not be empty")
+	}
+	m.Lock()
+	defer m.Unlock()
+	if group.isDefault() {
+		return m.deleteRuleGroupLocked(group.ID)
+	}
+	old := m.ruleGroups[group.ID]
+	if err := m.updateRuleGroupLocked(group.ID, group); err != nil {
+		return err
+	}
+	if err := m.store.SaveRuleGroup(group.ID, group); err != nil {
+		m.updateRuleGroupLocked(group.ID, old)
+		return err
+	}
+	log.Info("rule group updated", zap.Stringer("group", group))
+	return nil
+}
+
+// DeleteRuleGroup removes a RuleGroup configuration. Rules of the group are
+// kept and are applied with default group properties afterwards.
+func (m *RuleManager) DeleteRuleGroup(id string) error {
+	m.Lock()
+	defer m.Unlock()
+	return m.deleteRuleGroupLocked(id)
+}
+
+func (m *RuleManager) deleteRuleGroupLocked(id string) error {
+	old, ok := m.ruleGroups[id]
+	if !ok {
+		return nil
+	}
+	if err := m.updateRuleGroupLocked(id, nil); err != nil {
+		return err
+	}
+	if err := m.store.DeleteRuleGroup(id); err != nil {
+		m.updateRuleGroupLocked(id, old)
+		return err
+	}
+	log.Info("rule group removed", zap.Stringer("group", old))
+	return nil
+}
+
+// updateRuleGroupLocked replaces the group config in memory and rebuilds the
+// rule list. Rules of the group are copied before binding to the new config
+// because the old ones may still be referenced by the callers.
+func (m *RuleManager) updateRuleGroupLocked(id string, group *RuleGroup) error {
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	for k, r := range m.rules {
+		if r.GroupID == id {
+			cloned := *r
+			cloned.group = group
+			r = &cloned
+		}
+		rules[k] = r
+	}
+	ruleList, err := buildRuleList(rules)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		delete(m.ruleGroups, id)
+	} else {
+		m.ruleGroups[id] = group
+	}
+	m.rules, m.ruleList = rules, ruleList
+	return nil
+}
+
 // GetSplitKeys returns all split keys in the range (start, end).
 func (m *RuleManager) GetSplitKeys(start, end []byte) [][]byte {
 	m.RLock()
@@ -15,6 +15,7 @@ package placement
 
 import (
 	"encoding/hex"
+	"strconv"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -61,6 +62,8 @@ func (s *testManagerSuite) TestAdjustRule(c *C) {
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 0},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: -1},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, LabelConstraints: []LabelConstraint{{Op: "foo"}}},
+		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, LabelPreferences: []LabelPreference{{Key: "zone"}}},
+		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "learner", Count: 3, LeaderPreferences: []LabelPreference{{Key: "zone", Values: []string{"z1"}}}},
 	}
 	c.Assert(s.manager.adjustRule(&rules[0]), IsNil)
 	c.Assert(rules[0].StartKey, DeepEquals, []byte{0x12, 0x3a, 0xbc})
@@ -184,6 +187,200 @@ func (s *testManagerSuite) TestRangeGap(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testManagerSuite) TestBatch(c *C) {
+	testCases := []struct {
+		opt    []RuleOp
+		expect []Rule
+	}{
+		{
+			opt: []RuleOp{
+				{Action: RuleOpAdd, Rule: &Rule{GroupID: "a", ID: "12", Role: "voter", Count: 1}},
+				{Action: RuleOpAdd, Rule: &Rule{GroupID: "a", ID: "13", Role: "voter", Count: 1}},
+				{Action: RuleOpAdd, Rule: &Rule{GroupID: "a", ID: "14", Role: "voter", Count: 1}},
+				{Action: RuleOpAdd, Rule: &Rule{GroupID: "b", ID: "1", Role: "voter", Count: 1}},
+			},
+			expect: []Rule{
+				{GroupID: "a", ID: "12", Role: "voter", Count: 1},
+				{GroupID: "a", ID: "13", Role: "voter", Count: 1},
+				{GroupID: "a", ID: "14", Role: "voter", Count: 1},
+				{GroupID: "b", ID: "1", Role: "voter", Count: 1},
+				{GroupID: "pd", ID: "default", Role: "voter", Count: 3},
+			},
+		},
+		{
+			opt: []RuleOp{
+				{Action: RuleOpDel, Rule: &Rule{GroupID: "a", ID: "12"}},
+				{Action: RuleOpDel, Rule: &Rule{GroupID: "b", ID: "1"}},
+			},
+			expect: []Rule{
+				{GroupID: "a", ID: "13", Role: "voter", Count: 1},
+				{GroupID: "a", ID: "14", Role: "voter", Count: 1},
+				{GroupID: "pd", ID: "default", Role: "voter", Count: 3},
+			},
+		},
+		{
+			opt: []RuleOp{
+				{Action: RuleOpDel, Rule: &Rule{GroupID: "a", ID: "1"}, DeleteByIDPrefix: true},
+				{Action: RuleOpAdd, Rule: &Rule{GroupID: "a", ID: "100", Role: "voter", Count: 1}},
+				{Action: RuleOpDel, Rule: &Rule{GroupID: "pd", ID: "default"}},
+			},
+			expect: []Rule{
+				{GroupID: "a", ID: "100", Role: "voter", Count: 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := s.manager.Batch(testCase.opt)
+		c.Assert(err, IsNil)
+		rules := s.manager.GetAllRules()
+		c.Assert(rules, HasLen, len(testCase.expect))
+		for i, r := range rules {
+			c.Assert(r.Key(), Equals, testCase.expect[i].Key())
+			c.Assert(r.Count, Equals, testCase.expect[i].Count)
+		}
+		m2 := NewRuleManager(s.store)
+		err = m2.Initialize(3, []string{"zone", "rack", "host"})
+		c.Assert(err, IsNil)
+		c.Assert(m2.GetAllRules(), DeepEquals, rules)
+	}
+
+	// failed batch leaves rules unchanged.
+	badCases := [][]RuleOp{
+		{
+			{Action: RuleOpAdd, Rule: &Rule{GroupID: "a", ID: "101", Role: "voter", Count: 1}},
+			{Action: RuleOpAdd, Rule: &Rule{GroupID: "a", ID: "102", Role: "voter", Count: 0}},
+		},
+		{
+			{Action: RuleOpAdd, Rule: &Rule{GroupID: "a", ID: "101", Role: "voter", Count: 1, EndKeyHex: "abcd"}},
+			{Action: RuleOpDel, Rule: &Rule{GroupID: "a", ID: "100"}},
+		},
+		{
+			{Action: "foo", Rule: &Rule{GroupID: "a", ID: "100"}},
+		},
+	}
+	var tooMany []RuleOp
+	for i := 0; i <= MaxBatchSize; i++ {
+		tooMany = append(tooMany, RuleOp{Action: RuleOpAdd, Rule: &Rule{GroupID: "b", ID: strconv.Itoa(i), Role: "voter", Count: 1}})
+	}
+	badCases = append(badCases, tooMany)
+	for _, opt := range badCases {
+		err := s.manager.Batch(opt)
+		c.Assert(IsValidationError(err), IsTrue)
+		rules := s.manager.GetAllRules()
+		c.Assert(rules, HasLen, 1)
+		c.Assert(rules[0].Key(), Equals, [2]string{"a", "100"})
+	}
+}
+
+func (s *testManagerSuite) TestGroupConfig(c *C) {
+	pd1 := &Rule{GroupID: "pd", ID: "default"}
+	c.Assert(s.manager.GetRuleGroups(), HasLen, 0)
+	c.Assert(s.manager.GetRuleGroup("pd"), IsNil)
+
+	// add a rule to group "g", both groups use default properties.
+	err := s.manager.SetRule(&Rule{GroupID: "g", ID: "1", Role: "voter", Count: 1})
+	c.Assert(err, IsNil)
+	g1 := &Rule{GroupID: "g", ID: "1"}
+	s.checkApplyRules(c, g1, pd1)
+
+	// move group "g" after group "pd".
+	err = s.manager.SetRuleGroup(&RuleGroup{ID: "g", Index: 1})
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetRuleGroup("g"), DeepEquals, &RuleGroup{ID: "g", Index: 1})
+	s.checkApplyRules(c, pd1, g1)
+
+	// group "pd" overrides group "g".
+	err = s.manager.SetRuleGroup(&RuleGroup{ID: "pd", Index: 2, Override: true})
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetRuleGroups(), HasLen, 2)
+	s.checkApplyRules(c, pd1)
+
+	// group "g" overrides group "pd".
+	err = s.manager.SetRuleGroup(&RuleGroup{ID: "g", Index: 3, Override: true})
+	c.Assert(err, IsNil)
+	s.checkApplyRules(c, g1)
+
+	// reload from storage.
+	m2 := NewRuleManager(s.store)
+	err = m2.Initialize(3, []string{"zone", "rack", "host"})
+	c.Assert(err, IsNil)
+	c.Assert(m2.GetRuleGroups(), DeepEquals, s.manager.GetRuleGroups())
+	rules := m2.GetRulesForApplyRegion(core.NewRegionInfo(&metapb.Region{}, nil))
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Key(), Equals, g1.Key())
+
+	// set group "pd" to default, and delete group "g".
+	err = s.manager.SetRuleGroup(&RuleGroup{ID: "pd"})
+	c.Assert(err, IsNil)
+	err = s.manager.DeleteRuleGroup("g")
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetRuleGroups(), HasLen, 0)
+	s.checkApplyRules(c, g1, pd1)
+	c.Assert(s.manager.SetRuleGroup(&RuleGroup{}), NotNil)
+}
+
+func (s *testManagerSuite) TestPlan(c *C) {
+	stores := core.NewBasicCluster()
+	for id, zone := range map[uint64]string{1: "z1", 2: "z2", 3: "z3", 4: "z4", 5: "z4"} {
+		stores.PutStore(core.NewStoreInfoWithLabel(id, 10, map[string]string{"zone": zone}))
+	}
+	var regions []*core.RegionInfo
+	for id := uint64(1); id <= 2; id++ {
+		regions = append(regions, core.NewRegionInfo(&metapb.Region{Id: id, Peers: []*metapb.Peer{
+			{Id: id*10 + 1, StoreId: 1}, {Id: id*10 + 2, StoreId: 2}, {Id: id*10 + 3, StoreId: 3},
+		}}, &metapb.Peer{Id: id*10 + 1, StoreId: 1}))
+	}
+
+	plan, err := s.manager.Plan([]RuleOp{
+		{Rule: &Rule{GroupID: "pd", ID: "default", Role: "voter", Count: 2, LocationLabels: []string{"zone"}}, Action: RuleOpAdd},
+		{Rule: &Rule{GroupID: "pd", ID: "learner", Role: "learner", Count: 1, LabelConstraints: []LabelConstraint{{Key: "zone", Op: "in", Values: []string{"z4"}}}}, Action: RuleOpAdd},
+	})
+	c.Assert(err, IsNil)
+	// current rules are not changed.
+	c.Assert(s.manager.GetAllRules(), HasLen, 1)
+	c.Assert(s.manager.GetRule("pd", "default").Count, Equals, 3)
+
+	impact := s.manager.EstimateImpact(stores, regions, plan)
+	c.Assert(impact.RegionCount, Equals, 2)
+	c.Assert(impact.AffectedCount, Equals, 2)
+	c.Assert(impact.NewUnsatisfiedCount, Equals, 2)
+	c.Assert(impact.NewOverSatisfiedCount, Equals, 2)
+	c.Assert(impact.SplitCount, Equals, 0)
+	c.Assert(impact.UnplaceablePeers, Equals, 0)
+	c.Assert(impact.SampleRegions, DeepEquals, []uint64{1, 2})
+	// new learners are spread to both stores in z4.
+	c.Assert(impact.PeerAdditions, DeepEquals, map[uint64]int{4: 1, 5: 1})
+	var removals int
+	for _, n := range impact.PeerRemovals {
+		removals += n
+	}
+	c.Assert(removals, Equals, 2)
+
+	// no store can hold peers of the plan.
+	plan, err = s.manager.Plan([]RuleOp{
+		{Rule: &Rule{GroupID: "pd", ID: "learner", Role: "learner", Count: 1, LabelConstraints: []LabelConstraint{{Key: "zone", Op: "in", Values: []string{"z5"}}}}, Action: RuleOpAdd},
+	})
+	c.Assert(err, IsNil)
+	impact = s.manager.EstimateImpact(stores, regions, plan)
+	c.Assert(impact.AffectedCount, Equals, 2)
+	c.Assert(impact.NewOverSatisfiedCount, Equals, 0)
+	c.Assert(impact.UnplaceablePeers, Equals, 2)
+	c.Assert(impact.PeerAdditions, HasLen, 0)
+
+	// invalid operations.
+	_, err = s.manager.Plan([]RuleOp{{Rule: &Rule{GroupID: "pd", ID: "default", Role: "voter", Count: 0}, Action: RuleOpAdd}})
+	c.Assert(err, NotNil)
+}
+
+func (s *testManagerSuite) checkApplyRules(c *C, expect ...*Rule) {
+	rules := s.manager.GetRulesForApplyRegion(core.NewRegionInfo(&metapb.Region{}, nil))
+	c.Assert(rules, HasLen, len(expect))
+	for i := range rules {
+		c.Assert(rules[i].Key(), Equals, expect[i].Key())
+	}
+}
+
 func (s *testManagerSuite) dhex(hk string) []byte {
 	k, err := hex.DecodeString(hk)
 	if err != nil {
@@ -14,8 +14,11 @@
 package schedule
 
 import (
+	"math"
 	"math/rand"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/log"
@@ -29,6 +32,10 @@ import (
 
 const regionScatterName = "region-scatter"
 
+// scatterGroupTTL is how long the distribution of a scatter group is kept
+// after the group is used last time.
+var scatterGroupTTL = 10 * time.Minute
+
 type selectedStores struct {
 	mu     sync.Mutex
 	stores map[uint64]struct{}
@@ -66,12 +73,53 @@ func (s *selectedStores) newFilter(scope string) filter.Filter {
 	return filter.NewExcludedFilter(scope, nil, cloned)
 }
 
+// groupDistribution records the peers and leaders selected for the regions
+// of a scatter group on each store.
+type groupDistribution struct {
+	peers    map[uint64]uint64
+	leaders  map[uint64]uint64
+	lastUsed time.Time
+}
+
+type scatterGroups struct {
+	sync.Mutex
+	groups map[string]*groupDistribution
+}
+
+func newScatterGroups() *scatterGroups {
+	return &scatterGroups{
+		groups: make(map[string]*groupDistribution),
+	}
+}
+
+// get returns the distribution of the group and cleans up the stale groups.
+// It should be called with the lock held.
+func (g *scatterGroups) get(group string) *groupDistribution {
+	now := time.Now()
+	for name, d := range g.groups {
+		if now.Sub(d.lastUsed) > scatterGroupTTL {
+			delete(g.groups, name)
+		}
+	}
+	d, ok := g.groups[group]
+	if !ok {
+		d = &groupDistribution{
+			peers:   make(map[uint64]uint64),
+			leaders: make(map[uint64]uint64),
+		}
+		g.groups[group] = d
+	}
+	d.lastUsed = now
+	return d
+}
+
 // RegionScatterer scatters regions.
 type RegionScatterer struct {
 	name           string
 	cluster        opt.Cluster
 	ordinaryEngine engineContext
 	specialEngines map[string]engineContext
+	groups         *scatterGroups
 }
 
 // NewRegionScatterer creates a region scatterer.
@@ -82,6 +130,7 @@ func NewRegionScatterer(cluster opt.Cluster) *RegionScatterer {
 		cluster:        cluster,
 		ordinaryEngine: newEngineContext(filter.NewOrdinaryEngineFilter(regionScatterName)),
 		specialEngines: make(map[string]engineContext),
+		groups:         newScatterGroups(),
 	}
 }
 
@@ -100,22 +149,46 @@ func newEngineContext(filters ...filter.Filter) engineContext {
 
 // Scatter relocates the region.
 func (r *RegionScatterer) Scatter(region *core.RegionInfo) (*operator.Operator, error) {
-	if !opt.IsRegionReplicated(r.cluster, region) {
-		return nil, errors.Errorf("region %d is not fully replicated", region.GetID())
+	if err := r.checkRegion(region); err != nil {
+		return nil, err
 	}
+	return r.scatterRegion(region), nil
+}
 
-	if region.GetLeader() == nil {
-		return nil, errors.Errorf("region %d has no leader", region.GetID())
+// ScatterWithGroup relocates the region and balances both the peers and the
+// leaders among the regions scattered with the same group. The operator is
+// passed to addOp, and the distribution of the group is only updated if the
+// region stays or addOp succeeds, so a rejected operator does not skew the
+// following regions of the group. It is the same as Scatter if the group is
+// empty.
+func (r *RegionScatterer) ScatterWithGroup(region *core.RegionInfo, group string, addOp func(*operator.Operator) error) error {
+	if err := r.checkRegion(region); err != nil {
+		return err
 	}
+	if group == "" {
+		if op := r.scatterRegion(region); op != nil {
+			return addOp(op)
+		}
+		return nil
+	}
+	return r.scatterRegionWithGroup(region, group, addOp)
+}
 
-	return r.scatterRegion(region), nil
+func (r *RegionScatterer) checkRegion(region *core.RegionInfo) error {
+	if !opt.IsRegionReplicated(r.cluster, region) {
+		return errors.Errorf("region %d is not fully replicated", region.GetID())
+	}
+	if region.GetLeader() == nil {
+		return errors.Errorf("region %d has no leader", region.GetID())
+	}
+	return nil
 }
 
-func (r *RegionScatterer) scatterRegion(region *core.RegionInfo) *operator.Operator {
+// groupPeersByEngine groups peers by the engine of their stores.
+func (r *RegionScatterer) groupPeersByEngine(region *core.RegionInfo) ([]*metapb.Peer, map[string][]*metapb.Peer) {
 	ordinaryFilter := filter.NewOrdinaryEngineFilter(r.name)
 	var ordinaryPeers []*metapb.Peer
 	specialPeers := make(map[string][]*metapb.Peer)
-	// Group peers by the engine of their stores
 	for _, peer := range region.GetPeers() {
 		store := r.cluster.GetStore(peer.GetStoreId())
 		if ordinaryFilter.Target(r.cluster, store) {
@@ -125,6 +198,20 @@ func (r *RegionScatterer) scatterRegion(region *core.RegionInfo) *operator.Opera
 			specialPeers[engine] = append(specialPeers[engine], peer)
 		}
 	}
+	return ordinaryPeers, specialPeers
+}
+
+func (r *RegionScatterer) getSpecialEngine(engine string) engineContext {
+	context, ok := r.specialEngines[engine]
+	if !ok {
+		context = newEngineContext(filter.NewEngineFilter(r.name, engine))
+		r.specialEngines[engine] = context
+	}
+	return context
+}
+
+func (r *RegionScatterer) scatterRegion(region *core.RegionInfo) *operator.Operator {
+	ordinaryPeers, specialPeers := r.groupPeersByEngine(region)
 
 	targetPeers := make(map[uint64]*metapb.Peer)
 
@@ -154,15 +241,10 @@ func (r *RegionScatterer) scatterRegion(region *core.RegionInfo) *operator.Opera
 
 	scatterWithSameEngine(ordinaryPeers, r.ordinaryEngine)
 	for engine, peers := range specialPeers {
-		context, ok := r.specialEngines[engine]
-		if !ok {
-			context = newEngineContext(filter.NewEngineFilter(r.name, engine))
-			r.specialEngines[engine] = context
-		}
-		scatterWithSameEngine(peers, context)
+		scatterWithSameEngine(peers, r.getSpecialEngine(engine))
 	}
 
-	op, err := operator.CreateScatterRegionOperator("scatter-region", r.cluster, region, targetPeers)
+	op, err := operator.CreateScatterRegionOperator("scatter-region", r.cluster, region, targetPeers, 0)
 	if err != nil {
 		log.Debug("fail to create scatter region operator", zap.Error(err))
 		return nil
@@ -171,21 +253,133 @@ func (r *RegionScatterer) scatterRegion(region *core.RegionInfo) *operator.Opera
 	return op
 }
 
-func (r *RegionScatterer) selectPeerToReplace(stores map[uint64]*core.StoreInfo, region *core.RegionInfo, oldPeer *metapb.Peer) *metapb.Peer {
-	// scoreGuard guarantees that the distinct score will not decrease.
+// scatterRegionWithGroup moves each peer to the store with the fewest peers
+// of the group, then picks the store with the fewest leaders of the group as
+// the leader.
+func (r *RegionScatterer) scatterRegionWithGroup(region *core.RegionInfo, group string, addOp func(*operator.Operator) error) error {
+	r.groups.Lock()
+	defer r.groups.Unlock()
+	dist := r.groups.get(group)
+
+	ordinaryPeers, specialPeers := r.groupPeersByEngine(region)
+	targetPeers := make(map[uint64]*metapb.Peer)
+	scatterWithSameEngine := func(peers []*metapb.Peer, context engineContext) {
+		stores := r.collectGroupCandidates(region, context)
+		for _, peer := range peers {
+			storeID := r.selectStoreInGroup(dist, stores, region, peer)
+			delete(stores, storeID)
+			if storeID == peer.GetStoreId() {
+				targetPeers[storeID] = peer
+				continue
+			}
+			targetPeers[storeID] = &metapb.Peer{
+				StoreId:   storeID,
+				IsLearner: peer.GetIsLearner(),
+			}
+		}
+	}
+	scatterWithSameEngine(ordinaryPeers, r.ordinaryEngine)
+	for engine, peers := range specialPeers {
+		scatterWithSameEngine(peers, r.getSpecialEngine(engine))
+	}
+
+	leader := selectLeaderInGroup(dist, targetPeers, region.GetLeader().GetStoreId())
+	if !isScatterUnchanged(region, targetPeers, leader) {
+		op, err := operator.CreateScatterRegionOperator("scatter-region", r.cluster, region, targetPeers, leader)
+		if err != nil {
+			log.Debug("fail to create scatter region operator", zap.String("group", group), zap.Error(err))
+			return nil
+		}
+		op.SetPriorityLevel(core.HighPriority)
+		if err := addOp(op); err != nil {
+			return err
+		}
+	}
+	// the region is counted even if it stays, so the following regions of
+	// the group avoid its stores.
+	for storeID := range targetPeers {
+		dist.peers[storeID]++
+	}
+	dist.leaders[leader]++
+	return nil
+}
+
+func isScatterUnchanged(region *core.RegionInfo, targetPeers map[uint64]*metapb.Peer, leader uint64) bool {
+	if leader != region.GetLeader().GetStoreId() || len(targetPeers) != len(region.GetPeers()) {
+		return false
+	}
+	for storeID, peer := range targetPeers {
+		old := region.GetStorePeer(storeID)
+		if old == nil || old.GetIsLearner() != peer.GetIsLearner() {
+			return false
+		}
+	}
+	return true
+}
+
+// selectStoreInGroup returns the store for the peer. The peer stays if no
+// candidate has fewer peers of the group than its current store.
+func (r *RegionScatterer) selectStoreInGroup(dist *groupDistribution, stores map[uint64]*core.StoreInfo, region *core.RegionInfo, peer *metapb.Peer) uint64 {
+	storeID := peer.GetStoreId()
+	scoreGuard := r.newScoreGuard(region, peer)
+	minCount := dist.peers[storeID]
+	var candidates []uint64
+	for id, store := range stores {
+		count := dist.peers[id]
+		if count > minCount || !scoreGuard.Target(r.cluster, store) {
+			continue
+		}
+		if count < minCount {
+			minCount, candidates = count, candidates[:0]
+		}
+		candidates = append(candidates, id)
+	}
+	if len(candidates) == 0 || minCount == dist.peers[storeID] {
+		return storeID
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// selectLeaderInGroup returns the voter store with the fewest leaders of the
+// group, the current leader is kept on ties.
+func selectLeaderInGroup(dist *groupDistribution, targetPeers map[uint64]*metapb.Peer, oldLeader uint64) uint64 {
+	var leader uint64
+	minCount := uint64(math.MaxUint64)
+	if p, ok := targetPeers[oldLeader]; ok && !p.GetIsLearner() {
+		leader, minCount = oldLeader, dist.leaders[oldLeader]
+	}
+	ids := make([]uint64, 0, len(targetPeers))
+	for id, p := range targetPeers {
+		if !p.GetIsLearner() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if count := dist.leaders[id]; count < minCount {
+			leader, minCount = id, count
+		}
+	}
+	return leader
+}
+
+// newScoreGuard returns a filter that guarantees the distinct score will not
+// decrease after replacing the old peer.
+func (r *RegionScatterer) newScoreGuard(region *core.RegionInfo, oldPeer *metapb.Peer) filter.Filter {
 	regionStores := r.cluster.GetRegionStores(region)
 	storeID := oldPeer.GetStoreId()
 	sourceStore := r.cluster.GetStore(storeID)
 	if sourceStore == nil {
 		log.Error("failed to get the store", zap.Uint64("store-id", storeID))
 	}
-	var scoreGuard filter.Filter
 	if r.cluster.IsPlacementRulesEnabled() {
-		scoreGuard = filter.NewRuleFitFilter(r.name, r.cluster, region, oldPeer.GetStoreId())
-	} else {
-		scoreGuard = filter.NewLocationSafeguard(r.name, r.cluster.GetLocationLabels(), regionStores, sourceStore)
+		return filter.NewRuleFitFilter(r.name, r.cluster, region, oldPeer.GetStoreId())
 	}
+	return filter.NewLocationSafeguard(r.name, r.cluster.GetLocationLabels(), regionStores, sourceStore)
+}
 
+func (r *RegionScatterer) selectPeerToReplace(stores map[uint64]*core.StoreInfo, region *core.RegionInfo, oldPeer *metapb.Peer) *metapb.Peer {
+	scoreGuard := r.newScoreGuard(region, oldPeer)
 	candidates := make([]*core.StoreInfo, 0, len(stores))
 	for _, store := range stores {
 		if !scoreGuard.Target(r.cluster, store) {
@@ -221,3 +415,22 @@ func (r *RegionScatterer) collectAvailableStores(region *core.RegionInfo, contex
 	}
 	return targets
 }
+
+// collectGroupCandidates returns the stores which can hold a new peer of the
+// region, the selected stores of the regions scattered without a group are
+// not excluded.
+func (r *RegionScatterer) collectGroupCandidates(region *core.RegionInfo, context engineContext) map[uint64]*core.StoreInfo {
+	filters := []filter.Filter{
+		filter.NewExcludedFilter(r.name, nil, region.GetStoreIds()),
+	}
+	filters = append(filters, context.filters...)
+
+	stores := r.cluster.GetStores()
+	targets := make(map[uint64]*core.StoreInfo, len(stores))
+	for _, store := range stores {
+		if filter.Target(r.cluster, store, filters) && !store.IsBusy() {
+			targets[store.GetID()] = store
+		}
+	}
+	return targets
+}
@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"context"
+	"errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/v4/pkg/mock/mockhbstream"
@@ -176,6 +177,69 @@ func (s *testScatterRegionSuite) scatterSpecial(c *C, numOrdinaryStores, numSpec
 	}
 }
 
+func (s *testScatterRegionSuite) TestScatterGroup(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	// The regions of a freshly split table are all on the same stores.
+	for i := uint64(1); i <= 12; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3)
+	}
+
+	scatterer := NewRegionScatterer(tc)
+	applyOp := func(op *operator.Operator) error {
+		s.checkOperator(op, c)
+		ApplyOperator(tc, op)
+		return nil
+	}
+	// The first region of a group stays and is counted.
+	c.Assert(scatterer.ScatterWithGroup(tc.GetRegion(1), "table-1", applyOp), IsNil)
+	expectPeers := map[uint64]uint64{1: 1, 2: 1, 3: 1}
+	expectLeaders := map[uint64]uint64{1: 1}
+	c.Assert(scatterer.groups.get("table-1").peers, DeepEquals, expectPeers)
+	c.Assert(scatterer.groups.get("table-1").leaders, DeepEquals, expectLeaders)
+
+	// A rejected operator does not change the distribution of the group.
+	rejected := errors.New("rejected")
+	err := scatterer.ScatterWithGroup(tc.GetRegion(2), "table-1", func(op *operator.Operator) error {
+		return rejected
+	})
+	c.Assert(err, Equals, rejected)
+	c.Assert(scatterer.groups.get("table-1").peers, DeepEquals, expectPeers)
+	c.Assert(scatterer.groups.get("table-1").leaders, DeepEquals, expectLeaders)
+
+	for i := uint64(2); i <= 12; i++ {
+		c.Assert(scatterer.ScatterWithGroup(tc.GetRegion(i), "table-1", applyOp), IsNil)
+	}
+
+	countPeers := make(map[uint64]int)
+	countLeaders := make(map[uint64]int)
+	for i := uint64(1); i <= 12; i++ {
+		region := tc.GetRegion(i)
+		for _, peer := range region.GetPeers() {
+			countPeers[peer.GetStoreId()]++
+		}
+		countLeaders[region.GetLeader().GetStoreId()]++
+	}
+	// Both peers and leaders are balanced within the group.
+	c.Assert(countPeers, HasLen, 6)
+	c.Assert(countLeaders, HasLen, 6)
+	for storeID := uint64(1); storeID <= 6; storeID++ {
+		c.Assert(countPeers[storeID], Equals, 6)
+		c.Assert(countLeaders[storeID], Equals, 2)
+	}
+
+	// Another group is balanced independently, so its first region stays.
+	tc.AddLeaderRegion(13, 1, 2, 3)
+	err = scatterer.ScatterWithGroup(tc.GetRegion(13), "table-2", func(op *operator.Operator) error {
+		c.Fatalf("unexpected operator %s", op)
+		return nil
+	})
+	c.Assert(err, IsNil)
+}
+
 func (s *testScatterRegionSuite) TestStoreLimit(c *C) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/juju/ratelimit"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -28,15 +29,19 @@ const (
 
 // RegionInfluence represents the influence of a operator step, which is used by store limit.
 var RegionInfluence = map[Type]int64{
-	AddPeer:    1000,
-	RemovePeer: 1000,
+	AddPeer:         1000,
+	RemovePeer:      1000,
+	SendSnapshot:    1000,
+	ReceiveSnapshot: 1000,
 }
 
 // SmallRegionInfluence represents the influence of a operator step
 // when the region size is smaller than smallRegionThreshold, which is used by store limit.
 var SmallRegionInfluence = map[Type]int64{
-	AddPeer:    200,
-	RemovePeer: 200,
+	AddPeer:         200,
+	RemovePeer:      200,
+	SendSnapshot:    200,
+	ReceiveSnapshot: 200,
 }
 
 // Type indicates the type of store limit
@@ -47,12 +52,20 @@ const (
 	AddPeer Type = iota
 	// RemovePeer indicates the type of store limit that limits the removing peer rate
 	RemovePeer
+	// SendSnapshot indicates the type of store limit that limits the rate of
+	// generating and sending snapshots, which is charged on the leader store
+	SendSnapshot
+	// ReceiveSnapshot indicates the type of store limit that limits the rate of
+	// receiving snapshots
+	ReceiveSnapshot
 )
 
 // TypeNameValue indicates the name of store limit type and the enum value
 var TypeNameValue = map[string]Type{
-	"add-peer":    AddPeer,
-	"remove-peer": RemovePeer,
+	"add-peer":         AddPeer,
+	"remove-peer":      RemovePeer,
+	"send-snapshot":    SendSnapshot,
+	"receive-snapshot": ReceiveSnapshot,
 }
 
 // String returns the representation of the Type
@@ -65,15 +78,68 @@ func (t Type) String() string {
 	return ""
 }
 
+// Algorithm indicates the algorithm used by a store limit.
+type Algorithm string
+
+const (
+	// TokenBucket charges a fixed cost for each operator step from a token
+	// bucket, only small regions are charged less.
+	TokenBucket Algorithm = "token-bucket"
+	// Adaptive charges the region size in bytes for each operator step, and
+	// slows down the fill rate when the store has too many pending snapshots.
+	Adaptive Algorithm = "adaptive"
+)
+
+// ParseAlgorithm parses the name of a store limit algorithm. An empty name
+// means the default algorithm.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch Algorithm(name) {
+	case "", TokenBucket:
+		return TokenBucket, nil
+	case Adaptive:
+		return Adaptive, nil
+	default:
+		return "", errors.Errorf("unknown store limit algorithm %s", name)
+	}
+}
+
 // StoreLimit limits the operators of a store
-type StoreLimit struct {
+type StoreLimit interface {
+	// Algorithm returns the algorithm of the limit.
+	Algorithm() Algorithm
+	// Available returns the number of available tokens.
+	Available() int64
+	// Rate returns the configured rate of the limit, in operators per second.
+	Rate() float64
+	// Allow returns if there are enough tokens for the given cost.
+	Allow(cost int64) bool
+	// Take takes count tokens without blocking.
+	Take(count int64) time.Duration
+	// RegionCost returns the tokens cost by a step of a normal region. A store
+	// is regarded as available only if it allows such a step.
+	RegionCost() int64
+	// Cost returns the tokens cost by steps, given their token bucket cost
+	// and the total size (MB) of their regions.
+	Cost(stepCost, stepSize int64) int64
+	// Adjust adjusts the limit according to the pending snapshots of the store.
+	Adjust(pendingSnapshots, maxSnapshots uint64)
+}
+
+// NewStoreLimit returns a StoreLimit object with the given algorithm.
+func NewStoreLimit(algorithm Algorithm, ratePerSec float64, limitType Type) StoreLimit {
+	if algorithm == Adaptive {
+		return newAdaptiveLimit(ratePerSec)
+	}
+	return newTokenBucketLimit(ratePerSec, RegionInfluence[limitType])
+}
+
+type tokenBucketLimit struct {
 	bucket          *ratelimit.Bucket
 	regionInfluence int64
 	ratePerSec      float64
 }
 
-// NewStoreLimit returns a StoreLimit object
-func NewStoreLimit(ratePerSec float64, regionInfluence int64) *StoreLimit {
+func newTokenBucketLimit(ratePerSec float64, regionInfluence int64) *tokenBucketLimit {
 	capacity := regionInfluence
 	rate := ratePerSec
 	// unlimited
@@ -85,24 +151,39 @@ func NewStoreLimit(ratePerSec float64, regionInfluence int64) *StoreLimit {
 	} else {
 		ratePerSec *= float64(regionInfluence)
 	}
-	return &StoreLimit{
+	return &tokenBucketLimit{
 		bucket:          ratelimit.NewBucketWithRate(ratePerSec, capacity),
 		regionInfluence: regionInfluence,
 		ratePerSec:      rate,
 	}
 }
 
-// Available returns the number of available tokens
-func (l *StoreLimit) Available() int64 {
+func (l *tokenBucketLimit) Algorithm() Algorithm {
+	return TokenBucket
+}
+
+func (l *tokenBucketLimit) Available() int64 {
 	return l.bucket.Available()
 }
 
-// Rate returns the fill rate of the bucket, in tokens per second.
-func (l *StoreLimit) Rate() float64 {
+func (l *tokenBucketLimit) Rate() float64 {
 	return l.ratePerSec
 }
 
-// Take takes count tokens from the bucket without blocking.
-func (l *StoreLimit) Take(count int64) time.Duration {
+func (l *tokenBucketLimit) Allow(cost int64) bool {
+	return l.bucket.Available() >= cost
+}
+
+func (l *tokenBucketLimit) Take(count int64) time.Duration {
 	return l.bucket.Take(count)
 }
+
+func (l *tokenBucketLimit) RegionCost() int64 {
+	return l.regionInfluence
+}
+
+func (l *tokenBucketLimit) Cost(stepCost, stepSize int64) int64 {
+	return stepCost
+}
+
+func (l *tokenBucketLimit) Adjust(pendingSnapshots, maxSnapshots uint64) {}
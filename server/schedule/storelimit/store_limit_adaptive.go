@@ -0,0 +1,118 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RegionBytes is the size of a normal region in bytes. The adaptive limit
+// converts the configured rate (in operators) to bytes with it.
+const RegionBytes int64 = 96 << 20
+
+// adaptiveLimit is a token bucket in bytes. Each step is charged by the size
+// of its region, and the fill rate is reduced in proportion when the store
+// has more pending snapshots than expected.
+type adaptiveLimit struct {
+	sync.Mutex
+	ratePerSec float64 // operators per second
+	capacity   float64 // bytes
+	available  float64 // bytes, can be negative after a large step
+	factor     float64 // the ratio of the actual fill rate, in (0, 1]
+	lastFill   time.Time
+}
+
+func newAdaptiveLimit(ratePerSec float64) *adaptiveLimit {
+	capacity := float64(RegionBytes)
+	if ratePerSec >= Unlimited {
+		capacity = Unlimited * float64(RegionBytes)
+	} else if ratePerSec > 1 {
+		capacity = ratePerSec * float64(RegionBytes)
+	}
+	return &adaptiveLimit{
+		ratePerSec: ratePerSec,
+		capacity:   capacity,
+		available:  capacity,
+		factor:     1,
+		lastFill:   time.Now(),
+	}
+}
+
+// fillRate returns the actual fill rate in bytes per second.
+func (l *adaptiveLimit) fillRate() float64 {
+	return l.ratePerSec * float64(RegionBytes) * l.factor
+}
+
+func (l *adaptiveLimit) fill() {
+	now := time.Now()
+	l.available = math.Min(l.capacity, l.available+now.Sub(l.lastFill).Seconds()*l.fillRate())
+	l.lastFill = now
+}
+
+func (l *adaptiveLimit) Algorithm() Algorithm {
+	return Adaptive
+}
+
+func (l *adaptiveLimit) Available() int64 {
+	l.Lock()
+	defer l.Unlock()
+	l.fill()
+	return int64(l.available)
+}
+
+func (l *adaptiveLimit) Rate() float64 {
+	return l.ratePerSec
+}
+
+// Allow allows a step larger than the capacity once the bucket is full,
+// otherwise such a step can never be scheduled.
+func (l *adaptiveLimit) Allow(cost int64) bool {
+	l.Lock()
+	defer l.Unlock()
+	l.fill()
+	return l.available >= math.Min(float64(cost), l.capacity)
+}
+
+func (l *adaptiveLimit) Take(count int64) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+	l.fill()
+	l.available -= float64(count)
+	if l.available >= 0 || l.fillRate() <= 0 {
+		return 0
+	}
+	return time.Duration(-l.available / l.fillRate() * float64(time.Second))
+}
+
+func (l *adaptiveLimit) RegionCost() int64 {
+	return RegionBytes
+}
+
+func (l *adaptiveLimit) Cost(stepCost, stepSize int64) int64 {
+	return stepSize << 20
+}
+
+func (l *adaptiveLimit) Adjust(pendingSnapshots, maxSnapshots uint64) {
+	l.Lock()
+	defer l.Unlock()
+	// settle the tokens with the previous rate before changing it.
+	l.fill()
+	if maxSnapshots == 0 || pendingSnapshots <= maxSnapshots {
+		l.factor = 1
+		return
+	}
+	l.factor = float64(maxSnapshots) / float64(pendingSnapshots)
+}
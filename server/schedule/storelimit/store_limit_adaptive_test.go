@@ -0,0 +1,141 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storelimit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testAdaptiveLimitSuite{})
+
+type testAdaptiveLimitSuite struct{}
+
+// tolerance covers the tokens filled between setting up a case and checking
+// it, about 10ms at one region per second.
+const tolerance = float64(RegionBytes) / 100
+
+func assertNear(c *C, obtained, expected float64) {
+	c.Assert(math.Abs(obtained-expected) < tolerance, IsTrue, Commentf("obtained %v, expected %v", obtained, expected))
+}
+
+func (s *testAdaptiveLimitSuite) TestCapacity(c *C) {
+	testCases := []struct {
+		rate     float64
+		capacity float64
+	}{
+		{0.5, float64(RegionBytes)}, // at least one region
+		{1, float64(RegionBytes)},
+		{4, 4 * float64(RegionBytes)},
+		{Unlimited, Unlimited * float64(RegionBytes)},
+		{2 * Unlimited, Unlimited * float64(RegionBytes)}, // at most unlimited
+	}
+	for _, t := range testCases {
+		l := NewStoreLimit(Adaptive, t.rate, AddPeer).(*adaptiveLimit)
+		c.Assert(l.Algorithm(), Equals, Adaptive)
+		c.Assert(l.Rate(), Equals, t.rate)
+		c.Assert(l.capacity, Equals, t.capacity)
+		c.Assert(l.Available(), Equals, int64(t.capacity))
+	}
+}
+
+func (s *testAdaptiveLimitSuite) TestAdjust(c *C) {
+	testCases := []struct {
+		pending, max uint64
+		factor       float64
+	}{
+		{0, 0, 1},     // no max snapshots, never adjusted
+		{10, 0, 1},    // no max snapshots, never adjusted
+		{0, 4, 1},     // idle store, full rate
+		{4, 4, 1},     // at the max, full rate
+		{8, 4, 0.5},   // slows down in proportion
+		{16, 4, 0.25}, // slows down further
+		{5, 4, 0.8},   // speeds up when snapshots are finished
+		{2, 4, 1},     // back to full rate, never above it
+	}
+	l := newAdaptiveLimit(2)
+	for _, t := range testCases {
+		l.Adjust(t.pending, t.max)
+		c.Assert(l.factor, Equals, t.factor, Commentf("pending %d, max %d", t.pending, t.max))
+		c.Assert(l.fillRate(), Equals, 2*float64(RegionBytes)*t.factor)
+	}
+}
+
+func (s *testAdaptiveLimitSuite) TestFill(c *C) {
+	testCases := []struct {
+		pending, max uint64
+		elapsed      time.Duration
+		available    float64
+	}{
+		{0, 4, time.Second / 2, float64(RegionBytes)},
+		{8, 4, time.Second, float64(RegionBytes)},
+		{16, 4, time.Second, float64(RegionBytes) / 2},
+		{16, 4, 2 * time.Second, float64(RegionBytes)},
+		{0, 4, time.Hour, 2 * float64(RegionBytes)}, // clamped by the capacity
+	}
+	for _, t := range testCases {
+		l := newAdaptiveLimit(2)
+		l.Adjust(t.pending, t.max)
+		l.available = 0
+		l.lastFill = time.Now().Add(-t.elapsed)
+		assertNear(c, float64(l.Available()), t.available)
+	}
+}
+
+func (s *testAdaptiveLimitSuite) TestTake(c *C) {
+	testCases := []struct {
+		pending, max uint64
+		take         int64
+		available    float64
+		wait         time.Duration
+	}{
+		{0, 0, RegionBytes / 2, float64(RegionBytes) / 2, 0},
+		{0, 0, RegionBytes, 0, 0},
+		{0, 0, 2 * RegionBytes, -float64(RegionBytes), time.Second},
+		{8, 4, 2 * RegionBytes, -float64(RegionBytes), 2 * time.Second},
+		{16, 4, 3 * RegionBytes, -2 * float64(RegionBytes), 8 * time.Second},
+	}
+	for _, t := range testCases {
+		l := newAdaptiveLimit(1)
+		l.Adjust(t.pending, t.max)
+		wait := l.Take(t.take)
+		assertNear(c, l.available, t.available)
+		c.Assert(math.Abs(float64(wait-t.wait)) < float64(10*time.Millisecond), IsTrue, Commentf("obtained %v, expected %v", wait, t.wait))
+	}
+
+	// A zero rate never fills the bucket, so there is nothing to wait for.
+	l := newAdaptiveLimit(0)
+	c.Assert(l.Take(2*RegionBytes), Equals, time.Duration(0))
+}
+
+func (s *testAdaptiveLimitSuite) TestAllow(c *C) {
+	l := newAdaptiveLimit(1)
+	c.Assert(l.RegionCost(), Equals, RegionBytes)
+	c.Assert(l.Cost(1000, 96), Equals, RegionBytes)
+	// A step larger than the capacity is allowed once the bucket is full.
+	c.Assert(l.Allow(2*RegionBytes), IsTrue)
+	l.Take(2 * RegionBytes)
+	c.Assert(l.Allow(RegionBytes/2), IsFalse)
+	// Refill the bucket.
+	l.lastFill = time.Now().Add(-2 * time.Second)
+	c.Assert(l.Allow(RegionBytes), IsTrue)
+	c.Assert(l.Allow(2*RegionBytes), IsTrue)
+}
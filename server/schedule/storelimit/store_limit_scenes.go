@@ -43,6 +43,8 @@ func DefaultScene(limitType Type) *Scene {
 		return defaultScene
 	case RemovePeer:
 		return defaultScene
+	case SendSnapshot, ReceiveSnapshot:
+		return defaultScene
 	default:
 		return nil
 	}
@@ -82,6 +82,25 @@ func ApplyOperatorStep(region *core.RegionInfo, op *operator.Operator) *core.Reg
 				StoreId: s.ToStore,
 			}
 			region = region.Clone(core.WithRemoveStorePeer(s.ToStore), core.WithAddPeer(peer))
+		case operator.AddWitness:
+			if region.GetStorePeer(s.ToStore) != nil {
+				panic("Add witness that exists")
+			}
+			peer := &metapb.Peer{
+				Id:        s.PeerID,
+				StoreId:   s.ToStore,
+				IsLearner: true,
+			}
+			region = region.Clone(core.WithAddPeer(peer))
+		case operator.PromoteWitness:
+			if region.GetStoreLearner(s.ToStore) == nil {
+				panic("Promote witness that doesn't exist")
+			}
+			peer := &metapb.Peer{
+				Id:      s.PeerID,
+				StoreId: s.ToStore,
+			}
+			region = region.Clone(core.WithRemoveStorePeer(s.ToStore), core.WithAddPeer(peer))
 		default:
 			panic("Unknown operator step")
 		}
@@ -254,6 +254,15 @@ func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, region *cor
 		return nil
 	}
 
+	if cluster.IsPlacementRulesEnabled() {
+		preferenceGuard := filter.NewLeaderPreferenceFilter(l.GetName(), cluster, region, source)
+		if !preferenceGuard.Target(cluster, target) {
+			log.Debug("target store is less preferred to hold the leader", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
+			schedulerCounter.WithLabelValues(l.GetName(), "leader-preference").Inc()
+			return nil
+		}
+	}
+
 	sourceID := source.GetID()
 	targetID := target.GetID()
 
@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
 func newTestReplication(mso *mockoption.ScheduleOptions, maxReplicas int, locationLabels ...string) {
@@ -395,6 +396,36 @@ func (s *testBalanceLeaderSchedulerSuite) TestLeaderWeight(c *C) {
 	testutil.CheckTransferLeader(c, s.schedule()[0], operator.OpKind(0), 1, 3)
 }
 
+func (s *testBalanceLeaderSchedulerSuite) TestLeaderPreference(c *C) {
+	// Stores:     1       2       3
+	// Leaders:    16      0       0
+	// Zone:       z1      z2      z3
+	// Region1:    L       F       F
+	s.tc.AddLabelsStore(1, 16, map[string]string{"zone": "z1"})
+	s.tc.AddLabelsStore(2, 0, map[string]string{"zone": "z2"})
+	s.tc.AddLabelsStore(3, 0, map[string]string{"zone": "z3"})
+	s.tc.UpdateLeaderCount(1, 16)
+	s.tc.AddLeaderRegion(1, 1, 2, 3)
+	c.Assert(s.schedule(), NotNil)
+
+	// The leader is on the most preferred store.
+	s.opt.EnablePlacementRules = true
+	rule := &placement.Rule{
+		GroupID:           "pd",
+		ID:                "default",
+		Role:              placement.Voter,
+		Count:             3,
+		LeaderPreferences: []placement.LabelPreference{{Key: "zone", Values: []string{"z1", "z2"}}},
+	}
+	c.Assert(s.tc.SetRule(rule), IsNil)
+	c.Assert(s.schedule(), IsNil)
+
+	// Only stores that are preferred no less than the leader store can be selected.
+	rule.LeaderPreferences = []placement.LabelPreference{{Key: "zone", Values: []string{"z2", "z1"}}}
+	c.Assert(s.tc.SetRule(rule), IsNil)
+	testutil.CheckTransferLeader(c, s.schedule()[0], operator.OpKind(0), 1, 2)
+}
+
 func (s *testBalanceLeaderSchedulerSuite) TestBalancePolicy(c *C) {
 	// Stores:       1    2     3    4
 	// LeaderCount: 20   66     6   20
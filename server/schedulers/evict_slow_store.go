@@ -0,0 +1,383 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/selector"
+	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+)
+
+const (
+	// EvictSlowStoreName is evict slow store scheduler name.
+	EvictSlowStoreName = "evict-slow-store-scheduler"
+	// EvictSlowStoreType is evict slow store scheduler type.
+	EvictSlowStoreType = "evict-slow-store"
+
+	defaultSlowThreshold = 1000 // 1s
+	defaultSlowRounds    = 3
+	defaultRecoverRounds = 6
+)
+
+func init() {
+	schedule.RegisterSliceDecoderBuilder(EvictSlowStoreType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			conf, ok := v.(*evictSlowStoreSchedulerConfig)
+			if !ok {
+				return ErrScheduleConfigNotExist
+			}
+			conf.SlowThreshold = defaultSlowThreshold
+			conf.SlowRounds = defaultSlowRounds
+			conf.RecoverRounds = defaultRecoverRounds
+			conf.EvictedStores = []uint64{}
+			return nil
+		}
+	})
+
+	schedule.RegisterScheduler(EvictSlowStoreType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := &evictSlowStoreSchedulerConfig{storage: storage}
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		if err := conf.validate(); err != nil {
+			return nil, err
+		}
+		return newEvictSlowStoreScheduler(opController, conf), nil
+	})
+}
+
+type evictSlowStoreSchedulerConfig struct {
+	sync.RWMutex
+	storage *core.Storage
+
+	SlowThreshold uint64 `json:"slow-threshold"` // in milliseconds, a heartbeat with higher slow score is considered slow
+	SlowRounds    int    `json:"slow-rounds"`    // count of continuous slow heartbeats to mark a store as slow
+	RecoverRounds int    `json:"recover-rounds"` // count of continuous normal heartbeats to recover a slow store
+	// EvictedStores is maintained by the scheduler and cannot be set by users.
+	// At most one store is evicted at a time, to avoid evicting leaders of
+	// many stores when the whole cluster is slow.
+	EvictedStores []uint64 `json:"evicted-stores"`
+}
+
+func (conf *evictSlowStoreSchedulerConfig) EncodeConfig() ([]byte, error) {
+	conf.RLock()
+	defer conf.RUnlock()
+	return schedule.EncodeConfig(conf)
+}
+
+// validate checks the config set by users. A zero slow threshold makes every
+// heartbeat slow, so the leaders would be evicted forever.
+func (conf *evictSlowStoreSchedulerConfig) validate() error {
+	if conf.SlowThreshold == 0 {
+		return errors.New("slow-threshold should be positive")
+	}
+	if conf.SlowRounds <= 0 || conf.RecoverRounds <= 0 {
+		return errors.New("rounds should be positive")
+	}
+	return nil
+}
+
+func (conf *evictSlowStoreSchedulerConfig) getEvictedStores() []uint64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return append(conf.EvictedStores[:0:0], conf.EvictedStores...)
+}
+
+func (conf *evictSlowStoreSchedulerConfig) setEvictedStores(stores []uint64) error {
+	conf.Lock()
+	defer conf.Unlock()
+	old := conf.EvictedStores
+	conf.EvictedStores = stores
+	if err := conf.persist(); err != nil {
+		conf.EvictedStores = old // revert
+		return err
+	}
+	return nil
+}
+
+func (conf *evictSlowStoreSchedulerConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router := mux.NewRouter()
+	router.HandleFunc("/list", conf.handleGetConfig).Methods("GET")
+	router.HandleFunc("/config", conf.handleSetConfig).Methods("POST")
+	router.ServeHTTP(w, r)
+}
+
+func (conf *evictSlowStoreSchedulerConfig) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	conf.RLock()
+	defer conf.RUnlock()
+	rd := render.New(render.Options{IndentJSON: true})
+	rd.JSON(w, http.StatusOK, conf)
+}
+
+func (conf *evictSlowStoreSchedulerConfig) handleSetConfig(w http.ResponseWriter, r *http.Request) {
+	conf.Lock()
+	defer conf.Unlock()
+	rd := render.New(render.Options{IndentJSON: true})
+	oldc, _ := json.Marshal(conf)
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	evicted := conf.EvictedStores
+	if err := json.Unmarshal(data, conf); err != nil {
+		rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	conf.EvictedStores = evicted
+	if err := conf.validate(); err != nil {
+		json.Unmarshal(oldc, conf)
+		rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	newc, _ := json.Marshal(conf)
+	if !bytes.Equal(oldc, newc) {
+		if err := conf.persist(); err != nil {
+			json.Unmarshal(oldc, conf)
+			rd.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	rd.Text(w, http.StatusOK, "success")
+}
+
+func (conf *evictSlowStoreSchedulerConfig) persist() error {
+	data, err := schedule.EncodeConfig(conf)
+	if err != nil {
+		return err
+	}
+	return conf.storage.SaveScheduleConfig(EvictSlowStoreName, data)
+}
+
+// slowStoreState tracks the recent heartbeats of a store.
+type slowStoreState struct {
+	lastHeartbeat time.Time
+	slowRounds    int // count of continuous slow heartbeats
+	normalRounds  int // count of continuous normal heartbeats
+}
+
+type evictSlowStoreScheduler struct {
+	*BaseScheduler
+	conf   *evictSlowStoreSchedulerConfig
+	states map[uint64]*slowStoreState
+}
+
+// newEvictSlowStoreScheduler creates a scheduler that detects slow stores by
+// store heartbeats and evicts their leaders. The store is recovered
+// automatically after it becomes normal again.
+func newEvictSlowStoreScheduler(opController *schedule.OperatorController, conf *evictSlowStoreSchedulerConfig) schedule.Scheduler {
+	base := NewBaseScheduler(opController)
+	return &evictSlowStoreScheduler{
+		BaseScheduler: base,
+		conf:          conf,
+		states:        make(map[uint64]*slowStoreState),
+	}
+}
+
+func (s *evictSlowStoreScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.conf.ServeHTTP(w, r)
+}
+
+func (s *evictSlowStoreScheduler) GetName() string {
+	return EvictSlowStoreName
+}
+
+func (s *evictSlowStoreScheduler) GetType() string {
+	return EvictSlowStoreType
+}
+
+func (s *evictSlowStoreScheduler) EncodeConfig() ([]byte, error) {
+	return s.conf.EncodeConfig()
+}
+
+func (s *evictSlowStoreScheduler) Prepare(cluster opt.Cluster) error {
+	var res error
+	for _, id := range s.conf.getEvictedStores() {
+		if err := cluster.BlockStore(id); err != nil {
+			res = err
+		}
+	}
+	return res
+}
+
+func (s *evictSlowStoreScheduler) Cleanup(cluster opt.Cluster) {
+	for _, id := range s.conf.getEvictedStores() {
+		cluster.UnblockStore(id)
+	}
+}
+
+func (s *evictSlowStoreScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
+	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
+}
+
+func (s *evictSlowStoreScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	s.updateSlowStores(cluster)
+
+	var ops []*operator.Operator
+	for _, id := range s.conf.getEvictedStores() {
+		for i := 0; i < EvictLeaderBatchSize; i++ {
+			op := s.evictLeader(cluster, id)
+			if op == nil {
+				break
+			}
+			ops = s.uniqueAppend(ops, op)
+		}
+	}
+	return ops
+}
+
+// updateSlowStores checks new heartbeats of all stores, evicts the store that
+// keeps slow and recovers the evicted store that keeps normal.
+func (s *evictSlowStoreScheduler) updateSlowStores(cluster opt.Cluster) {
+	s.conf.RLock()
+	threshold, slowRounds, recoverRounds := s.conf.SlowThreshold, s.conf.SlowRounds, s.conf.RecoverRounds
+	s.conf.RUnlock()
+
+	evicted := s.conf.getEvictedStores()
+	stores := make(map[uint64]struct{})
+	for _, store := range cluster.GetStores() {
+		if store.IsTombstone() {
+			continue
+		}
+		stores[store.GetID()] = struct{}{}
+		state, ok := s.states[store.GetID()]
+		if !ok {
+			state = &slowStoreState{}
+			s.states[store.GetID()] = state
+		}
+		if !state.lastHeartbeat.Before(store.GetLastHeartbeatTS()) {
+			continue // no new heartbeat
+		}
+		state.lastHeartbeat = store.GetLastHeartbeatTS()
+		if storeSlowScore(store) > threshold {
+			state.slowRounds++
+			state.normalRounds = 0
+		} else {
+			state.normalRounds++
+			state.slowRounds = 0
+		}
+	}
+	for id := range s.states {
+		if _, ok := stores[id]; !ok {
+			delete(s.states, id)
+		}
+	}
+
+	if len(evicted) > 0 {
+		// recover the store if it keeps normal or it has been removed.
+		id := evicted[0]
+		if state, ok := s.states[id]; ok && state.normalRounds < recoverRounds {
+			return
+		}
+		if err := s.conf.setEvictedStores([]uint64{}); err != nil {
+			log.Warn("failed to persist evict slow store config", zap.Error(err))
+			return
+		}
+		cluster.UnblockStore(id)
+		log.Info("slow store recovered", zap.Uint64("store-id", id))
+		schedulerCounter.WithLabelValues(s.GetName(), "recover-store").Inc()
+		return
+	}
+	for id, state := range s.states {
+		if state.slowRounds < slowRounds {
+			continue
+		}
+		if err := cluster.BlockStore(id); err != nil {
+			log.Warn("failed to block slow store", zap.Uint64("store-id", id), zap.Error(err))
+			continue
+		}
+		if err := s.conf.setEvictedStores([]uint64{id}); err != nil {
+			cluster.UnblockStore(id)
+			log.Warn("failed to persist evict slow store config", zap.Error(err))
+			return
+		}
+		log.Info("detected slow store, start to evict leaders", zap.Uint64("store-id", id), zap.Int("slow-rounds", state.slowRounds))
+		schedulerCounter.WithLabelValues(s.GetName(), "evict-store").Inc()
+		return
+	}
+}
+
+func (s *evictSlowStoreScheduler) evictLeader(cluster opt.Cluster, storeID uint64) *operator.Operator {
+	region := cluster.RandLeaderRegion(storeID, nil, opt.HealthRegion(cluster))
+	if region == nil {
+		schedulerCounter.WithLabelValues(s.GetName(), "no-leader").Inc()
+		return nil
+	}
+	target := selector.NewCandidates(cluster.GetFollowerStores(region)).
+		FilterTarget(cluster, filter.StoreStateFilter{ActionScope: EvictSlowStoreName, TransferLeader: true}).
+		RandomPick()
+	if target == nil {
+		schedulerCounter.WithLabelValues(s.GetName(), "no-target-store").Inc()
+		return nil
+	}
+	op, err := operator.CreateTransferLeaderOperator(EvictSlowStoreType, cluster, region, region.GetLeader().GetStoreId(), target.GetID(), operator.OpLeader)
+	if err != nil {
+		log.Debug("fail to create evict slow store operator", zap.Error(err))
+		return nil
+	}
+	op.SetPriorityLevel(core.HighPriority)
+	op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
+	return op
+}
+
+func (s *evictSlowStoreScheduler) uniqueAppend(dst []*operator.Operator, op *operator.Operator) []*operator.Operator {
+	for i := range dst {
+		if dst[i].RegionID() == op.RegionID() {
+			return dst
+		}
+	}
+	return append(dst, op)
+}
+
+// storeSlowScore returns the slow score of the last heartbeat of a store, in
+// milliseconds. It is the larger one of the max operation latency reported by
+// the store and the delay of the heartbeat.
+func storeSlowScore(store *core.StoreInfo) uint64 {
+	stats := store.GetStoreStats()
+	var score uint64
+	for _, l := range stats.GetOpLatencies() {
+		if l.GetValue() > score {
+			score = l.GetValue()
+		}
+	}
+	interval := stats.GetInterval()
+	if interval.GetEndTimestamp() > interval.GetStartTimestamp() {
+		// timestamps are in seconds.
+		elapsed := interval.GetEndTimestamp() - interval.GetStartTimestamp()
+		if elapsed > statistics.StoreHeartBeatReportInterval {
+			if delay := (elapsed - statistics.StoreHeartBeatReportInterval) * 1000; delay > score {
+				score = delay
+			}
+		}
+	}
+	return score
+}
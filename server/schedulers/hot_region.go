@@ -46,6 +46,9 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		if err := conf.validate(); err != nil {
+			return nil, err
+		}
 		conf.storage = storage
 		return newHotScheduler(opController, conf), nil
 	})
@@ -494,6 +497,10 @@ type balanceSolver struct {
 	maxSrc   *storeLoad
 	minDst   *storeLoad
 	rankStep *storeLoad
+
+	// firstPriority and secondPriority are the dimensions to balance.
+	firstPriority  string
+	secondPriority string
 }
 
 type solution struct {
@@ -509,13 +516,20 @@ type solution struct {
 }
 
 func (bs *balanceSolver) init() {
+	var priorities []string
 	switch toResourceType(bs.rwTy, bs.opTy) {
 	case writePeer:
 		bs.stLoadDetail = bs.sche.stLoadInfos[writePeer]
+		priorities = bs.sche.conf.GetWritePeerPriorities()
 	case writeLeader:
 		bs.stLoadDetail = bs.sche.stLoadInfos[writeLeader]
+		priorities = bs.sche.conf.GetWriteLeaderPriorities()
 	case readLeader:
 		bs.stLoadDetail = bs.sche.stLoadInfos[readLeader]
+		priorities = bs.sche.conf.GetReadPriorities()
+	}
+	if len(priorities) == 2 {
+		bs.firstPriority, bs.secondPriority = priorities[0], priorities[1]
 	}
 	for _, id := range getUnhealthyStores(bs.cluster) {
 		delete(bs.stLoadDetail, id)
@@ -569,6 +583,9 @@ func (bs *balanceSolver) isValid() bool {
 	if bs.cluster == nil || bs.sche == nil || bs.stLoadDetail == nil {
 		return false
 	}
+	if bs.firstPriority == "" || bs.secondPriority == "" {
+		return false
+	}
 	switch bs.rwTy {
 	case write, read:
 	default:
@@ -647,8 +664,9 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*storeLoadDetail {
 		if len(detail.HotPeers) == 0 {
 			continue
 		}
-		if detail.LoadPred.min().ByteRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Future.ExpByteRate &&
-			detail.LoadPred.min().KeyRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Future.ExpKeyRate {
+		minLd, srcToleranceRatio := detail.LoadPred.min(), bs.sche.conf.GetSrcToleranceRatio()
+		if stLdRate(bs.firstPriority)(minLd) > srcToleranceRatio*stLdExpRate(&detail.LoadPred.Future, bs.firstPriority) &&
+			stLdRate(bs.secondPriority)(minLd) > srcToleranceRatio*stLdExpRate(&detail.LoadPred.Future, bs.secondPriority) {
 			ret[id] = detail
 			balanceHotRegionCounter.WithLabelValues("src-store-succ", strconv.FormatUint(id, 10)).Inc()
 		}
@@ -677,30 +695,30 @@ func (bs *balanceSolver) filterHotPeers() []*statistics.HotPeerStat {
 		return nret
 	}
 
-	byteSort := make([]*statistics.HotPeerStat, len(ret))
-	copy(byteSort, ret)
-	sort.Slice(byteSort, func(i, j int) bool {
-		return byteSort[i].GetByteRate() > byteSort[j].GetByteRate()
+	firstSort := make([]*statistics.HotPeerStat, len(ret))
+	copy(firstSort, ret)
+	sort.Slice(firstSort, func(i, j int) bool {
+		return hotPeerRate(firstSort[i], bs.firstPriority) > hotPeerRate(firstSort[j], bs.firstPriority)
 	})
-	keySort := make([]*statistics.HotPeerStat, len(ret))
-	copy(keySort, ret)
-	sort.Slice(keySort, func(i, j int) bool {
-		return keySort[i].GetKeyRate() > keySort[j].GetKeyRate()
+	secondSort := make([]*statistics.HotPeerStat, len(ret))
+	copy(secondSort, ret)
+	sort.Slice(secondSort, func(i, j int) bool {
+		return hotPeerRate(secondSort[i], bs.secondPriority) > hotPeerRate(secondSort[j], bs.secondPriority)
 	})
 
 	union := make(map[*statistics.HotPeerStat]struct{}, maxPeerNum)
 	for len(union) < maxPeerNum {
-		for len(byteSort) > 0 {
-			peer := byteSort[0]
-			byteSort = byteSort[1:]
+		for len(firstSort) > 0 {
+			peer := firstSort[0]
+			firstSort = firstSort[1:]
 			if _, ok := union[peer]; !ok {
 				union[peer] = struct{}{}
 				break
 			}
 		}
-		for len(keySort) > 0 {
-			peer := keySort[0]
-			keySort = keySort[1:]
+		for len(secondSort) > 0 {
+			peer := secondSort[0]
+			secondSort = secondSort[1:]
 			if _, ok := union[peer]; !ok {
 				union[peer] = struct{}{}
 				break
@@ -816,8 +834,9 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 	for _, store := range candidates {
 		if filter.Target(bs.cluster, store, filters) {
 			detail := bs.stLoadDetail[store.GetID()]
-			if detail.LoadPred.max().ByteRate*bs.sche.conf.GetDstToleranceRatio() < detail.LoadPred.Future.ExpByteRate &&
-				detail.LoadPred.max().KeyRate*bs.sche.conf.GetDstToleranceRatio() < detail.LoadPred.Future.ExpKeyRate {
+			maxLd, dstToleranceRatio := detail.LoadPred.max(), bs.sche.conf.GetDstToleranceRatio()
+			if stLdRate(bs.firstPriority)(maxLd)*dstToleranceRatio < stLdExpRate(&detail.LoadPred.Future, bs.firstPriority) &&
+				stLdRate(bs.secondPriority)(maxLd)*dstToleranceRatio < stLdExpRate(&detail.LoadPred.Future, bs.secondPriority) {
 				ret[store.GetID()] = bs.stLoadDetail[store.GetID()]
 				balanceHotRegionCounter.WithLabelValues("dst-store-succ", strconv.FormatUint(store.GetID(), 10)).Inc()
 			}
@@ -835,10 +854,11 @@ func (bs *balanceSolver) calcProgressiveRank() {
 	dstLd := bs.stLoadDetail[bs.cur.dstStoreID].LoadPred.max()
 	peer := bs.cur.srcPeerStat
 	rank := int64(0)
+	first, second := bs.firstPriority, bs.secondPriority
 	if bs.rwTy == write && bs.opTy == transferLeader {
 		// In this condition, CPU usage is the matter.
-		// Only consider about key rate.
-		if srcLd.KeyRate >= dstLd.KeyRate+peer.GetKeyRate() {
+		// Only consider about the first priority, which is key rate by default.
+		if stLdRate(first)(srcLd) >= stLdRate(first)(dstLd)+hotPeerRate(peer, first) {
 			rank = -1
 		}
 	} else {
@@ -848,26 +868,39 @@ func (bs *balanceSolver) calcProgressiveRank() {
 			}
 			return a - b
 		}
-		keyDecRatio := (dstLd.KeyRate + peer.GetKeyRate()) / getSrcDecRate(srcLd.KeyRate, peer.GetKeyRate())
-		keyHot := peer.GetKeyRate() >= bs.sche.conf.GetMinHotKeyRate()
-		byteDecRatio := (dstLd.ByteRate + peer.GetByteRate()) / getSrcDecRate(srcLd.ByteRate, peer.GetByteRate())
-		byteHot := peer.GetByteRate() > bs.sche.conf.GetMinHotByteRate()
+		getDecRatio := func(dim string) float64 {
+			peerRate := hotPeerRate(peer, dim)
+			return (stLdRate(dim)(dstLd) + peerRate) / getSrcDecRate(stLdRate(dim)(srcLd), peerRate)
+		}
+		firstDecRatio, firstHot := getDecRatio(first), bs.isHotPeer(first)
+		secondDecRatio, secondHot := getDecRatio(second), bs.isHotPeer(second)
 		greatDecRatio, minorDecRatio := bs.sche.conf.GetGreatDecRatio(), bs.sche.conf.GetMinorGreatDecRatio()
 		switch {
-		case byteHot && byteDecRatio <= greatDecRatio && keyHot && keyDecRatio <= greatDecRatio:
-			// Both byte rate and key rate are balanced, the best choice.
+		case firstHot && firstDecRatio <= greatDecRatio && secondHot && secondDecRatio <= greatDecRatio:
+			// Both dimensions are balanced, the best choice.
 			rank = -3
-		case byteDecRatio <= minorDecRatio && keyHot && keyDecRatio <= greatDecRatio:
-			// Byte rate is not worsened, key rate is balanced.
+		case firstDecRatio <= minorDecRatio && secondHot && secondDecRatio <= greatDecRatio:
+			// The first dimension is not worsened, the second one is balanced.
 			rank = -2
-		case byteHot && byteDecRatio <= greatDecRatio:
-			// Byte rate is balanced, ignore the key rate.
+		case firstHot && firstDecRatio <= greatDecRatio:
+			// The first dimension is balanced, ignore the second one.
 			rank = -1
 		}
 	}
 	bs.cur.progressiveRank = rank
 }
 
+// isHotPeer checks if the current source peer is hot enough in the dimension.
+func (bs *balanceSolver) isHotPeer(priority string) bool {
+	peer := bs.cur.srcPeerStat
+	switch priority {
+	case KeyPriority:
+		return peer.GetKeyRate() >= bs.sche.conf.GetMinHotKeyRate()
+	default:
+		return peer.GetByteRate() > bs.sche.conf.GetMinHotByteRate()
+	}
+}
+
 // betterThan checks if `bs.cur` is a better solution than `old`.
 func (bs *balanceSolver) betterThan(old *solution) bool {
 	if old == nil {
@@ -896,35 +929,36 @@ func (bs *balanceSolver) betterThan(old *solution) bool {
 	if bs.cur.srcPeerStat != old.srcPeerStat {
 		// compare region
 
+		first, second := bs.firstPriority, bs.secondPriority
 		if bs.rwTy == write && bs.opTy == transferLeader {
 			switch {
-			case bs.cur.srcPeerStat.GetKeyRate() > old.srcPeerStat.GetKeyRate():
+			case hotPeerRate(bs.cur.srcPeerStat, first) > hotPeerRate(old.srcPeerStat, first):
 				return true
-			case bs.cur.srcPeerStat.GetKeyRate() < old.srcPeerStat.GetKeyRate():
+			case hotPeerRate(bs.cur.srcPeerStat, first) < hotPeerRate(old.srcPeerStat, first):
 				return false
 			}
 		} else {
-			byteRkCmp := rankCmp(bs.cur.srcPeerStat.GetByteRate(), old.srcPeerStat.GetByteRate(), stepRank(0, 100))
-			keyRkCmp := rankCmp(bs.cur.srcPeerStat.GetKeyRate(), old.srcPeerStat.GetKeyRate(), stepRank(0, 10))
+			firstRkCmp := rankCmp(hotPeerRate(bs.cur.srcPeerStat, first), hotPeerRate(old.srcPeerStat, first), stepRank(0, regionRankStep(first)))
+			secondRkCmp := rankCmp(hotPeerRate(bs.cur.srcPeerStat, second), hotPeerRate(old.srcPeerStat, second), stepRank(0, regionRankStep(second)))
 
 			switch bs.cur.progressiveRank {
-			case -2: // greatDecRatio < byteDecRatio <= minorDecRatio && keyDecRatio <= greatDecRatio
-				if keyRkCmp != 0 {
-					return keyRkCmp > 0
+			case -2: // greatDecRatio < firstDecRatio <= minorDecRatio && secondDecRatio <= greatDecRatio
+				if secondRkCmp != 0 {
+					return secondRkCmp > 0
 				}
-				if byteRkCmp != 0 {
-					// prefer smaller byte rate, to reduce oscillation
-					return byteRkCmp < 0
+				if firstRkCmp != 0 {
+					// prefer smaller rate of the first dimension, to reduce oscillation
+					return firstRkCmp < 0
 				}
-			case -3: // byteDecRatio <= greatDecRatio && keyDecRatio <= greatDecRatio
-				if keyRkCmp != 0 {
-					return keyRkCmp > 0
+			case -3: // firstDecRatio <= greatDecRatio && secondDecRatio <= greatDecRatio
+				if secondRkCmp != 0 {
+					return secondRkCmp > 0
 				}
 				fallthrough
-			case -1: // byteDecRatio <= greatDecRatio
-				if byteRkCmp != 0 {
-					// prefer region with larger byte rate, to converge faster
-					return byteRkCmp > 0
+			case -1: // firstDecRatio <= greatDecRatio
+				if firstRkCmp != 0 {
+					// prefer region with larger rate of the first dimension, to converge faster
+					return firstRkCmp > 0
 				}
 			}
 		}
@@ -938,26 +972,27 @@ func (bs *balanceSolver) compareSrcStore(st1, st2 uint64) int {
 	if st1 != st2 {
 		// compare source store
 		var lpCmp storeLPCmp
+		first, second := bs.firstPriority, bs.secondPriority
 		if bs.rwTy == write && bs.opTy == transferLeader {
 			lpCmp = sliceLPCmp(
 				minLPCmp(negLoadCmp(sliceLoadCmp(
-					stLdRankCmp(stLdKeyRate, stepRank(bs.maxSrc.KeyRate, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(bs.maxSrc.ByteRate, bs.rankStep.ByteRate)),
+					bs.stLdRankCmp(first, stLdRate(first)(bs.maxSrc)),
+					bs.stLdRankCmp(second, stLdRate(second)(bs.maxSrc)),
 				))),
 				diffCmp(sliceLoadCmp(
 					stLdRankCmp(stLdCount, stepRank(0, bs.rankStep.Count)),
-					stLdRankCmp(stLdKeyRate, stepRank(0, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					bs.stLdRankCmp(first, 0),
+					bs.stLdRankCmp(second, 0),
 				)),
 			)
 		} else {
 			lpCmp = sliceLPCmp(
 				minLPCmp(negLoadCmp(sliceLoadCmp(
-					stLdRankCmp(stLdByteRate, stepRank(bs.maxSrc.ByteRate, bs.rankStep.ByteRate)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.maxSrc.KeyRate, bs.rankStep.KeyRate)),
+					bs.stLdRankCmp(first, stLdRate(first)(bs.maxSrc)),
+					bs.stLdRankCmp(second, stLdRate(second)(bs.maxSrc)),
 				))),
 				diffCmp(
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					bs.stLdRankCmp(first, 0),
 				),
 			)
 		}
@@ -974,25 +1009,26 @@ func (bs *balanceSolver) compareDstStore(st1, st2 uint64) int {
 	if st1 != st2 {
 		// compare destination store
 		var lpCmp storeLPCmp
+		first, second := bs.firstPriority, bs.secondPriority
 		if bs.rwTy == write && bs.opTy == transferLeader {
 			lpCmp = sliceLPCmp(
 				maxLPCmp(sliceLoadCmp(
-					stLdRankCmp(stLdKeyRate, stepRank(bs.minDst.KeyRate, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(bs.minDst.ByteRate, bs.rankStep.ByteRate)),
+					bs.stLdRankCmp(first, stLdRate(first)(bs.minDst)),
+					bs.stLdRankCmp(second, stLdRate(second)(bs.minDst)),
 				)),
 				diffCmp(sliceLoadCmp(
 					stLdRankCmp(stLdCount, stepRank(0, bs.rankStep.Count)),
-					stLdRankCmp(stLdKeyRate, stepRank(0, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					bs.stLdRankCmp(first, 0),
+					bs.stLdRankCmp(second, 0),
 				)))
 		} else {
 			lpCmp = sliceLPCmp(
 				maxLPCmp(sliceLoadCmp(
-					stLdRankCmp(stLdByteRate, stepRank(bs.minDst.ByteRate, bs.rankStep.ByteRate)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.minDst.KeyRate, bs.rankStep.KeyRate)),
+					bs.stLdRankCmp(first, stLdRate(first)(bs.minDst)),
+					bs.stLdRankCmp(second, stLdRate(second)(bs.minDst)),
 				)),
 				diffCmp(
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					bs.stLdRankCmp(first, 0),
 				),
 			)
 		}
@@ -1004,6 +1040,19 @@ func (bs *balanceSolver) compareDstStore(st1, st2 uint64) int {
 	return 0
 }
 
+// stLdRankCmp compares the load of the dimension by the rank step of it.
+func (bs *balanceSolver) stLdRankCmp(priority string, rk0 float64) storeLoadCmp {
+	return stLdRankCmp(stLdRate(priority), stepRank(rk0, stLdRate(priority)(bs.rankStep)))
+}
+
+// regionRankStep returns the rank step used to compare the rate of regions.
+func regionRankStep(priority string) float64 {
+	if priority == BytePriority {
+		return 100
+	}
+	return 10
+}
+
 func stepRank(rk0 float64, step float64) func(float64) int64 {
 	return func(rate float64) int64 {
 		return int64((rate - rk0) / step)
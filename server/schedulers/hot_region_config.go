@@ -16,6 +16,7 @@ package schedulers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"reflect"
@@ -44,9 +45,18 @@ func initHotRegionScheduleConfig() *hotRegionSchedulerConfig {
 		MaxPeerNum:            1000,
 		SrcToleranceRatio:     1.05, // Tolerate 5% difference
 		DstToleranceRatio:     1.05, // Tolerate 5% difference
+		ReadPriorities:        []string{BytePriority, KeyPriority},
+		WriteLeaderPriorities: []string{KeyPriority, BytePriority},
+		WritePeerPriorities:   []string{BytePriority, KeyPriority},
 	}
 }
 
+// The dimensions which can be used in the priorities of hot region scheduler.
+const (
+	BytePriority = "byte"
+	KeyPriority  = "key"
+)
+
 type hotRegionSchedulerConfig struct {
 	sync.RWMutex
 	storage *core.Storage
@@ -65,6 +75,12 @@ type hotRegionSchedulerConfig struct {
 	MinorDecRatio         float64 `json:"minor-dec-ratio"`
 	SrcToleranceRatio     float64 `json:"src-tolerance-ratio"`
 	DstToleranceRatio     float64 `json:"dst-tolerance-ratio"`
+
+	// priorities decide the dimensions to balance, the first one is the
+	// primary dimension and the second one is the secondary dimension.
+	ReadPriorities        []string `json:"read-priorities"`
+	WriteLeaderPriorities []string `json:"write-leader-priorities"`
+	WritePeerPriorities   []string `json:"write-peer-priorities"`
 }
 
 func (conf *hotRegionSchedulerConfig) EncodeConfig() ([]byte, error) {
@@ -151,6 +167,55 @@ func (conf *hotRegionSchedulerConfig) GetMinHotByteRate() float64 {
 	return conf.MinHotByteRate
 }
 
+func (conf *hotRegionSchedulerConfig) GetReadPriorities() []string {
+	conf.RLock()
+	defer conf.RUnlock()
+	return append([]string(nil), conf.ReadPriorities...)
+}
+
+func (conf *hotRegionSchedulerConfig) GetWriteLeaderPriorities() []string {
+	conf.RLock()
+	defer conf.RUnlock()
+	return append([]string(nil), conf.WriteLeaderPriorities...)
+}
+
+func (conf *hotRegionSchedulerConfig) GetWritePeerPriorities() []string {
+	conf.RLock()
+	defer conf.RUnlock()
+	return append([]string(nil), conf.WritePeerPriorities...)
+}
+
+func (conf *hotRegionSchedulerConfig) validate() error {
+	for name, priorities := range map[string][]string{
+		"read-priorities":         conf.ReadPriorities,
+		"write-leader-priorities": conf.WriteLeaderPriorities,
+		"write-peer-priorities":   conf.WritePeerPriorities,
+	} {
+		if err := validatePriorities(priorities); err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// validatePriorities checks that the priorities are two different dimensions.
+func validatePriorities(priorities []string) error {
+	if len(priorities) != 2 {
+		return fmt.Errorf("need exactly 2 dimensions, got %d", len(priorities))
+	}
+	for _, p := range priorities {
+		switch p {
+		case BytePriority, KeyPriority:
+		default:
+			return fmt.Errorf("unknown dimension %q", p)
+		}
+	}
+	if priorities[0] == priorities[1] {
+		return fmt.Errorf("duplicated dimension %q", priorities[0])
+	}
+	return nil
+}
+
 func (conf *hotRegionSchedulerConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	router := mux.NewRouter()
 	router.HandleFunc("/list", conf.handleGetConfig).Methods("GET")
@@ -181,6 +246,12 @@ func (conf *hotRegionSchedulerConfig) handleSetConfig(w http.ResponseWriter, r *
 		rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if err := conf.validate(); err != nil {
+		// roll back to the old config.
+		_ = json.Unmarshal(oldc, conf)
+		rd.Text(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	newc, _ := json.Marshal(conf)
 	if !bytes.Equal(oldc, newc) {
 		conf.persist()
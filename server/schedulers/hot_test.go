@@ -111,6 +111,19 @@ func (s *testHotSchedulerSuite) TestGCPendingOpInfos(c *C) {
 	}
 }
 
+func (s *testHotSchedulerSuite) TestValidatePriorities(c *C) {
+	c.Assert(validatePriorities([]string{BytePriority, KeyPriority}), IsNil)
+	c.Assert(validatePriorities([]string{KeyPriority, BytePriority}), IsNil)
+	for _, priorities := range [][]string{
+		{BytePriority},
+		{BytePriority, BytePriority},
+		{"query", BytePriority},
+		{BytePriority, KeyPriority, "query"},
+	} {
+		c.Assert(validatePriorities(priorities), NotNil)
+	}
+}
+
 func newTestRegion(id uint64) *core.RegionInfo {
 	peers := []*metapb.Peer{{Id: id*100 + 1, StoreId: 1}, {Id: id*100 + 2, StoreId: 2}, {Id: id*100 + 3, StoreId: 3}}
 	return core.NewRegionInfo(&metapb.Region{Id: id, Peers: peers}, peers[0])
@@ -15,8 +15,14 @@ package schedulers
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/pkg/testutil"
@@ -338,6 +344,73 @@ func (s *testEvictLeaderSuite) TestEvictLeader(c *C) {
 	testutil.CheckTransferLeader(c, op[0], operator.OpLeader, 1, 2)
 }
 
+var _ = Suite(&testEvictSlowStoreSuite{})
+
+type testEvictSlowStoreSuite struct{}
+
+func (s *testEvictSlowStoreSuite) TestEvictSlowStore(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.AddLeaderRegion(1, 1, 2, 3)
+	tc.AddLeaderRegion(2, 1, 2, 3)
+	tc.AddLeaderRegion(3, 2, 1, 3)
+
+	now := time.Now()
+	heartbeat := func(latency uint64) {
+		now = now.Add(statistics.StoreHeartBeatReportInterval * time.Second)
+		for id := uint64(1); id <= 3; id++ {
+			stats := &pdpb.StoreStats{Capacity: 1000 * (1 << 20), Available: 1000 * (1 << 20)}
+			if id == 1 {
+				stats.OpLatencies = []*pdpb.RecordPair{{Key: "apply", Value: latency}}
+			}
+			tc.PutStore(tc.GetStore(id).Clone(core.SetStoreStats(stats), core.SetLastHeartbeatTS(now)))
+		}
+	}
+
+	storage := core.NewStorage(kv.NewMemoryKV())
+	es, err := schedule.CreateScheduler(EvictSlowStoreType, schedule.NewOperatorController(ctx, nil, nil), storage, schedule.ConfigSliceDecoder(EvictSlowStoreType, nil))
+	c.Assert(err, IsNil)
+	c.Assert(es.Prepare(tc), IsNil)
+	conf := es.(*evictSlowStoreScheduler).conf
+
+	// Store 1 is evicted after being slow for `slow-rounds` heartbeats.
+	for i := 0; i < defaultSlowRounds-1; i++ {
+		heartbeat(defaultSlowThreshold * 2)
+		c.Assert(es.Schedule(tc), IsNil)
+		c.Assert(conf.getEvictedStores(), HasLen, 0)
+	}
+	heartbeat(defaultSlowThreshold * 2)
+	ops := es.Schedule(tc)
+	c.Assert(conf.getEvictedStores(), DeepEquals, []uint64{1})
+	c.Assert(len(ops), Greater, 0)
+	for _, op := range ops {
+		testutil.CheckTransferLeaderFrom(c, op, operator.OpLeader, 1)
+	}
+	c.Assert(tc.GetStore(1).IsBlocked(), IsTrue)
+
+	// The evicted store is persisted.
+	data, err := storage.LoadScheduleConfig(EvictSlowStoreName)
+	c.Assert(err, IsNil)
+	persisted := &evictSlowStoreSchedulerConfig{}
+	c.Assert(schedule.DecodeConfig([]byte(data), persisted), IsNil)
+	c.Assert(persisted.EvictedStores, DeepEquals, []uint64{1})
+
+	// Store 1 is recovered after being normal for `recover-rounds` heartbeats.
+	for i := 0; i < defaultRecoverRounds; i++ {
+		c.Assert(conf.getEvictedStores(), DeepEquals, []uint64{1})
+		heartbeat(0)
+		es.Schedule(tc)
+	}
+	c.Assert(conf.getEvictedStores(), HasLen, 0)
+	c.Assert(tc.GetStore(1).IsBlocked(), IsFalse)
+}
+
 var _ = Suite(&testShuffleRegionSuite{})
 
 type testShuffleRegionSuite struct{}
@@ -513,3 +586,40 @@ func (s *testSpecialUseSuite) TestSpecialUseReserved(c *C) {
 	ops = bs.Schedule(tc)
 	c.Assert(ops, HasLen, 0)
 }
+
+func (s *testEvictSlowStoreSuite) TestValidateConfig(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage := core.NewStorage(kv.NewMemoryKV())
+	es, err := schedule.CreateScheduler(EvictSlowStoreType, schedule.NewOperatorController(ctx, nil, nil), storage, schedule.ConfigSliceDecoder(EvictSlowStoreType, nil))
+	c.Assert(err, IsNil)
+	conf := es.(*evictSlowStoreScheduler).conf
+	c.Assert(conf.validate(), IsNil)
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		conf.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/config", strings.NewReader(body)))
+		return w.Code
+	}
+	// A zero slow threshold or non-positive rounds are rejected and the old config is kept.
+	for _, body := range []string{`{"slow-threshold":0}`, `{"slow-rounds":0}`, `{"recover-rounds":-1}`} {
+		c.Assert(post(body), Equals, http.StatusBadRequest)
+		c.Assert(conf.SlowThreshold, Equals, uint64(defaultSlowThreshold))
+		c.Assert(conf.SlowRounds, Equals, defaultSlowRounds)
+		c.Assert(conf.RecoverRounds, Equals, defaultRecoverRounds)
+	}
+	c.Assert(post(`{"slow-threshold":2000}`), Equals, http.StatusOK)
+	c.Assert(conf.SlowThreshold, Equals, uint64(2000))
+
+	// An invalid persisted config cannot create the scheduler.
+	conf.SlowThreshold = 0
+	c.Assert(conf.persist(), IsNil)
+	_, err = schedule.CreateScheduler(EvictSlowStoreType, schedule.NewOperatorController(ctx, nil, nil), storage, func(v interface{}) error {
+		data, err := storage.LoadScheduleConfig(EvictSlowStoreName)
+		if err != nil {
+			return err
+		}
+		return schedule.DecodeConfig([]byte(data), v)
+	})
+	c.Assert(err, NotNil)
+}
@@ -243,6 +243,36 @@ func stLdCount(ld *storeLoad) float64 {
 	return ld.Count
 }
 
+// stLdRate returns the getter of the load in the dimension.
+func stLdRate(priority string) func(ld *storeLoad) float64 {
+	switch priority {
+	case KeyPriority:
+		return stLdKeyRate
+	default:
+		return stLdByteRate
+	}
+}
+
+// stLdExpRate returns the expected load of the dimension.
+func stLdExpRate(ld *storeLoad, priority string) float64 {
+	switch priority {
+	case KeyPriority:
+		return ld.ExpKeyRate
+	default:
+		return ld.ExpByteRate
+	}
+}
+
+// hotPeerRate returns the denoised rate of the peer in the dimension.
+func hotPeerRate(peer *statistics.HotPeerStat, priority string) float64 {
+	switch priority {
+	case KeyPriority:
+		return peer.GetKeyRate()
+	default:
+		return peer.GetByteRate()
+	}
+}
+
 type storeLoadCmp func(ld1, ld2 *storeLoad) int
 
 func negLoadCmp(cmp storeLoadCmp) storeLoadCmp {
@@ -113,6 +113,8 @@ type Server struct {
 	idAllocator *id.AllocatorImpl
 	// for storage operation.
 	storage *core.Storage
+	// for hot region history.
+	hotRegionStorage *core.HotRegionStorage
 	// for baiscCluster operation.
 	basicCluster *core.BasicCluster
 	// for tso.
@@ -350,6 +352,7 @@ func (s *Server) startServer(ctx context.Context) error {
 		s.rootPath,
 		s.member.MemberValue(),
 		s.cfg.TsoSaveInterval.Duration,
+		s.cfg.TsoUpdatePhysicalInterval.Duration,
 		func() time.Duration { return s.persistOptions.GetMaxResetTSGap() },
 	)
 	kvBase := kv.NewEtcdKVBase(s.client, s.rootPath)
@@ -359,6 +362,10 @@ func (s *Server) startServer(ctx context.Context) error {
 		return err
 	}
 	s.storage = core.NewStorage(kvBase).SetRegionStorage(regionStorage)
+	s.hotRegionStorage, err = core.NewHotRegionStorage(ctx, filepath.Join(s.cfg.DataDir, "hot-region"), s.handler)
+	if err != nil {
+		return err
+	}
 	s.basicCluster = core.NewBasicCluster()
 	s.cluster = cluster.NewRaftCluster(ctx, s.GetClusterRootPath(), s.clusterID, syncer.NewRegionSyncer(s), s.client, s.httpClient)
 	s.hbStreams = newHeartbeatStreams(ctx, s.clusterID, s.cluster)
@@ -419,6 +426,11 @@ func (s *Server) Close() {
 	if err := s.storage.Close(); err != nil {
 		log.Error("close storage meet error", zap.Error(err))
 	}
+	if s.hotRegionStorage != nil {
+		if err := s.hotRegionStorage.Close(); err != nil {
+			log.Error("close hot region storage meet error", zap.Error(err))
+		}
+	}
 
 	// Run callbacks
 	for _, cb := range s.closeCallbacks {
@@ -644,6 +656,11 @@ func (s *Server) GetStorage() *core.Storage {
 	return s.storage
 }
 
+// GetHotRegionStorage returns the storage of the hot region history.
+func (s *Server) GetHotRegionStorage() *core.HotRegionStorage {
+	return s.hotRegionStorage
+}
+
 // SetStorage changes the storage only for test purpose.
 // When we use it, we should prevent calling GetStorage, otherwise, it may cause a data race problem.
 func (s *Server) SetStorage(storage *core.Storage) {
@@ -778,6 +795,9 @@ func (s *Server) SetReplicationConfig(cfg config.ReplicationConfig) error {
 				if !s.IsTombstone() && core.IsTiFlashStore(s.GetMeta()) {
 					return errors.New("cannot disable placement rules with TiFlash nodes")
 				}
+				if !s.IsTombstone() && s.IsWitness() {
+					return errors.New("cannot disable placement rules with witness nodes")
+				}
 			}
 		}
 	}
@@ -1128,7 +1148,7 @@ func (s *Server) campaignLeader() {
 	CheckPDVersion(s.persistOptions)
 	log.Info("PD cluster leader is ready to serve", zap.String("leader-name", s.Name()))
 
-	tsTicker := time.NewTicker(tso.UpdateTimestampStep)
+	tsTicker := time.NewTicker(s.tso.GetUpdatePhysicalInterval())
 	defer tsTicker.Stop()
 	leaderTicker := time.NewTicker(leaderTickInterval)
 	defer leaderTicker.Stop()
@@ -1150,6 +1170,11 @@ func (s *Server) campaignLeader() {
 				log.Error("failed to update timestamp", zap.Error(err))
 				return
 			}
+		case <-s.tso.UpdateNotifier():
+			if err = s.tso.UpdateTimestamp(); err != nil {
+				log.Error("failed to update timestamp in advance", zap.Error(err))
+				return
+			}
 		case <-ctx.Done():
 			// Server is closed and it should return nil.
 			log.Info("server is closed")
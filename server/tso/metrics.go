@@ -31,9 +31,27 @@ var (
 			Name:      "tso",
 			Help:      "Record of tso metadata.",
 		}, []string{"type"})
+
+	tsoLogicalSaturation = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "tso",
+			Name:      "logical_saturation",
+			Help:      "The ratio of the used logical part to the max logical part before updating the physical part.",
+		})
+
+	tsoClockSkew = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "tso",
+			Name:      "clock_skew_seconds",
+			Help:      "The system time minus the physical part of the timestamp, a negative value means the system time is behind.",
+		})
 )
 
 func init() {
 	prometheus.MustRegister(tsoCounter)
 	prometheus.MustRegister(tsoGauge)
+	prometheus.MustRegister(tsoLogicalSaturation)
+	prometheus.MustRegister(tsoClockSkew)
 }
@@ -33,12 +33,23 @@ import (
 )
 
 const (
-	// UpdateTimestampStep is used to update timestamp.
+	// UpdateTimestampStep is the default interval to update timestamp.
 	UpdateTimestampStep  = 50 * time.Millisecond
 	updateTimestampGuard = time.Millisecond
 	maxLogical           = int64(1 << 18)
+	// logicalAdvanceThreshold is the logical part to update the physical part
+	// in advance, it is big enough that the logical part is not used up
+	// before the physical part is updated in common cases.
+	logicalAdvanceThreshold = maxLogical / 2
+	// logicalOverflowRetryInterval is the interval to retry after the logical
+	// part is used up, the physical part is updated in advance meanwhile.
+	logicalOverflowRetryInterval = 5 * time.Millisecond
 )
 
+// errClockBackward is returned if the system time jumps backward beyond the
+// saved time window.
+var errClockBackward = errors.New("system time jumps backward beyond the saved window, resign the leadership")
+
 // TimestampOracle is used to maintain the logic of tso.
 type TimestampOracle struct {
 	// For tso, set after pd becomes leader.
@@ -46,22 +57,52 @@ type TimestampOracle struct {
 	lastSavedTime atomic.Value
 	lease         *member.LeaderLease
 
-	rootPath      string
-	member        string
-	client        *clientv3.Client
-	saveInterval  time.Duration
-	maxResetTSGap func() time.Duration
+	rootPath               string
+	member                 string
+	client                 *clientv3.Client
+	saveInterval           time.Duration
+	updatePhysicalInterval time.Duration
+	maxResetTSGap          func() time.Duration
+
+	// updateCh notifies to update the physical part in advance.
+	updateCh chan struct{}
+	// lastWallTime is the latest system time observed by the leader loop,
+	// it is only accessed by the leader loop.
+	lastWallTime time.Time
+	// clockBackward is 1 if the system time jumps backward beyond the saved
+	// time window, the timestamp is not served until the next sync.
+	clockBackward int32
 }
 
 // NewTimestampOracle creates a new TimestampOracle.
 // TODO: remove saveInterval
-func NewTimestampOracle(client *clientv3.Client, rootPath string, member string, saveInterval time.Duration, maxResetTSGap func() time.Duration) *TimestampOracle {
+func NewTimestampOracle(client *clientv3.Client, rootPath string, member string, saveInterval, updatePhysicalInterval time.Duration, maxResetTSGap func() time.Duration) *TimestampOracle {
 	return &TimestampOracle{
-		rootPath:      rootPath,
-		client:        client,
-		saveInterval:  saveInterval,
-		maxResetTSGap: maxResetTSGap,
-		member:        member,
+		rootPath:               rootPath,
+		client:                 client,
+		saveInterval:           saveInterval,
+		updatePhysicalInterval: updatePhysicalInterval,
+		maxResetTSGap:          maxResetTSGap,
+		member:                 member,
+		updateCh:               make(chan struct{}, 1),
+	}
+}
+
+// GetUpdatePhysicalInterval returns the interval to update the timestamp.
+func (t *TimestampOracle) GetUpdatePhysicalInterval() time.Duration {
+	return t.updatePhysicalInterval
+}
+
+// UpdateNotifier returns a channel to notify updating the timestamp in advance
+// when the logical part is going to be used up.
+func (t *TimestampOracle) UpdateNotifier() <-chan struct{} {
+	return t.updateCh
+}
+
+func (t *TimestampOracle) notifyUpdate() {
+	select {
+	case t.updateCh <- struct{}{}:
+	default:
 	}
 }
 
@@ -116,6 +157,9 @@ func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
 	}
 
 	next := time.Now()
+	// The physical part may be ahead of the system time after syncing, it
+	// is not a clock jump back, so only the system time is compared later.
+	t.lastWallTime = next
 	failpoint.Inject("fallBackSync", func() {
 		next = next.Add(time.Hour)
 	})
@@ -140,6 +184,7 @@ func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
 		physical: next,
 	}
 	t.lease = lease
+	atomic.StoreInt32(&t.clockBackward, 0)
 	atomic.StorePointer(&t.ts, unsafe.Pointer(current))
 
 	return nil
@@ -190,6 +235,11 @@ func (t *TimestampOracle) ResetUserTimestamp(tso uint64) error {
 // 1. The physical time is monotonically increasing.
 // 2. The saved time is monotonically increasing.
 // 3. The physical time is always less than the saved timestamp.
+//
+// If the system time jumps backward by more than `TsoSaveInterval` compared
+// with the last system time it observes, it refuses to serve the timestamp
+// and returns an error to resign the leadership, so another member whose
+// clock is correct can take over.
 func (t *TimestampOracle) UpdateTimestamp() error {
 	prev := (*atomicObject)(atomic.LoadPointer(&t.ts))
 	now := time.Now()
@@ -197,11 +247,15 @@ func (t *TimestampOracle) UpdateTimestamp() error {
 	failpoint.Inject("fallBackUpdate", func() {
 		now = now.Add(time.Hour)
 	})
+	failpoint.Inject("systemTimeJumpBack", func() {
+		now = now.Add(-2 * t.saveInterval)
+	})
 
 	tsoCounter.WithLabelValues("save").Inc()
 
 	jetLag := typeutil.SubTimeByWallClock(now, prev.physical)
-	if jetLag > 3*UpdateTimestampStep {
+	tsoClockSkew.Set(jetLag.Seconds())
+	if jetLag > 3*t.updatePhysicalInterval {
 		log.Warn("clock offset", zap.Duration("jet-lag", jetLag), zap.Time("prev-physical", prev.physical), zap.Time("now", now))
 		tsoCounter.WithLabelValues("slow_save").Inc()
 	}
@@ -209,13 +263,17 @@ func (t *TimestampOracle) UpdateTimestamp() error {
 	if jetLag < 0 {
 		tsoCounter.WithLabelValues("system_time_slow").Inc()
 	}
+	if err := t.checkClockBackward(now); err != nil {
+		return err
+	}
 
 	var next time.Time
 	prevLogical := atomic.LoadInt64(&prev.logical)
+	tsoLogicalSaturation.Set(float64(prevLogical) / float64(maxLogical))
 	// If the system time is greater, it will be synchronized with the system time.
 	if jetLag > updateTimestampGuard {
 		next = now
-	} else if prevLogical > maxLogical/2 {
+	} else if prevLogical > logicalAdvanceThreshold {
 		// The reason choosing maxLogical/2 here is that it's big enough for common cases.
 		// Because there is enough timestamp can be allocated before next update.
 		log.Warn("the logical time may be not enough", zap.Int64("prev-logical", prevLogical))
@@ -247,6 +305,23 @@ func (t *TimestampOracle) UpdateTimestamp() error {
 	return nil
 }
 
+// checkClockBackward refuses to serve the timestamp if the system time jumps
+// backward beyond the saved time window. The physical part is not compared
+// because it may be ahead of the system time after the leader changes.
+func (t *TimestampOracle) checkClockBackward(now time.Time) error {
+	if back := typeutil.SubTimeByWallClock(t.lastWallTime, now); back > t.saveInterval {
+		atomic.StoreInt32(&t.clockBackward, 1)
+		log.Error("system time jumps backward beyond the saved window, stop serving timestamp",
+			zap.Duration("jump-back", back), zap.Time("last", t.lastWallTime), zap.Time("now", now))
+		tsoCounter.WithLabelValues("clock_backward").Inc()
+		return errClockBackward
+	}
+	if now.After(t.lastWallTime) {
+		t.lastWallTime = now
+	}
+	return nil
+}
+
 // ResetTimestamp is used to reset the timestamp.
 func (t *TimestampOracle) ResetTimestamp() {
 	zero := &atomicObject{
@@ -265,11 +340,12 @@ func (t *TimestampOracle) GetRespTS(count uint32) (pdpb.Timestamp, error) {
 		return resp, errors.New("tso count should be positive")
 	}
 
+	retryCount := maxRetryCount
 	failpoint.Inject("skipRetryGetTS", func() {
-		maxRetryCount = 1
+		retryCount = 1
 	})
 
-	for i := 0; i < maxRetryCount; i++ {
+	for i := 0; i < retryCount; i++ {
 		current := (*atomicObject)(atomic.LoadPointer(&t.ts))
 		if current == nil || current.physical == typeutil.ZeroTime {
 			log.Error("we haven't synced timestamp ok, wait and retry", zap.Int("retry-count", i))
@@ -277,6 +353,11 @@ func (t *TimestampOracle) GetRespTS(count uint32) (pdpb.Timestamp, error) {
 			continue
 		}
 
+		if atomic.LoadInt32(&t.clockBackward) == 1 {
+			tsoCounter.WithLabelValues("err_clock_backward").Inc()
+			return pdpb.Timestamp{}, errClockBackward
+		}
+
 		resp.Physical = current.physical.UnixNano() / int64(time.Millisecond)
 		resp.Logical = atomic.AddInt64(&current.logical, int64(count))
 		if resp.Logical >= maxLogical {
@@ -284,9 +365,13 @@ func (t *TimestampOracle) GetRespTS(count uint32) (pdpb.Timestamp, error) {
 				zap.Reflect("response", resp),
 				zap.Int("retry-count", i))
 			tsoCounter.WithLabelValues("logical_overflow").Inc()
-			time.Sleep(UpdateTimestampStep)
+			t.notifyUpdate()
+			time.Sleep(logicalOverflowRetryInterval)
 			continue
 		}
+		if resp.Logical > logicalAdvanceThreshold {
+			t.notifyUpdate()
+		}
 		if t.lease == nil || t.lease.IsExpired() {
 			return pdpb.Timestamp{}, errors.New("alloc timestamp failed, lease expired")
 		}
@@ -15,10 +15,12 @@ package client_test
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -29,9 +31,11 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	pd "github.com/pingcap/pd/v4/client"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/pkg/mock/mockid"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/tests"
 	"go.etcd.io/etcd/clientv3"
@@ -213,6 +217,141 @@ func (s *clientTestSuite) TestCustomTimeout(c *C) {
 	c.Assert(time.Since(start), Less, 2*time.Second)
 }
 
+func (s *clientTestSuite) TestFollowerRead(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3, func(conf *config.Config) { conf.PDServerCfg.UseRegionStorage = true })
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	rc := leaderServer.GetServer().GetRaftCluster()
+	c.Assert(rc, NotNil)
+	region := &metapb.Region{
+		Id:          10,
+		StartKey:    []byte("a"),
+		EndKey:      []byte("b"),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		Peers:       []*metapb.Peer{{Id: 11, StoreId: 1}},
+	}
+	c.Assert(rc.HandleRegionHeartbeat(core.NewRegionInfo(region, region.Peers[0])), IsNil)
+
+	followerServer := cluster.GetServer(cluster.GetFollower())
+	grpcClient := testutil.MustNewGrpcClient(c, followerServer.GetAddr())
+	req := &pdpb.GetRegionRequest{
+		Header:    &pdpb.RequestHeader{ClusterId: leaderServer.GetClusterID()},
+		RegionKey: []byte("a"),
+	}
+	// the follower only serves the follower read requests.
+	_, err = grpcClient.GetRegion(s.ctx, req)
+	c.Assert(err, NotNil)
+	ctx := grpcutil.WithFollowerRead(s.ctx, time.Minute)
+	// the follower is synced after the keepalive message from the leader, but
+	// it does not serve the region because the leader of it is not synced.
+	testutil.WaitUntil(c, func(c *C) bool {
+		_, err := grpcClient.GetRegion(ctx, req)
+		c.Assert(err, NotNil)
+		c.Log(err)
+		return strings.Contains(err.Error(), "region leader")
+	})
+	// the stores are loaded by the follower.
+	testutil.WaitUntil(c, func(c *C) bool {
+		resp, err := grpcClient.GetStore(ctx, &pdpb.GetStoreRequest{
+			Header:  &pdpb.RequestHeader{ClusterId: leaderServer.GetClusterID()},
+			StoreId: 1,
+		})
+		if err != nil {
+			c.Log(err)
+			return false
+		}
+		return resp.GetStore().GetId() == 1
+	})
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClientWithContext(s.ctx, endpoints, pd.SecurityOption{}, pd.WithFollowerRead(time.Minute))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	// the region is read from the leader.
+	r, err := cli.GetRegion(context.TODO(), []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(r.Meta, DeepEquals, region)
+	c.Assert(r.Leader, DeepEquals, region.Peers[0])
+	regions, _, err := cli.ScanRegions(context.TODO(), []byte(""), nil, 10)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	store, err := cli.GetStore(context.TODO(), 1)
+	c.Assert(err, IsNil)
+	c.Assert(store.GetId(), Equals, uint64(1))
+}
+
+func (s *clientTestSuite) TestWatchRegions(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	rc := leaderServer.GetServer().GetRaftCluster()
+	c.Assert(rc, NotNil)
+
+	cli, err := pd.NewClientWithContext(s.ctx, []string{leaderServer.GetAddr()}, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	events, err := cli.WatchRegions(ctx, []byte("b"), []byte("d"))
+	c.Assert(err, IsNil)
+	// wait for the watcher to be bound with the region syncer.
+	time.Sleep(time.Second)
+
+	c.Assert(rc.PutStore(&metapb.Store{Id: 2, Address: "mock://2"}, false), IsNil)
+	newRegion := func(id uint64, start, end string, leader int) *core.RegionInfo {
+		region := &metapb.Region{
+			Id:          id,
+			StartKey:    []byte(start),
+			EndKey:      []byte(end),
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 2},
+			Peers:       []*metapb.Peer{{Id: id + 100, StoreId: 1}, {Id: id + 200, StoreId: 2}},
+		}
+		return core.NewRegionInfo(region, region.Peers[leader])
+	}
+	watch := func(count int) []uint64 {
+		var watched []uint64
+		for len(watched) < count {
+			select {
+			case e := <-events:
+				for _, r := range e {
+					c.Assert(r.Resync, IsFalse)
+					watched = append(watched, r.Region.GetId())
+				}
+			case <-time.After(10 * time.Second):
+				c.Fatal("watch timeout")
+			}
+		}
+		return watched
+	}
+	for i, keys := range [][2]string{{"", "b"}, {"b", "c"}, {"c", "d"}, {"d", ""}} {
+		c.Assert(rc.HandleRegionHeartbeat(newRegion(uint64(i+10), keys[0], keys[1], 0)), IsNil)
+	}
+	c.Assert(watch(2), DeepEquals, []uint64{11, 12})
+	// the leader changes are watched.
+	c.Assert(rc.HandleRegionHeartbeat(newRegion(10, "", "b", 1)), IsNil)
+	c.Assert(rc.HandleRegionHeartbeat(newRegion(12, "c", "d", 1)), IsNil)
+	c.Assert(watch(1), DeepEquals, []uint64{12})
+
+	cancel()
+	for range events {
+	}
+}
+
 func (s *clientTestSuite) waitLeader(c *C, cli client, leader string) {
 	testutil.WaitUntil(c, func(c *C) bool {
 		cli.ScheduleCheckLeader()
@@ -377,6 +516,30 @@ func (s *testClientSuite) TestTSORace(c *C) {
 	wg.Wait()
 }
 
+func (s *testClientSuite) TestTSOStreams(c *C) {
+	cli, err := pd.NewClientWithContext(s.ctx, s.srv.GetEndpoints(), pd.SecurityOption{},
+		pd.WithTSOStreams(4), pd.WithTSOMaxBatchWaitTime(time.Millisecond), pd.WithTSOLatencyHistogram())
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	var wg sync.WaitGroup
+	count := 10
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		go func() {
+			defer wg.Done()
+			var last int64
+			for i := 0; i < 100; i++ {
+				p, l, err := cli.GetTS(context.Background())
+				c.Assert(err, IsNil)
+				c.Assert(p<<18+l, Greater, last)
+				last = p<<18 + l
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func (s *testClientSuite) TestGetRegion(c *C) {
 	regionID := regionIDAllocator.alloc()
 	region := &metapb.Region{
@@ -691,3 +854,38 @@ func (s *testClientSuite) TestScatterRegion(c *C) {
 	})
 	c.Succeed()
 }
+
+func (s *testClientSuite) TestScatterRegions(c *C) {
+	regionIDs := []uint64{regionIDAllocator.alloc(), regionIDAllocator.alloc()}
+	for i, regionID := range regionIDs {
+		req := &pdpb.RegionHeartbeatRequest{
+			Header: newHeader(s.srv),
+			Region: &metapb.Region{
+				Id:          regionID,
+				RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+				StartKey:    []byte{'s', byte('a' + i)},
+				EndKey:      []byte{'s', byte('a' + i), 'z'},
+				Peers:       peers,
+			},
+			Leader: peers[0],
+		}
+		c.Assert(s.regionHeartbeat.Send(req), IsNil)
+	}
+	testutil.WaitUntil(c, func(c *C) bool {
+		for _, regionID := range regionIDs {
+			if s.srv.GetRaftCluster().GetRegion(regionID) == nil {
+				return false
+			}
+		}
+		return true
+	})
+
+	// The regions are sent by a single request, the missing one is reported.
+	missing := regionIDAllocator.alloc()
+	err := s.client.ScatterRegions(context.Background(), append(regionIDs, missing), "t")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, fmt.Sprintf(".*region %d: .*not found.*", missing))
+	for _, regionID := range regionIDs {
+		c.Assert(err.Error(), Not(Matches), fmt.Sprintf(".*region %d: .*", regionID))
+	}
+}
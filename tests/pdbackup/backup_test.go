@@ -17,11 +17,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
 
 	. "github.com/pingcap/check"
+	pd "github.com/pingcap/pd/v4/client"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tools/pd-backup/pdbackup"
@@ -71,3 +73,42 @@ func (s *backupTestSuite) TestBackup(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(backupInfo, DeepEquals, newInfo)
 }
+
+func (s *backupTestSuite) TestBackupMetadata(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leader := cluster.GetServer(cluster.WaitLeader())
+	c.Assert(leader.BootstrapCluster(), IsNil)
+	// The config is persisted once the coordinator runs or it is changed.
+	scheduleCfg := leader.GetPersistOptions().GetScheduleConfig().Clone()
+	c.Assert(leader.GetServer().SetScheduleConfig(*scheduleCfg), IsNil)
+	pdAddr := cluster.GetConfig().GetClientURL()
+	urls := strings.Split(pdAddr, ",")
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   urls,
+		DialTimeout: 3 * time.Second,
+	})
+	c.Assert(err, IsNil)
+	backupInfo, err := pdbackup.GetBackupInfo(client, pdAddr)
+	c.Assert(err, IsNil)
+	c.Assert(backupInfo.Version, Equals, pdbackup.BackupVersion)
+
+	pdClient, err := pd.NewClient(urls, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer pdClient.Close()
+	c.Assert(pdbackup.BackupRegions(ctx, pdClient, backupInfo), IsNil)
+
+	keys := make(map[string]int)
+	for _, kv := range backupInfo.Metadata {
+		keys[kv.Key]++
+	}
+	c.Assert(keys[pdbackup.ClusterMetaKey], Equals, 1)
+	c.Assert(keys["config"], Equals, 1)
+	c.Assert(keys[fmt.Sprintf("raft/s/%020d", 1)], Equals, 1)
+	c.Assert(keys[fmt.Sprintf("raft/r/%020d", 2)], Equals, 1)
+}
@@ -263,6 +263,9 @@ func (s *schedulerTestSuite) TestScheduler(c *C) {
 		"minor-dec-ratio":           0.99,
 		"src-tolerance-ratio":       1.05,
 		"dst-tolerance-ratio":       1.05,
+		"read-priorities":           []interface{}{"byte", "key"},
+		"write-leader-priorities":   []interface{}{"key", "byte"},
+		"write-peer-priorities":     []interface{}{"byte", "key"},
 	}
 	c.Assert(conf, DeepEquals, expected1)
 	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "set", "src-tolerance-ratio", "1.02"}, nil)
@@ -270,4 +273,38 @@ func (s *schedulerTestSuite) TestScheduler(c *C) {
 	var conf1 map[string]interface{}
 	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler"}, &conf1)
 	c.Assert(conf1, DeepEquals, expected1)
+
+	// test priorities
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "set", "read-priorities", "key,byte"}, nil)
+	expected1["read-priorities"] = []interface{}{"key", "byte"}
+	var conf2 map[string]interface{}
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler"}, &conf2)
+	c.Assert(conf2, DeepEquals, expected1)
+	// invalid priorities are rejected
+	for _, priorities := range []string{"query", "query,byte", "byte,byte", "byte,cpu"} {
+		mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "set", "read-priorities", priorities}, nil)
+		var conf3 map[string]interface{}
+		mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler"}, &conf3)
+		c.Assert(conf3, DeepEquals, expected1)
+	}
+
+	// test evict slow store scheduler config
+	mustExec([]string{"-u", pdAddr, "scheduler", "add", "evict-slow-store-scheduler"}, nil)
+	expected2 := map[string]interface{}{
+		"slow-threshold": 1000.,
+		"slow-rounds":    3.,
+		"recover-rounds": 6.,
+		"evicted-stores": []interface{}{},
+	}
+	var conf4 map[string]interface{}
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "evict-slow-store-scheduler"}, &conf4)
+	c.Assert(conf4, DeepEquals, expected2)
+	// a zero slow threshold is rejected
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "evict-slow-store-scheduler", "set", "slow-threshold", "0"}, nil)
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "evict-slow-store-scheduler"}, &conf4)
+	c.Assert(conf4, DeepEquals, expected2)
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "evict-slow-store-scheduler", "set", "slow-threshold", "2000"}, nil)
+	expected2["slow-threshold"] = 2000.
+	mustExec([]string{"-u", pdAddr, "scheduler", "config", "evict-slow-store-scheduler"}, &conf4)
+	c.Assert(conf4, DeepEquals, expected2)
 }
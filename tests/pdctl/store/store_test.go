@@ -211,6 +211,45 @@ func (s *storeTestSuite) TestStore(c *C) {
 	c.Assert(allRemovePeerLimit["3"]["remove-peer"].(float64), Equals, float64(25))
 	c.Assert(allRemovePeerLimit["2"]["remove-peer"].(float64), Equals, float64(25))
 
+	// store limit <store_id> <rate> <snapshot type>
+	args = []string{"-u", pdAddr, "store", "limit", "1", "8", "send-snapshot"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(leaderServer.GetRaftCluster().GetStoreLimitByType(1, storelimit.SendSnapshot), Equals, float64(8))
+	c.Assert(leaderServer.GetRaftCluster().GetStoreLimitByType(1, storelimit.ReceiveSnapshot), Equals, storelimit.Unlimited)
+	args = []string{"-u", pdAddr, "store", "limit", "all", "9", "receive-snapshot"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(leaderServer.GetRaftCluster().GetStoreLimitByType(2, storelimit.ReceiveSnapshot), Equals, float64(9))
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "store", "limit", "send-snapshot"})
+	allSnapshotLimit := make(map[string]map[string]interface{})
+	json.Unmarshal([]byte(echo), &allSnapshotLimit)
+	c.Assert(allSnapshotLimit["1"]["send-snapshot"].(float64), Equals, float64(8))
+	c.Assert(allSnapshotLimit["3"]["receive-snapshot"].(float64), Equals, float64(9))
+
+	// store limit-scene <scene> <rate> <snapshot type>
+	args = []string{"-u", pdAddr, "store", "limit-scene", "idle", "40", "send-snapshot"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "store", "limit-scene", "send-snapshot"})
+	scene := &storelimit.Scene{}
+	c.Assert(json.Unmarshal([]byte(echo), scene), IsNil)
+	c.Assert(scene.Idle, Equals, 40)
+	c.Assert(scene.Normal, Equals, storelimit.DefaultScene(storelimit.SendSnapshot).Normal)
+
+	// store limit-algorithm <store_id> <algorithm>
+	args = []string{"-u", pdAddr, "store", "limit-algorithm", "1", "adaptive"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(leaderServer.GetRaftCluster().GetStoreLimitAlgorithm(1), Equals, storelimit.Adaptive)
+	c.Assert(leaderServer.GetRaftCluster().GetStoreLimitAlgorithm(2), Equals, storelimit.TokenBucket)
+	// the rate is kept
+	c.Assert(leaderServer.GetRaftCluster().GetStoreLimitByType(1, storelimit.RemovePeer), Equals, float64(25))
+	args = []string{"-u", pdAddr, "store", "limit-algorithm", "1", "unknown"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(leaderServer.GetRaftCluster().GetStoreLimitAlgorithm(1), Equals, storelimit.Adaptive)
+
 	// store delete <store_id> command
 	c.Assert(storeInfo.Store.State, Equals, metapb.StoreState_Up)
 	args = []string{"-u", pdAddr, "store", "delete", "1"}
@@ -255,7 +294,7 @@ func (s *storeTestSuite) TestStore(c *C) {
 	args = []string{"-u", pdAddr, "store", "limit-scene"}
 	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
-	scene := &storelimit.Scene{}
+	scene = &storelimit.Scene{}
 	err = json.Unmarshal(output, scene)
 	c.Assert(err, IsNil)
 	c.Assert(scene, DeepEquals, storelimit.DefaultScene(storelimit.AddPeer))
@@ -745,7 +745,7 @@ func (s *clusterTestSuite) TestTiFlashWithPlacementRules(c *C) {
 	_, err = putStore(c, grpcPDClient, clusterID, tiflashStore)
 	c.Assert(err, IsNil)
 	// test TiFlash store limit
-	expect := map[uint64]config.StoreLimitConfig{11: {AddPeer: 30, RemovePeer: 30}}
+	expect := map[uint64]config.StoreLimitConfig{11: {AddPeer: 30, RemovePeer: 30, SendSnapshot: storelimit.Unlimited, ReceiveSnapshot: storelimit.Unlimited}}
 	c.Assert(svr.GetScheduleConfig().StoreLimit, DeepEquals, expect)
 
 	// cannot disable placement rules with TiFlash nodes
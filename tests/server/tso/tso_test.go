@@ -260,6 +260,56 @@ func (s *testTimeFallBackSuite) TestTimeFallBack(c *C) {
 	wg.Wait()
 }
 
+func (s *testTsoSuite) TestClockJumpBack(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1)
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	grpcPDClient := testutil.MustNewGrpcClient(c, leaderServer.GetAddr())
+	req := &pdpb.TsoRequest{Header: testutil.NewRequestHeader(leaderServer.GetClusterID()), Count: 1}
+	getTS := func() (*pdpb.Timestamp, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		tsoClient, err := grpcPDClient.Tso(ctx)
+		c.Assert(err, IsNil)
+		defer tsoClient.CloseSend()
+		c.Assert(tsoClient.Send(req), IsNil)
+		resp, err := tsoClient.Recv()
+		return resp.GetTimestamp(), err
+	}
+	last, err := getTS()
+	c.Assert(err, IsNil)
+
+	// The leader keeps resigning while the system time keeps jumping back,
+	// the requests are not retried until it syncs the timestamp again.
+	c.Assert(failpoint.Enable("github.com/pingcap/pd/v4/server/tso/skipRetryGetTS", `return(true)`), IsNil)
+	c.Assert(failpoint.Enable("github.com/pingcap/pd/v4/server/tso/systemTimeJumpBack", `return(true)`), IsNil)
+	testutil.WaitUntil(c, func(c *C) bool {
+		ts, err := getTS()
+		if err == nil {
+			c.Assert(ts.GetPhysical(), GreaterEqual, last.GetPhysical())
+		}
+		return err != nil
+	})
+
+	failpoint.Disable("github.com/pingcap/pd/v4/server/tso/systemTimeJumpBack")
+	failpoint.Disable("github.com/pingcap/pd/v4/server/tso/skipRetryGetTS")
+	cluster.WaitLeader()
+	testutil.WaitUntil(c, func(c *C) bool {
+		ts, err := getTS()
+		if err != nil {
+			return false
+		}
+		c.Assert(ts.GetPhysical(), Greater, last.GetPhysical())
+		return true
+	})
+}
+
 var _ = Suite(&testFollowerTsoSuite{})
 
 type testFollowerTsoSuite struct {
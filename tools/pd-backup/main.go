@@ -14,12 +14,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	pd "github.com/pingcap/pd/v4/client"
 	"github.com/pingcap/pd/v4/tools/pd-backup/pdbackup"
 	"go.etcd.io/etcd/clientv3"
 	"go.etcd.io/etcd/pkg/transport"
@@ -34,7 +36,8 @@ var (
 )
 
 const (
-	etcdTimeout = 3 * time.Second
+	etcdTimeout   = 3 * time.Second
+	regionTimeout = 5 * time.Minute
 )
 
 func main() {
@@ -61,6 +64,18 @@ func main() {
 
 	backInfo, err := pdbackup.GetBackupInfo(client, *pdAddr)
 	checkErr(err)
+
+	pdClient, err := pd.NewClient(urls, pd.SecurityOption{
+		CAPath:   *caPath,
+		CertPath: *certPath,
+		KeyPath:  *keyPath,
+	})
+	checkErr(err)
+	defer pdClient.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), regionTimeout)
+	defer cancel()
+	checkErr(pdbackup.BackupRegions(ctx, pdClient, backInfo))
+
 	pdbackup.OutputToFile(backInfo, f)
 	fmt.Println("pd backup successful! dump file is:", *filePath)
 }
@@ -38,15 +38,19 @@ const (
 
 // BackupInfo is the backup infos.
 type BackupInfo struct {
+	Version           int            `json:"version,omitempty"`
 	ClusterID         uint64         `json:"clusterID"`
 	AllocIDMax        uint64         `json:"allocIDMax"`
 	AllocTimestampMax uint64         `json:"allocTimestampMax"`
 	Config            *config.Config `json:"config"`
+	// Metadata is the metadata saved in the storage of the cluster, it is
+	// used by pd-recover to restore the cluster.
+	Metadata []*MetaKV `json:"metadata,omitempty"`
 }
 
 //GetBackupInfo return the BackupInfo
 func GetBackupInfo(client *clientv3.Client, pdAddr string) (*BackupInfo, error) {
-	backInfo := &BackupInfo{}
+	backInfo := &BackupInfo{Version: BackupVersion}
 	resp, err := etcdutil.EtcdKVGet(client, pdClusterIDPath)
 	if err != nil {
 		return nil, err
@@ -84,6 +88,11 @@ func GetBackupInfo(client *clientv3.Client, pdAddr string) (*BackupInfo, error)
 	}
 	backInfo.AllocTimestampMax = allocTimestampMax
 
+	backInfo.Metadata, err = getMetadata(client, rootPath)
+	if err != nil {
+		return nil, err
+	}
+
 	backInfo.Config, err = getConfig(pdAddr)
 	if err != nil {
 		return nil, err
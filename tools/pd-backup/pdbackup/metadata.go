@@ -0,0 +1,155 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdbackup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	pd "github.com/pingcap/pd/v4/client"
+	"github.com/pingcap/pd/v4/pkg/etcdutil"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// BackupVersion is the version of the backup archive. The archives without
+// version only contain the cluster ID, the allocated ID, the timestamp and the
+// config.
+const BackupVersion = 2
+
+const (
+	metaPageSize   = 512
+	regionPageSize = 1024
+)
+
+// ClusterMetaKey is the key of the cluster meta, it is restored at last as
+// the flag of a bootstrapped cluster.
+const ClusterMetaKey = "raft"
+
+// metaKeys are the keys and key prefixes of core.Storage, relative to the
+// root path of the cluster.
+var (
+	metaKeys     = []string{ClusterMetaKey, "config"}
+	metaPrefixes = []string{
+		"raft/",             // stores, regions and raft status
+		"schedule/",         // store weights
+		"scheduler_config/", // configs of the schedulers
+		"gc/",               // GC safe point and service safe points
+		"rules/",            // placement rules
+		"rule_group/",       // placement rule groups
+		"replication_mode/", // status of the replication mode
+	}
+)
+
+// MetaKV is a key-value pair of the metadata. Key is relative to the root path
+// of the cluster.
+type MetaKV struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+func getMetadata(client *clientv3.Client, rootPath string) ([]*MetaKV, error) {
+	var kvs []*MetaKV
+	for _, key := range metaKeys {
+		resp, err := etcdutil.EtcdKVGet(client, path.Join(rootPath, key))
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range resp.Kvs {
+			kvs = append(kvs, &MetaKV{Key: key, Value: kv.Value})
+		}
+	}
+	for _, prefix := range metaPrefixes {
+		prefixKVs, err := getMetadataWithPrefix(client, rootPath, prefix)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, prefixKVs...)
+	}
+	return kvs, nil
+}
+
+func getMetadataWithPrefix(client *clientv3.Client, rootPath, prefix string) ([]*MetaKV, error) {
+	var kvs []*MetaKV
+	key := path.Join(rootPath, prefix) + "/"
+	endKey := clientv3.GetPrefixRangeEnd(key)
+	for {
+		resp, err := etcdutil.EtcdKVGet(client, key, clientv3.WithRange(endKey), clientv3.WithLimit(metaPageSize))
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range resp.Kvs {
+			kvs = append(kvs, &MetaKV{
+				Key:   strings.TrimPrefix(string(kv.Key), rootPath+"/"),
+				Value: kv.Value,
+			})
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return kvs, nil
+		}
+		key = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// BackupRegions adds the meta of all regions into the backup. The regions may
+// be saved in the region storage instead of etcd, so they are scanned from PD.
+func BackupRegions(ctx context.Context, client pd.Client, backInfo *BackupInfo) error {
+	saved := make(map[string]struct{}, len(backInfo.Metadata))
+	for _, kv := range backInfo.Metadata {
+		saved[kv.Key] = struct{}{}
+	}
+	var key []byte
+	for {
+		regions, _, err := client.ScanRegions(ctx, key, nil, regionPageSize)
+		if err != nil {
+			return err
+		}
+		if len(regions) == 0 {
+			return nil
+		}
+		for _, region := range regions {
+			regionKey := fmt.Sprintf("raft/r/%020d", region.GetId())
+			if _, ok := saved[regionKey]; ok {
+				continue
+			}
+			value, err := region.Marshal()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			backInfo.Metadata = append(backInfo.Metadata, &MetaKV{Key: regionKey, Value: value})
+			saved[regionKey] = struct{}{}
+		}
+		key = regions[len(regions)-1].GetEndKey()
+		if len(key) == 0 {
+			return nil
+		}
+	}
+}
+
+// LoadFromFile loads the backup from the file.
+func LoadFromFile(filePath string) (*BackupInfo, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	backInfo := &BackupInfo{}
+	if err := json.Unmarshal(data, backInfo); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return backInfo, nil
+}
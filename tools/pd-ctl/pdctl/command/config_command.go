@@ -16,7 +16,6 @@ package command
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"path"
@@ -37,6 +36,8 @@ var (
 	clusterVersionPrefix  = "pd/api/v1/config/cluster-version"
 	rulesPrefix           = "pd/api/v1/config/rules"
 	rulePrefix            = "pd/api/v1/config/rule"
+	ruleGroupPrefix       = "pd/api/v1/config/rule_group"
+	ruleGroupsPrefix      = "pd/api/v1/config/rule_groups"
 	replicationModePrefix = "pd/api/v1/config/replication-mode"
 )
 
@@ -424,11 +425,37 @@ func NewPlacementRulesCommand() *cobra.Command {
 	load.Flags().String("out", "rules.json", "the filename contains rules")
 	save := &cobra.Command{
 		Use:   "save",
-		Short: "save rules from file",
+		Short: "save rules from file, rules with zero count are deleted",
 		Run:   putPlacementRulesFunc,
 	}
 	save.Flags().String("in", "rules.json", "the filename contains rules")
-	c.AddCommand(enable, disable, show, load, save)
+	plan := &cobra.Command{
+		Use:   "plan",
+		Short: "estimate the impact of saving rules from file without applying them",
+		Run:   planPlacementRulesFunc,
+	}
+	plan.Flags().String("in", "rules.json", "the filename contains rules")
+	ruleGroup := &cobra.Command{
+		Use:   "rule-group",
+		Short: "rule group configurations",
+	}
+	groupShow := &cobra.Command{
+		Use:   "show [id]",
+		Short: "show rule group configuration(s)",
+		Run:   showRuleGroupFunc,
+	}
+	groupSet := &cobra.Command{
+		Use:   "set <id> <index> <override>",
+		Short: "update rule group configuration",
+		Run:   updateRuleGroupFunc,
+	}
+	groupDel := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete rule group configuration",
+		Run:   delRuleGroupFunc,
+	}
+	ruleGroup.AddCommand(groupShow, groupSet, groupDel)
+	c.AddCommand(enable, disable, show, load, save, plan, ruleGroup)
 	return c
 }
 
@@ -498,40 +525,121 @@ func getPlacementRulesFunc(cmd *cobra.Command, args []string) {
 }
 
 func putPlacementRulesFunc(cmd *cobra.Command, args []string) {
+	file, b, err := readRuleOpsFromFile(cmd)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	_, err = doRequest(cmd, path.Join(rulesPrefix, "batch"), http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
+	if err != nil {
+		cmd.Printf("failed to save rules %s: %s\n", file, err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func planPlacementRulesFunc(cmd *cobra.Command, args []string) {
+	file, b, err := readRuleOpsFromFile(cmd)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	res, err := doRequest(cmd, path.Join(rulesPrefix, "plan"), http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
+	if err != nil {
+		cmd.Printf("failed to plan rules %s: %s\n", file, err)
+		return
+	}
+	cmd.Println(res)
+}
+
+// readRuleOpsFromFile reads rules from the file specified by the "in" flag and
+// returns them as encoded rule operations.
+func readRuleOpsFromFile(cmd *cobra.Command) (string, []byte, error) {
 	var file string
 	if f := cmd.Flag("in"); f != nil {
 		file = f.Value.String()
 	}
 	content, err := ioutil.ReadFile(file)
 	if err != nil {
-		cmd.Println(err)
-		return
+		return file, nil, err
 	}
 	var rules []*placement.Rule
 	if err = json.Unmarshal(content, &rules); err != nil {
-		cmd.Println(err)
-		return
+		return file, nil, err
 	}
+	// Rules with zero count are deleted, others are added or updated. All of
+	// them are applied in one batch so the file takes effect atomically.
+	opts := make([]placement.RuleOp, 0, len(rules))
 	for _, r := range rules {
 		if r.Count > 0 {
-			b, _ := json.Marshal(r)
-			_, err = doRequest(cmd, rulePrefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
-			if err != nil {
-				fmt.Printf("failed to save rule %s/%s: %v\n", r.GroupID, r.ID, err)
-				return
-			}
-			fmt.Printf("saved rule %s/%s\n", r.GroupID, r.ID)
+			opts = append(opts, placement.RuleOp{Action: placement.RuleOpAdd, Rule: r})
+		} else {
+			opts = append(opts, placement.RuleOp{Action: placement.RuleOpDel, Rule: r})
 		}
 	}
-	for _, r := range rules {
-		if r.Count == 0 {
-			_, err = doRequest(cmd, path.Join(rulePrefix, r.GroupID, r.ID), http.MethodDelete)
-			if err != nil {
-				fmt.Printf("failed to delete rule %s/%s: %v\n", r.GroupID, r.ID, err)
-				return
-			}
-			fmt.Printf("deleted rule %s/%s\n", r.GroupID, r.ID)
-		}
+	b, err := json.Marshal(opts)
+	return file, b, err
+}
+
+func showRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	reqPath := ruleGroupsPrefix
+	if len(args) > 0 {
+		reqPath = path.Join(ruleGroupPrefix, args[0])
+	}
+	res, err := doRequest(cmd, reqPath, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func updateRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 3 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	index, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		cmd.Printf("index %s should be a number\n", args[1])
+		return
+	}
+	var override bool
+	switch strings.ToLower(args[2]) {
+	case "false":
+	case "true":
+		override = true
+	default:
+		cmd.Printf("override %s should be a boolean\n", args[2])
+		return
+	}
+	b, _ := json.Marshal(&placement.RuleGroup{
+		ID:       args[0],
+		Index:    int(index),
+		Override: override,
+	})
+	_, err = doRequest(cmd, ruleGroupPrefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func delRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	_, err := doRequest(cmd, path.Join(ruleGroupPrefix, args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Println(err)
+		return
 	}
 	cmd.Println("Success!")
 }
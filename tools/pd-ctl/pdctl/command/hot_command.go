@@ -15,6 +15,7 @@ package command
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/spf13/cobra"
 )
@@ -23,6 +24,7 @@ const (
 	hotReadRegionsPrefix  = "pd/api/v1/hotspot/regions/read"
 	hotWriteRegionsPrefix = "pd/api/v1/hotspot/regions/write"
 	hotStoresPrefix       = "pd/api/v1/hotspot/stores"
+	hotHistoryPrefix      = "pd/api/v1/hotspot/regions/history"
 )
 
 // NewHotSpotCommand return a hot subcommand of rootCmd
@@ -34,6 +36,7 @@ func NewHotSpotCommand() *cobra.Command {
 	cmd.AddCommand(NewHotWriteRegionCommand())
 	cmd.AddCommand(NewHotReadRegionCommand())
 	cmd.AddCommand(NewHotStoreCommand())
+	cmd.AddCommand(NewHotHistoryCommand())
 	return cmd
 }
 
@@ -93,3 +96,41 @@ func showHotStoresCommandFunc(cmd *cobra.Command, args []string) {
 	}
 	cmd.Println(r)
 }
+
+// NewHotHistoryCommand return a hot history subcommand of hotSpotCmd
+func NewHotHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history [--start=<timestamp>] [--end=<timestamp>] [--region=<region_id>] [--store=<store_id>] [--hot_type=<read|write>]",
+		Short: "show the hot regions recorded in the history",
+		Run:   showHotHistoryCommandFunc,
+	}
+	cmd.Flags().String("start", "", "start unix timestamp, inclusive")
+	cmd.Flags().String("end", "", "end unix timestamp, exclusive")
+	cmd.Flags().String("region", "", "region id")
+	cmd.Flags().String("store", "", "store id")
+	cmd.Flags().String("hot_type", "", "hot region type, read or write")
+	return cmd
+}
+
+func showHotHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for _, name := range []string{"start", "end", "region", "store", "hot_type"} {
+		if value, _ := cmd.Flags().GetString(name); value != "" {
+			query.Set(name, value)
+		}
+	}
+	path := hotHistoryPrefix
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get hotspot history: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
@@ -16,6 +16,7 @@ package command
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -36,9 +37,48 @@ func NewOperatorCommand() *cobra.Command {
 	c.AddCommand(NewCheckOperatorCommand())
 	c.AddCommand(NewAddOperatorCommand())
 	c.AddCommand(NewRemoveOperatorCommand())
+	c.AddCommand(NewOperatorHistoryCommand())
 	return c
 }
 
+// NewOperatorHistoryCommand returns a command to show finished operators.
+func NewOperatorHistoryCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "history [--start=<timestamp>] [--end=<timestamp>] [--region=<region_id>] [--store=<store_id>] [--kind=<kind>]",
+		Short: "show the records of finished operators",
+		Run:   operatorHistoryCommandFunc,
+	}
+	c.Flags().String("start", "", "start unix timestamp, inclusive")
+	c.Flags().String("end", "", "end unix timestamp, exclusive")
+	c.Flags().String("region", "", "region id")
+	c.Flags().String("store", "", "store id")
+	c.Flags().String("kind", "", "operator kind, such as leader or region")
+	return c
+}
+
+func operatorHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for _, name := range []string{"start", "end", "region", "store", "kind"} {
+		if value, _ := cmd.Flags().GetString(name); value != "" {
+			query.Set(name, value)
+		}
+	}
+	path := operatorsPrefix + "/history"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
 // NewCheckOperatorCommand returns a command to show status of the operator.
 func NewCheckOperatorCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -118,6 +118,7 @@ func NewAddSchedulerCommand() *cobra.Command {
 	}
 	c.AddCommand(NewGrantLeaderSchedulerCommand())
 	c.AddCommand(NewEvictLeaderSchedulerCommand())
+	c.AddCommand(NewEvictSlowStoreSchedulerCommand())
 	c.AddCommand(NewShuffleLeaderSchedulerCommand())
 	c.AddCommand(NewShuffleRegionSchedulerCommand())
 	c.AddCommand(NewShuffleHotRegionSchedulerCommand())
@@ -151,6 +152,16 @@ func NewEvictLeaderSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// NewEvictSlowStoreSchedulerCommand returns a command to add a evict-slow-store-scheduler.
+func NewEvictSlowStoreSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "evict-slow-store-scheduler",
+		Short: "add a scheduler to detect slow stores and evict their leaders",
+		Run:   addSchedulerCommandFunc,
+	}
+	return c
+}
+
 func checkSchedulerExist(cmd *cobra.Command, schedulerName string) (bool, error) {
 	r, err := doRequest(cmd, schedulersPrefix, http.MethodGet)
 	if err != nil {
@@ -423,6 +434,7 @@ func NewConfigSchedulerCommand() *cobra.Command {
 		newConfigGrantLeaderCommand(),
 		newConfigHotRegionCommand(),
 		newConfigShuffleRegionCommand(),
+		newConfigEvictSlowStoreCommand(),
 	)
 	return c
 }
@@ -444,6 +456,23 @@ func newConfigHotRegionCommand() *cobra.Command {
 	return c
 }
 
+func newConfigEvictSlowStoreCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "evict-slow-store-scheduler",
+		Short: "evict-slow-store-scheduler config",
+		Run:   listSchedulerConfigCommandFunc,
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "list the config item",
+		Run:   listSchedulerConfigCommandFunc})
+	c.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "set the config item",
+		Run:   func(cmd *cobra.Command, args []string) { postSchedulerConfigCommandFunc(cmd, c.Name(), args) }})
+	return c
+}
+
 func newConfigEvictLeaderCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "evict-leader-scheduler",
@@ -545,6 +574,9 @@ func postSchedulerConfigCommandFunc(cmd *cobra.Command, schedulerName string, ar
 	if err != nil {
 		val = value
 	}
+	if strings.HasSuffix(key, "-priorities") {
+		val = strings.Split(value, ",")
+	}
 	input[key] = val
 	postJSON(cmd, path.Join(schedulerConfigPrefix, schedulerName, "config"), input)
 }
@@ -41,6 +41,7 @@ func NewStoreCommand() *cobra.Command {
 	s.AddCommand(NewStoreLimitCommand())
 	s.AddCommand(NewRemoveTombStoneCommand())
 	s.AddCommand(NewStoreLimitSceneCommand())
+	s.AddCommand(NewStoreLimitAlgorithmCommand())
 	s.Flags().String("jq", "", "jq query")
 	return s
 }
@@ -91,7 +92,7 @@ func NewStoreLimitCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "limit [<type>]|[<store_id>|<all> <limit> <type>]",
 		Short: "show or set a store's rate limit",
-		Long:  "show or set a store's rate limit, <type> can be 'add-peer'(default) or 'remove-peer'",
+		Long:  "show or set a store's rate limit, <type> can be 'add-peer'(default), 'remove-peer', 'send-snapshot' or 'receive-snapshot'",
 		Run:   storeLimitCommandFunc,
 	}
 	return c
@@ -180,11 +181,36 @@ func NewStoreLimitSceneCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "limit-scene [<type>]|[<scene> <rate> <type>]",
 		Short: "show or set the limit value for a scene",
-		Long:  "show or set the limit value for a scene, <type> can be 'add-peer'(default) or 'remove-peer'",
+		Long:  "show or set the limit value for a scene, <type> can be 'add-peer'(default), 'remove-peer', 'send-snapshot' or 'receive-snapshot'",
 		Run:   storeLimitSceneCommandFunc,
 	}
 }
 
+// NewStoreLimitAlgorithmCommand returns a limit-algorithm command for store command
+func NewStoreLimitAlgorithmCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "limit-algorithm <store_id> <algorithm>",
+		Short: "set the store limit algorithm of a store",
+		Long:  "set the store limit algorithm of a store, <algorithm> can be 'token-bucket' or 'adaptive'",
+		Run:   storeLimitAlgorithmCommandFunc,
+	}
+}
+
+func storeLimitAlgorithmCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Usage()
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		cmd.Println("store_id should be a number")
+		return
+	}
+	prefix := fmt.Sprintf(path.Join(storePrefix, "limit"), args[0])
+	postJSON(cmd, prefix, map[string]interface{}{
+		"algorithm": args[1],
+	})
+}
+
 func storeLimitSceneCommandFunc(cmd *cobra.Command, args []string) {
 	var resp string
 	var err error
@@ -219,7 +245,7 @@ func storeLimitSceneCommandFunc(cmd *cobra.Command, args []string) {
 			return
 		}
 		if len(args) == 3 {
-			prefix = path.Join(prefix, fmt.Sprintf("?type=%s", args[2]))
+			prefix += fmt.Sprintf("?type=%s", args[2])
 		}
 		postJSON(cmd, prefix, map[string]interface{}{scene: rate})
 	}
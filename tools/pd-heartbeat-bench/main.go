@@ -18,10 +18,13 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/tools/regions-dump/regionsdump"
 	"go.etcd.io/etcd/pkg/report"
 	"google.golang.org/grpc"
 )
@@ -35,6 +38,15 @@ var (
 	regionUpdateRatio = flag.Float64("region-update-ratio", 0.05, "ratio of the region need to update")
 	sample            = flag.Bool("sample", false, "sample per second")
 	heartbeatRounds   = flag.Int("heartbeat-rounds", 5, "total rounds of hearbeat")
+	regionsFile       = flag.String("regions-file", "", "JSON file dumped by regions-dump, the stores and regions in it are used instead of the generated ones")
+	workloadName      = flag.String("workload", workloadUniform, "workload profile, uniform, hot-spot, split-storm or leader-churn")
+	hotBytes          = flag.Uint64("hot-bytes", 64*1024*1024, "bytes written of the hottest region of each stream in the hot-spot workload")
+	hotSkew           = flag.Float64("hot-skew", 1.2, "exponent of the Zipf's law the write flow follows in the hot-spot workload")
+	splitRatio        = flag.Float64("split-ratio", 0.01, "ratio of the regions to split every round in the split-storm workload")
+	leaderChurnRatio  = flag.Float64("leader-churn-ratio", 0.05, "ratio of the regions to transfer leader every round in the leader-churn workload")
+	concurrency       = flag.Int("concurrency", 1, "count of the heartbeat streams of each store")
+	storeHeartbeat    = flag.Bool("store-heartbeat", true, "send store heartbeats with varying stats every round")
+	reportFile        = flag.String("report-file", "", "file to write the latency percentiles of each round as JSON")
 )
 
 var clusterID uint64
@@ -101,8 +113,8 @@ func bootstrap(cli pdpb.PDClient) {
 	log.Println("bootstrapped")
 }
 
-func putStores(cli pdpb.PDClient) {
-	for i := uint64(1); i <= uint64(*storeCount); i++ {
+func putStores(cli pdpb.PDClient, storeIDs []uint64) {
+	for _, i := range storeIDs {
 		store := &metapb.Store{
 			Id:      i,
 			Address: fmt.Sprintf("localhost:%d", i),
@@ -126,33 +138,20 @@ func newEndKey(id uint64) []byte {
 	return k
 }
 
-// Store simulates a TiKV to heartbeat.
-type Store struct {
-	id uint64
-}
-
-// Run runs the store.
-func (s *Store) Run(startNotifier chan report.Report, endNotifier chan struct{}) {
-	cli := newClient()
-	stream, err := cli.RegionHeartbeat(context.TODO())
-	if err != nil {
-		log.Fatal(err)
-	}
-	var peers []*metapb.Peer
-	for i := 0; i < *replica; i++ {
-		storeID := s.id + uint64(i)
-		if storeID > uint64(*storeCount) {
-			storeID -= uint64(*storeCount)
+// newRegions generates the regions and groups them by the leader stores, it
+// returns the max ID used.
+func newRegions() (map[uint64][]*region, uint64) {
+	regions := make(map[uint64][]*region)
+	for id := uint64(1); id <= uint64(*storeCount); id++ {
+		var peers []*metapb.Peer
+		for i := 0; i < *replica; i++ {
+			storeID := id + uint64(i)
+			if storeID > uint64(*storeCount) {
+				storeID -= uint64(*storeCount)
+			}
+			peers = append(peers, &metapb.Peer{Id: uint64(i + 1), StoreId: storeID})
 		}
-		peers = append(peers, &metapb.Peer{Id: uint64(i + 1), StoreId: storeID})
-	}
-
-	count := 1
-	for r := range startNotifier {
-		startTime := time.Now()
-		for regionID := s.id; regionID <= *regionCount+uint64(*storeCount); regionID += uint64(*storeCount) {
-			updateRegionCount := uint64(float64(*regionCount) * (*regionUpdateRatio) / float64(*storeCount))
-			storeUpdateRegionMaxID := s.id + updateRegionCount*uint64(*storeCount)
+		for regionID := id; regionID <= *regionCount+uint64(*storeCount); regionID += uint64(*storeCount) {
 			meta := &metapb.Region{
 				Id:          regionID,
 				Peers:       peers,
@@ -160,41 +159,260 @@ func (s *Store) Run(startNotifier chan report.Report, endNotifier chan struct{})
 				StartKey:    newStartKey(regionID),
 				EndKey:      newEndKey(regionID),
 			}
-			if regionID < storeUpdateRegionMaxID {
-				meta.RegionEpoch.Version = uint64(count)
+			regions[id] = append(regions[id], newRegion(meta, id))
+		}
+	}
+	return regions, *regionCount + uint64(*storeCount)
+}
+
+// loadRegions loads the regions of the regions file and groups them by the
+// leader stores. The first voter is the leader as the leader is not dumped.
+func loadRegions(path string) ([]uint64, map[uint64][]*region, uint64) {
+	f, err := regionsdump.LoadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	metas, err := f.Metas()
+	if err != nil {
+		log.Fatal(err)
+	}
+	regions := make(map[uint64][]*region)
+	var maxID uint64
+	for _, meta := range metas {
+		if meta.GetId() > maxID {
+			maxID = meta.GetId()
+		}
+		var leader *metapb.Peer
+		for _, p := range meta.GetPeers() {
+			if leader == nil && !p.GetIsLearner() {
+				leader = p
 			}
-			reqStart := time.Now()
-			err = stream.Send(&pdpb.RegionHeartbeatRequest{
-				Header: header(),
-				Region: meta,
-				Leader: peers[0],
-			})
-
-			r.Results() <- report.Result{Start: reqStart, End: time.Now(), Err: err}
-			if err != nil {
-				log.Fatal(err)
+			if p.GetId() > maxID {
+				maxID = p.GetId()
 			}
 		}
+		if leader != nil {
+			regions[leader.GetStoreId()] = append(regions[leader.GetStoreId()], newRegion(meta, leader.GetStoreId()))
+		}
+	}
+	return f.Stores, regions, maxID
+}
+
+// Store simulates a TiKV to heartbeat.
+type Store struct {
+	id       uint64
+	cli      pdpb.PDClient
+	streams  []*regionStream
+	workload *workload
+	recorder *latencyRecorder
+	rand     *rand.Rand
+}
+
+func newStore(id uint64, regions []*region, w *workload, recorder *latencyRecorder) *Store {
+	s := &Store{
+		id:       id,
+		cli:      newClient(),
+		workload: w,
+		recorder: recorder,
+		rand:     rand.New(rand.NewSource(int64(id))),
+	}
+	// the regions are distributed to the streams in a round-robin way.
+	for i := 0; i < *concurrency; i++ {
+		stream, err := s.cli.RegionHeartbeat(context.TODO())
+		if err != nil {
+			log.Fatal(err)
+		}
+		rs := &regionStream{
+			stream:   stream,
+			recorder: recorder,
+			rand:     rand.New(rand.NewSource(int64(id)*int64(*concurrency) + int64(i))),
+			sendTime: make(map[uint64]time.Time),
+		}
+		for j := i; j < len(regions); j += *concurrency {
+			rs.regions = append(rs.regions, regions[j])
+		}
+		go rs.receive()
+		s.streams = append(s.streams, rs)
+	}
+	return s
+}
+
+func (s *Store) regionCount() int {
+	var count int
+	for _, rs := range s.streams {
+		count += len(rs.regions)
+	}
+	return count
+}
+
+// Run runs the store.
+func (s *Store) Run(startNotifier chan report.Report, endNotifier chan struct{}) {
+	round := 0
+	for r := range startNotifier {
+		startTime := time.Now()
+		if *storeHeartbeat {
+			s.heartbeat()
+		}
+		var wg sync.WaitGroup
+		for _, rs := range s.streams {
+			wg.Add(1)
+			go func(rs *regionStream) {
+				defer wg.Done()
+				rs.regions = s.workload.step(round, rs.regions, rs.rand)
+				rs.heartbeat(r)
+			}(rs)
+		}
+		wg.Wait()
 		log.Printf("store %v finish heartbeat, cost time: %v", s.id, time.Since(startTime))
-		count++
+		round++
 		endNotifier <- struct{}{}
 	}
 }
 
+// heartbeat sends a store heartbeat with varying stats.
+func (s *Store) heartbeat() {
+	const capacity = 4 * 1024 * 1024 * 1024 * 1024
+	regionCount := s.regionCount()
+	used := uint64(float64(regionCount) * regionSize * (0.5 + s.rand.Float64()))
+	if used > capacity {
+		used = capacity
+	}
+	var bytesWritten, keysWritten uint64
+	for _, rs := range s.streams {
+		for _, r := range rs.regions {
+			bytesWritten += r.bytesWritten
+			keysWritten += r.keysWritten
+		}
+	}
+	now := uint64(time.Now().Unix())
+	stats := &pdpb.StoreStats{
+		StoreId:            s.id,
+		Capacity:           capacity,
+		Available:          capacity - used,
+		UsedSize:           used,
+		RegionCount:        uint32(regionCount),
+		SendingSnapCount:   uint32(s.rand.Intn(3)),
+		ReceivingSnapCount: uint32(s.rand.Intn(3)),
+		ApplyingSnapCount:  uint32(s.rand.Intn(2)),
+		BytesWritten:       bytesWritten,
+		KeysWritten:        keysWritten,
+		BytesRead:          uint64(s.rand.Int63n(64 * 1024 * 1024)),
+		KeysRead:           uint64(s.rand.Int63n(64 * 1024)),
+		Interval:           &pdpb.TimeInterval{StartTimestamp: now - 10, EndTimestamp: now},
+	}
+	start := time.Now()
+	_, err := s.cli.StoreHeartbeat(context.TODO(), &pdpb.StoreHeartbeatRequest{Header: header(), Stats: stats})
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.recorder.record(phaseStoreHeartbeat, time.Since(start))
+}
+
+// regionStream sends the region heartbeats of a part of the regions.
+type regionStream struct {
+	stream   pdpb.PD_RegionHeartbeatClient
+	regions  []*region
+	recorder *latencyRecorder
+	rand     *rand.Rand
+
+	mu sync.Mutex
+	// sendTime is the time of the last heartbeat of each region.
+	sendTime map[uint64]time.Time
+}
+
+func (rs *regionStream) heartbeat(r report.Report) {
+	now := uint64(time.Now().Unix())
+	for _, region := range rs.regions {
+		req := &pdpb.RegionHeartbeatRequest{
+			Header:          header(),
+			Region:          region.meta,
+			Leader:          region.getLeader(),
+			Term:            region.term,
+			BytesWritten:    region.bytesWritten,
+			KeysWritten:     region.keysWritten,
+			ApproximateSize: regionSize,
+			ApproximateKeys: regionKeys,
+			Interval:        &pdpb.TimeInterval{StartTimestamp: now - 60, EndTimestamp: now},
+		}
+		reqStart := time.Now()
+		rs.mu.Lock()
+		rs.sendTime[region.meta.GetId()] = reqStart
+		rs.mu.Unlock()
+		err := rs.stream.Send(req)
+		reqEnd := time.Now()
+		r.Results() <- report.Result{Start: reqStart, End: reqEnd, Err: err}
+		if err != nil {
+			log.Fatal(err)
+		}
+		rs.recorder.record(phaseRegionHeartbeat, reqEnd.Sub(reqStart))
+	}
+}
+
+// receive receives the responses of the region heartbeats, PD only responds
+// the regions with operators.
+func (rs *regionStream) receive() {
+	for {
+		resp, err := rs.stream.Recv()
+		if err != nil {
+			log.Println("receive region heartbeat response failed:", err)
+			return
+		}
+		rs.mu.Lock()
+		sendTime, ok := rs.sendTime[resp.GetRegionId()]
+		delete(rs.sendTime, resp.GetRegionId())
+		rs.mu.Unlock()
+		if ok {
+			rs.recorder.record(phaseOperator, time.Since(sendTime))
+		}
+	}
+}
+
 func main() {
 	log.SetFlags(0)
 	flag.Parse()
+	if !isValidWorkload(*workloadName) {
+		log.Fatalf("unknown workload %s", *workloadName)
+	}
+	if *concurrency < 1 {
+		log.Fatal("concurrency should be at least 1")
+	}
 
 	cli := newClient()
 	initClusterID(cli)
 	bootstrap(cli)
-	putStores(cli)
 
+	var (
+		storeIDs []uint64
+		regions  map[uint64][]*region
+		maxID    uint64
+	)
+	if *regionsFile != "" {
+		storeIDs, regions, maxID = loadRegions(*regionsFile)
+	} else {
+		for i := 1; i <= *storeCount; i++ {
+			storeIDs = append(storeIDs, uint64(i))
+		}
+		regions, maxID = newRegions()
+	}
+	putStores(cli, storeIDs)
 	log.Println("finish put stores")
-	groupStartNotify := make([]chan report.Report, *storeCount+1)
-	groupEndNotify := make([]chan struct{}, *storeCount+1)
-	for i := 1; i <= *storeCount; i++ {
-		s := Store{id: uint64(i)}
+
+	w := &workload{
+		name:             *workloadName,
+		updateRatio:      *regionUpdateRatio,
+		hotBytes:         *hotBytes,
+		hotSkew:          *hotSkew,
+		splitRatio:       *splitRatio,
+		leaderChurnRatio: *leaderChurnRatio,
+		ids:              &idAllocator{id: maxID},
+	}
+	recorder := newLatencyRecorder()
+	stores := make([]*Store, 0, len(storeIDs))
+	groupStartNotify := make([]chan report.Report, len(storeIDs))
+	groupEndNotify := make([]chan struct{}, len(storeIDs))
+	for i, id := range storeIDs {
+		s := newStore(id, regions[id], w, recorder)
+		stores = append(stores, s)
 		startNotifier := make(chan report.Report)
 		endNotifier := make(chan struct{})
 		groupStartNotify[i] = startNotifier
@@ -202,21 +420,46 @@ func main() {
 		go s.Run(startNotifier, endNotifier)
 	}
 
+	benchReport := &BenchReport{
+		Workload:    *workloadName,
+		Stores:      len(storeIDs),
+		Concurrency: *concurrency,
+	}
 	for i := 0; i < *heartbeatRounds; i++ {
 		log.Printf("\n--------- Bench heartbeat (Round %d) ----------\n", i+1)
 		report := newReport()
 		rs := report.Run()
+		startTime := time.Now()
 		// All stores start heartbeat.
-		for storeID := 1; storeID <= *storeCount; storeID++ {
-			startNotifier := groupStartNotify[storeID]
+		for _, startNotifier := range groupStartNotify {
 			startNotifier <- report
 		}
 		// All stores finished hearbeat once.
-		for storeID := 1; storeID <= *storeCount; storeID++ {
-			<-groupEndNotify[storeID]
+		for _, endNotifier := range groupEndNotify {
+			<-endNotifier
 		}
 
 		close(report.Results())
 		log.Println(<-rs)
+
+		var regionCount int
+		for _, s := range stores {
+			regionCount += s.regionCount()
+		}
+		roundReport := recorder.finishRound(i+1, regionCount, time.Since(startTime))
+		for _, phase := range []string{phaseRegionHeartbeat, phaseStoreHeartbeat, phaseOperator} {
+			if p, ok := roundReport.Phases[phase]; ok {
+				log.Printf("%s: count %d, avg %.4fms, p50 %.4fms, p99 %.4fms, max %.4fms", phase, p.Count, p.Avg, p.P50, p.P99, p.Max)
+			}
+		}
+		benchReport.Rounds = append(benchReport.Rounds, roundReport)
+	}
+
+	if *reportFile != "" {
+		benchReport.Total = recorder.totalReport()
+		if err := writeReport(benchReport, *reportFile); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("write report to", *reportFile)
 	}
 }
@@ -0,0 +1,150 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The phases of the latencies.
+const (
+	// phaseRegionHeartbeat is the time to send a region heartbeat.
+	phaseRegionHeartbeat = "region-heartbeat"
+	// phaseStoreHeartbeat is the time of a store heartbeat request.
+	phaseStoreHeartbeat = "store-heartbeat"
+	// phaseOperator is the time from sending a region heartbeat to receiving
+	// the response with the operator of the region.
+	phaseOperator = "operator"
+)
+
+// PhaseReport is the latencies of a phase in milliseconds.
+type PhaseReport struct {
+	Count int     `json:"count"`
+	Avg   float64 `json:"avg_ms"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// RoundReport is the latencies of the phases in a round.
+type RoundReport struct {
+	Round       int                     `json:"round"`
+	CostSeconds float64                 `json:"cost_seconds"`
+	Regions     int                     `json:"regions"`
+	Phases      map[string]*PhaseReport `json:"phases"`
+}
+
+// BenchReport is the result of the bench.
+type BenchReport struct {
+	Workload    string                  `json:"workload"`
+	Stores      int                     `json:"stores"`
+	Concurrency int                     `json:"concurrency"`
+	Rounds      []*RoundReport          `json:"rounds"`
+	Total       map[string]*PhaseReport `json:"total"`
+}
+
+type latencyRecorder struct {
+	mu    sync.Mutex
+	round map[string][]time.Duration
+	total map[string][]time.Duration
+}
+
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{
+		round: make(map[string][]time.Duration),
+		total: make(map[string][]time.Duration),
+	}
+}
+
+func (l *latencyRecorder) record(phase string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.round[phase] = append(l.round[phase], d)
+	l.total[phase] = append(l.total[phase], d)
+}
+
+// finishRound returns the report of the round and starts a new round.
+func (l *latencyRecorder) finishRound(round, regions int, cost time.Duration) *RoundReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	report := &RoundReport{
+		Round:       round,
+		CostSeconds: cost.Seconds(),
+		Regions:     regions,
+		Phases:      summaryPhases(l.round),
+	}
+	l.round = make(map[string][]time.Duration)
+	return report
+}
+
+func (l *latencyRecorder) totalReport() map[string]*PhaseReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return summaryPhases(l.total)
+}
+
+func summaryPhases(phases map[string][]time.Duration) map[string]*PhaseReport {
+	reports := make(map[string]*PhaseReport, len(phases))
+	for phase, durations := range phases {
+		reports[phase] = summaryLatencies(durations)
+	}
+	return reports
+}
+
+func summaryLatencies(durations []time.Duration) *PhaseReport {
+	if len(durations) == 0 {
+		return &PhaseReport{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	return &PhaseReport{
+		Count: len(sorted),
+		Avg:   toMillisecond(sum / time.Duration(len(sorted))),
+		P50:   toMillisecond(percentile(sorted, 0.5)),
+		P90:   toMillisecond(percentile(sorted, 0.9)),
+		P99:   toMillisecond(percentile(sorted, 0.99)),
+		Max:   toMillisecond(sorted[len(sorted)-1]),
+	}
+}
+
+// percentile returns the percentile of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func toMillisecond(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func writeReport(report *BenchReport, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,202 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+// The workload profiles.
+const (
+	// workloadUniform updates the versions of a ratio of the regions.
+	workloadUniform = "uniform"
+	// workloadHotSpot adds the write flow skewed by the Zipf's law to the
+	// regions, only a few regions of each store are hot.
+	workloadHotSpot = "hot-spot"
+	// workloadSplitStorm splits a ratio of the regions every round.
+	workloadSplitStorm = "split-storm"
+	// workloadLeaderChurn transfers the leaders of a ratio of the regions to
+	// the other peers every round.
+	workloadLeaderChurn = "leader-churn"
+)
+
+const (
+	regionSize = 96 * 1024 * 1024
+	regionKeys = 960000
+)
+
+func isValidWorkload(w string) bool {
+	switch w {
+	case workloadUniform, workloadHotSpot, workloadSplitStorm, workloadLeaderChurn:
+		return true
+	}
+	return false
+}
+
+// idAllocator allocates the ids of the regions and peers created by splits.
+type idAllocator struct {
+	id uint64
+}
+
+func (a *idAllocator) alloc() uint64 {
+	return atomic.AddUint64(&a.id, 1)
+}
+
+// region is a region heartbeated by a store.
+type region struct {
+	meta *metapb.Region
+	// leader is the index of the leader in the peers.
+	leader       int
+	term         uint64
+	bytesWritten uint64
+	keysWritten  uint64
+}
+
+func newRegion(meta *metapb.Region, leaderStoreID uint64) *region {
+	r := &region{meta: meta, term: 1}
+	for i, p := range meta.GetPeers() {
+		if p.GetStoreId() == leaderStoreID {
+			r.leader = i
+			break
+		}
+	}
+	return r
+}
+
+func (r *region) getLeader() *metapb.Peer {
+	return r.meta.GetPeers()[r.leader]
+}
+
+// workload changes the regions of a stream before each round.
+type workload struct {
+	name             string
+	updateRatio      float64
+	hotBytes         uint64
+	hotSkew          float64
+	splitRatio       float64
+	leaderChurnRatio float64
+	ids              *idAllocator
+}
+
+// step changes the regions for the round and returns the regions to
+// heartbeat. The regions are not changed in the first round.
+func (w *workload) step(round int, regions []*region, rnd *rand.Rand) []*region {
+	if round == 0 {
+		if w.name == workloadHotSpot {
+			w.hotSpot(regions)
+		}
+		return regions
+	}
+	switch w.name {
+	case workloadUniform:
+		w.update(regions)
+	case workloadHotSpot:
+		w.update(regions)
+		w.hotSpot(regions)
+	case workloadSplitStorm:
+		return w.split(regions, rnd)
+	case workloadLeaderChurn:
+		w.transferLeaders(regions, rnd)
+	}
+	return regions
+}
+
+func (w *workload) update(regions []*region) {
+	count := int(float64(len(regions)) * w.updateRatio)
+	for _, r := range regions[:count] {
+		r.meta.RegionEpoch.Version++
+	}
+}
+
+// hotSpot sets the write flow of the i-th region to hotBytes/(i+1)^hotSkew.
+func (w *workload) hotSpot(regions []*region) {
+	for i, r := range regions {
+		r.bytesWritten = uint64(float64(w.hotBytes) / math.Pow(float64(i+1), w.hotSkew))
+		r.keysWritten = r.bytesWritten / 1024
+	}
+}
+
+func (w *workload) split(regions []*region, rnd *rand.Rand) []*region {
+	count := int(float64(len(regions)) * w.splitRatio)
+	for _, i := range rnd.Perm(len(regions))[:count] {
+		origin := regions[i]
+		splitKey := splitKey(origin.meta.GetStartKey(), origin.meta.GetEndKey())
+		if splitKey == nil {
+			continue
+		}
+		meta := proto.Clone(origin.meta).(*metapb.Region)
+		meta.Id = w.ids.alloc()
+		for _, p := range meta.GetPeers() {
+			p.Id = w.ids.alloc()
+		}
+		meta.StartKey = splitKey
+		origin.meta.EndKey = splitKey
+		origin.meta.RegionEpoch.Version++
+		meta.RegionEpoch.Version = origin.meta.RegionEpoch.Version
+		regions = append(regions, &region{meta: meta, leader: origin.leader, term: origin.term})
+	}
+	return regions
+}
+
+func (w *workload) transferLeaders(regions []*region, rnd *rand.Rand) {
+	count := int(float64(len(regions)) * w.leaderChurnRatio)
+	for _, i := range rnd.Perm(len(regions))[:count] {
+		r := regions[i]
+		peers := r.meta.GetPeers()
+		for j := 1; j < len(peers); j++ {
+			next := (r.leader + j) % len(peers)
+			if !peers[next].GetIsLearner() {
+				r.leader = next
+				r.term++
+				break
+			}
+		}
+	}
+}
+
+// splitKey returns the middle key of the range [start, end), or nil if the
+// range cannot be split.
+func splitKey(start, end []byte) []byte {
+	// pad the keys to the same length with one more byte, so that there is
+	// always a key between them.
+	l := len(start)
+	if len(end) > l {
+		l = len(end)
+	}
+	l++
+	a := make([]byte, l)
+	copy(a, start)
+	b := make([]byte, l)
+	if len(end) == 0 {
+		for i := range b {
+			b[i] = 0xFF
+		}
+	} else {
+		copy(b, end)
+	}
+	x, y := new(big.Int).SetBytes(a), new(big.Int).SetBytes(b)
+	if x.Cmp(y) >= 0 {
+		return nil
+	}
+	mid := x.Add(x, y).Rsh(x, 1).Bytes()
+	key := make([]byte, l)
+	copy(key[l-len(mid):], mid)
+	return key
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testWorkloadSuite{})
+
+type testWorkloadSuite struct{}
+
+func (s *testWorkloadSuite) TestSplitKey(c *C) {
+	cases := []struct{ start, end string }{
+		{"a", "b"},
+		{"a", "a\x01"},
+		{"", "a"},
+		{"a", ""},
+		{"a\xff", "b"},
+	}
+	for _, t := range cases {
+		key := splitKey([]byte(t.start), []byte(t.end))
+		c.Assert(key, NotNil)
+		c.Assert(bytes.Compare(key, []byte(t.start)), Equals, 1)
+		if t.end != "" {
+			c.Assert(bytes.Compare(key, []byte(t.end)), Equals, -1)
+		}
+	}
+	c.Assert(splitKey([]byte("a"), []byte("a\x00")), IsNil)
+}
+
+func (s *testWorkloadSuite) TestSplitStorm(c *C) {
+	w := &workload{name: workloadSplitStorm, splitRatio: 0.5, ids: &idAllocator{id: 100}}
+	var regions []*region
+	for i, key := range []string{"a", "b"} {
+		regions = append(regions, newRegion(&metapb.Region{
+			Id:          uint64(i + 1),
+			StartKey:    []byte(key),
+			EndKey:      []byte{key[0] + 1},
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+			Peers:       []*metapb.Peer{{Id: 10, StoreId: 1}, {Id: 11, StoreId: 2}},
+		}, 2))
+	}
+	rnd := rand.New(rand.NewSource(1))
+	// not changed in the first round.
+	c.Assert(w.step(0, regions, rnd), HasLen, 2)
+
+	regions = w.step(1, regions, rnd)
+	c.Assert(regions, HasLen, 3)
+	split := regions[2]
+	c.Assert(split.meta.GetId(), Equals, uint64(101))
+	c.Assert(split.meta.GetRegionEpoch().GetVersion(), Equals, uint64(2))
+	c.Assert(split.getLeader().GetStoreId(), Equals, uint64(2))
+	for _, r := range regions[:2] {
+		if r.meta.GetRegionEpoch().GetVersion() == 2 {
+			c.Assert(r.meta.GetEndKey(), DeepEquals, split.meta.GetStartKey())
+		} else {
+			c.Assert(r.meta.GetEndKey(), HasLen, 1)
+		}
+	}
+}
+
+func (s *testWorkloadSuite) TestSummaryLatencies(c *C) {
+	var durations []time.Duration
+	for i := 100; i > 0; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	report := summaryLatencies(durations)
+	c.Assert(report.Count, Equals, 100)
+	c.Assert(report.P50, Equals, float64(50))
+	c.Assert(report.P99, Equals, float64(99))
+	c.Assert(report.Max, Equals, float64(100))
+	c.Assert(report.Avg, Equals, 50.5)
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/tools/pd-backup/pdbackup"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/clientv3"
+)
+
+const (
+	// backupAllocIDStep is added to the allocated ID of the backup if the
+	// alloc-id is not specified, the IDs allocated after the backup must be
+	// less than it.
+	backupAllocIDStep = 100000000
+	// maxTxnOps is less than the default max operations of a etcd txn.
+	maxTxnOps = 64
+)
+
+var errBootstrapped = errors.New("the cluster is already bootstrapped")
+
+// loadBackup loads the backup and checks it with the specified cluster ID
+// and alloc ID, the returned alloc ID is safe to be recovered.
+func loadBackup(filePath string, clusterID, allocID uint64) (*pdbackup.BackupInfo, uint64, error) {
+	backInfo, err := pdbackup.LoadFromFile(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if backInfo.Version < pdbackup.BackupVersion {
+		return nil, 0, errors.Errorf("the backup of version %d does not contain the metadata", backInfo.Version)
+	}
+	if clusterID != 0 && clusterID != backInfo.ClusterID {
+		return nil, 0, errors.Errorf("cluster-id %d does not match the backup %d", clusterID, backInfo.ClusterID)
+	}
+	if allocID == 0 {
+		return backInfo, backInfo.AllocIDMax + backupAllocIDStep, nil
+	}
+	if allocID <= backInfo.AllocIDMax {
+		return nil, 0, errors.Errorf("alloc-id %d is not larger than the allocated ID %d of the backup", allocID, backInfo.AllocIDMax)
+	}
+	return backInfo, allocID, nil
+}
+
+// recoverFromBackup restores the metadata of the backup into etcd. The
+// metadata is written in several transactions and the cluster meta is saved
+// in the last one, so the cluster is not bootstrapped until all metadata is
+// restored. A failed recovery leaves part of the metadata behind, it should
+// be retried with the same backup, which overwrites all of it.
+func recoverFromBackup(client *clientv3.Client, backInfo *pdbackup.BackupInfo, allocID uint64) error {
+	rootPath := path.Join(pdRootPath, strconv.FormatUint(backInfo.ClusterID, 10))
+	clusterRootPath := path.Join(rootPath, pdbackup.ClusterMetaKey)
+	bootstrapCmp := clientv3.Compare(clientv3.CreateRevision(clusterRootPath), "=", 0)
+
+	commit := func(ops []clientv3.Op) error {
+		ctx, cancel := context.WithTimeout(client.Ctx(), requestTimeout)
+		defer cancel()
+		resp, err := client.Txn(ctx).If(bootstrapCmp).Then(ops...).Commit()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if !resp.Succeeded {
+			return errBootstrapped
+		}
+		return nil
+	}
+
+	var (
+		ops         []clientv3.Op
+		clusterMeta []byte
+	)
+	raftBootstrapTimeKey := path.Join(clusterRootPath, "status", "raft_bootstrap_time")
+	hasBootstrapTime := false
+	for _, kv := range backInfo.Metadata {
+		switch kv.Key {
+		case pdbackup.ClusterMetaKey:
+			clusterMeta = kv.Value
+			continue
+		case "alloc_id", "timestamp":
+			continue
+		}
+		key := path.Join(rootPath, kv.Key)
+		if key == raftBootstrapTimeKey {
+			hasBootstrapTime = true
+		}
+		ops = append(ops, clientv3.OpPut(key, string(kv.Value)))
+		if len(ops) >= maxTxnOps {
+			if err := commit(ops); err != nil {
+				return err
+			}
+			ops = ops[:0]
+		}
+	}
+	if clusterMeta == nil {
+		return errors.New("the backup does not contain the cluster meta")
+	}
+
+	// make sure the TSO is not fallback.
+	ts := uint64(time.Now().UnixNano())
+	if backInfo.AllocTimestampMax > ts {
+		ts = backInfo.AllocTimestampMax
+	}
+	ops = append(ops,
+		clientv3.OpPut(pdClusterIDPath, string(typeutil.Uint64ToBytes(backInfo.ClusterID))),
+		clientv3.OpPut(path.Join(rootPath, "alloc_id"), string(typeutil.Uint64ToBytes(allocID))),
+		clientv3.OpPut(path.Join(rootPath, "timestamp"), string(typeutil.Uint64ToBytes(ts))),
+	)
+	if !hasBootstrapTime {
+		nano := time.Now().UnixNano()
+		ops = append(ops, clientv3.OpPut(raftBootstrapTimeKey, string(typeutil.Uint64ToBytes(uint64(nano)))))
+	}
+	// mark the regions restored, the PD leader loads them only once if it
+	// keeps the regions in the local region storage.
+	ops = append(ops,
+		clientv3.OpPut(path.Join(rootPath, core.RecoveredRegionsPath), "1"),
+		clientv3.OpPut(clusterRootPath, string(clusterMeta)),
+	)
+	return commit(ops)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/tools/pd-backup/pdbackup"
 	"go.etcd.io/etcd/clientv3"
 	"go.etcd.io/etcd/pkg/transport"
 )
@@ -25,6 +26,8 @@ var (
 	caPath    string
 	certPath  string
 	keyPath   string
+
+	fromBackup string
 )
 
 const (
@@ -49,6 +52,7 @@ func main() {
 	fs.StringVar(&caPath, "cacert", "", "path of file that contains list of trusted SSL CAs")
 	fs.StringVar(&certPath, "cert", "", "path of file that contains list of trusted SSL CAs")
 	fs.StringVar(&keyPath, "key", "", "path of file that contains X509 key in PEM format")
+	fs.StringVar(&fromBackup, "from-backup", "", "path of the backup file dumped by pd-backup, the metadata in it will be recovered")
 
 	if len(os.Args[1:]) == 0 {
 		fs.Usage()
@@ -61,6 +65,15 @@ func main() {
 		server.PrintPDInfo()
 		return
 	}
+	var backInfo *pdbackup.BackupInfo
+	if fromBackup != "" {
+		var err error
+		backInfo, allocID, err = loadBackup(fromBackup, clusterID, allocID)
+		if err != nil {
+			exitErr(err)
+		}
+		clusterID = backInfo.ClusterID
+	}
 	if clusterID == 0 {
 		fmt.Println("please specify safe cluster-id")
 		return
@@ -95,6 +108,18 @@ func main() {
 	if err != nil {
 		exitErr(err)
 	}
+	if backInfo != nil {
+		if err := recoverFromBackup(client, backInfo, allocID); err != nil {
+			if err == errBootstrapped {
+				fmt.Println("failed to recover: the cluster is already bootstrapped")
+				return
+			}
+			exitErr(err)
+		}
+		fmt.Println("recover success! please restart the PD cluster")
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(client.Ctx(), requestTimeout)
 	defer cancel()
 
@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -43,6 +45,8 @@ var (
 	pdAddr                      = flag.String("pd", "", "pd address")
 	configFile                  = flag.String("config", "conf/simconfig.toml", "config file")
 	caseName                    = flag.String("case", "", "case name")
+	caseFile                    = flag.String("case-file", "", "case file in TOML or JSON")
+	reportFile                  = flag.String("report", "", "the file to write the JSON report of the case")
 	serverLogLevel              = flag.String("serverLog", "fatal", "pd server log level")
 	simLogLevel                 = flag.String("simLog", "fatal", "simulator log level")
 	regionNum                   = flag.Int("regionNum", 0, "regionNum of one store")
@@ -60,6 +64,15 @@ func main() {
 		analysis.GetTransferCounter().Init(simutil.CaseConfigure.StoreNum, simutil.CaseConfigure.RegionNum)
 	}
 
+	if *caseFile != "" {
+		name, err := cases.RegisterCaseFile(*caseFile)
+		if err != nil {
+			simutil.Logger.Fatal("failed to load case file", zap.Error(err))
+		}
+		run(name)
+		return
+	}
+
 	if *caseName == "" {
 		if *pdAddr != "" {
 			simutil.Logger.Fatal("need to specify one config name")
@@ -172,11 +185,16 @@ EXIT:
 	}
 
 	driver.Stop()
+	timeCost := time.Since(start)
+	// the report gets the operators from PD, so write it before PD is closed.
+	if *reportFile != "" {
+		writeReport(driver, simCase, simResult == "OK", timeCost)
+	}
 	if len(clean) != 0 {
 		clean[0]()
 	}
 
-	fmt.Printf("%s [%s] total iteration: %d, time cost: %v\n", simResult, simCase, driver.TickCount(), time.Since(start))
+	fmt.Printf("%s [%s] total iteration: %d, time cost: %v\n", simResult, simCase, driver.TickCount(), timeCost)
 	driver.PrintStatistics()
 	if analysis.GetTransferCounter().IsValid {
 		analysis.GetTransferCounter().PrintResult()
@@ -186,3 +204,19 @@ EXIT:
 		os.Exit(1)
 	}
 }
+
+func writeReport(driver *simulator.Driver, simCase string, checked bool, timeCost time.Duration) {
+	report, err := driver.Report(simCase, checked, timeCost)
+	if err != nil {
+		simutil.Logger.Error("failed to get the operators of the report", zap.Error(err))
+	}
+	path := *reportFile
+	// write a report for each case when running all the cases.
+	if *caseName == "" && *caseFile == "" {
+		ext := filepath.Ext(path)
+		path = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), simCase, ext)
+	}
+	if err := simulator.WriteReport(report, path); err != nil {
+		simutil.Logger.Error("failed to write the report", zap.String("path", path), zap.Error(err))
+	}
+}
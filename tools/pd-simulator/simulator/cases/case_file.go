@@ -0,0 +1,597 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/codec"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/info"
+	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/simutil"
+	"github.com/pingcap/pd/v4/tools/regions-dump/regionsdump"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// The event types supported by the case file.
+const (
+	EventWriteFlow   = "write-flow"
+	EventReadFlow    = "read-flow"
+	EventAddNodes    = "add-nodes"
+	EventDeleteNodes = "delete-nodes"
+	EventStoreDown   = "store-down"
+	// the fault events are active on the stores in the ticks [Start, End).
+	EventStopHeartbeat    = "stop-heartbeat"
+	EventNetworkPartition = "network-partition"
+	EventSlowSnapshot     = "slow-snapshot"
+)
+
+const (
+	defaultFileStoreCapacity  = 1 * TB
+	defaultFileStoreAvailable = 900 * GB
+	defaultFileStoreVersion   = "2.1.0"
+	defaultFileRegionReplicas = 3
+	defaultFileRegionSize     = 96 * MB
+	defaultFileRegionKeys     = 960000
+	defaultFileEventInterval  = 100
+)
+
+// CaseFile is a case described by a TOML or JSON file.
+type CaseFile struct {
+	// Stores are the initial stores. The stores are numbered from 1 in order.
+	Stores []StoreConfig `toml:"stores" json:"stores"`
+	// Regions are the initial regions, they are placed on the stores in a
+	// round-robin way.
+	Regions []RegionConfig `toml:"regions" json:"regions"`
+	// RegionsFile is the JSON file dumped by regions-dump, the initial stores
+	// and regions are replayed from it instead of Stores and Regions. The
+	// relative path is relative to the case file.
+	RegionsFile     string            `toml:"regions-file" json:"regions-file"`
+	RegionSplitSize typeutil.ByteSize `toml:"region-split-size" json:"region-split-size"`
+	RegionSplitKeys int64             `toml:"region-split-keys" json:"region-split-keys"`
+	// TableNumber is the number of tables the initial regions are split by,
+	// 0 means to split the regions by raw keys.
+	TableNumber int           `toml:"table-number" json:"table-number"`
+	Events      []EventConfig `toml:"events" json:"events"`
+	Checker     CheckerConfig `toml:"checker" json:"checker"`
+
+	regions *regionsdump.File
+}
+
+// StoreConfig describes a group of the initial stores.
+type StoreConfig struct {
+	// Count is the number of the stores in the group, default: 1.
+	Count     int               `toml:"count" json:"count"`
+	Labels    map[string]string `toml:"labels" json:"labels"`
+	Capacity  typeutil.ByteSize `toml:"capacity" json:"capacity"`
+	Available typeutil.ByteSize `toml:"available" json:"available"`
+	Version   string            `toml:"version" json:"version"`
+}
+
+// RegionConfig describes a group of the initial regions.
+type RegionConfig struct {
+	Count    int               `toml:"count" json:"count"`
+	Replicas int               `toml:"replicas" json:"replicas"`
+	Size     typeutil.ByteSize `toml:"size" json:"size"`
+	Keys     int64             `toml:"keys" json:"keys"`
+	// Stores are the stores to place the regions, empty means all the
+	// initial stores.
+	Stores []uint64 `toml:"stores" json:"stores"`
+}
+
+// EventConfig describes an event which is active in the ticks [Start, End).
+type EventConfig struct {
+	Type  string `toml:"type" json:"type"`
+	Start int64  `toml:"start" json:"start"`
+	// End is the tick the event stops, 0 means the event never stops.
+	End int64 `toml:"end" json:"end"`
+	// Interval is the ticks between two nodes are added or deleted.
+	Interval int64 `toml:"interval" json:"interval"`
+	// Count is the number of the nodes to add. The new nodes are numbered
+	// after the initial stores in the order of the events.
+	Count int `toml:"count" json:"count"`
+	// Stores are the stores to delete or to inject the fault into.
+	Stores []uint64 `toml:"stores" json:"stores"`
+	// Slowdown is the times the snapshots on the stores are slowed down by.
+	Slowdown int64 `toml:"slowdown" json:"slowdown"`
+	// Bytes is the flow of every tick on each key, table or region.
+	Bytes  typeutil.ByteSize `toml:"bytes" json:"bytes"`
+	Keys   []string          `toml:"keys" json:"keys"`
+	Tables []int64           `toml:"tables" json:"tables"`
+	// Regions are the ids of the regions with the flow.
+	Regions []uint64 `toml:"regions" json:"regions"`
+	// LeaderStore and RegionCount select the regions with the flow by the
+	// initial leaders.
+	LeaderStore uint64 `toml:"leader-store" json:"leader-store"`
+	RegionCount int    `toml:"region-count" json:"region-count"`
+}
+
+// CheckerConfig describes when the case is finished.
+type CheckerConfig struct {
+	// StoreCount is the number of the stores alive when the case is
+	// finished, 0 means not to check it.
+	StoreCount int `toml:"store-count" json:"store-count"`
+	// LeaderThreshold and RegionThreshold are the ratios the leader and
+	// region counts of a store can differ from the mean, 0 means not to
+	// check them.
+	LeaderThreshold float64 `toml:"leader-threshold" json:"leader-threshold"`
+	RegionThreshold float64 `toml:"region-threshold" json:"region-threshold"`
+	// MinRegionCount is the minimum region count of each store.
+	MinRegionCount int `toml:"min-region-count" json:"min-region-count"`
+}
+
+// LoadCaseFile loads a case file, the file is decoded as JSON if it has the
+// ".json" extension, otherwise as TOML.
+func LoadCaseFile(path string) (*CaseFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &CaseFile{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, f)
+	} else {
+		_, err = toml.Decode(string(data), f)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode case file %s", path)
+	}
+	if f.RegionsFile != "" {
+		regionsFile := f.RegionsFile
+		if !filepath.IsAbs(regionsFile) {
+			regionsFile = filepath.Join(filepath.Dir(path), regionsFile)
+		}
+		if f.regions, err = regionsdump.LoadFile(regionsFile); err != nil {
+			return nil, err
+		}
+	}
+	f.adjust()
+	if err := f.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid case file %s", path)
+	}
+	return f, nil
+}
+
+// RegisterCaseFile loads a case file and adds it into CaseMap, it returns
+// the name of the case.
+func RegisterCaseFile(path string) (string, error) {
+	f, err := LoadCaseFile(path)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	CaseMap[name] = f.NewCase
+	return name, nil
+}
+
+func (f *CaseFile) adjust() {
+	for i := range f.Stores {
+		s := &f.Stores[i]
+		if s.Count == 0 {
+			s.Count = 1
+		}
+		if s.Capacity == 0 {
+			s.Capacity = defaultFileStoreCapacity
+		}
+		if s.Available == 0 {
+			s.Available = defaultFileStoreAvailable
+		}
+		if s.Version == "" {
+			s.Version = defaultFileStoreVersion
+		}
+	}
+	for i := range f.Regions {
+		r := &f.Regions[i]
+		if r.Replicas == 0 {
+			r.Replicas = defaultFileRegionReplicas
+		}
+		if r.Size == 0 {
+			r.Size = defaultFileRegionSize
+		}
+		if r.Keys == 0 {
+			r.Keys = defaultFileRegionKeys
+		}
+	}
+	for i := range f.Events {
+		e := &f.Events[i]
+		if e.Interval == 0 {
+			e.Interval = defaultFileEventInterval
+		}
+	}
+}
+
+func (f *CaseFile) storeCount() int {
+	if f.regions != nil {
+		return len(f.regions.Stores)
+	}
+	var count int
+	for _, s := range f.Stores {
+		count += s.Count
+	}
+	return count
+}
+
+func (f *CaseFile) validate() error {
+	if f.regions != nil {
+		if len(f.Stores) > 0 || len(f.Regions) > 0 {
+			return errors.New("stores and regions cannot be specified with regions-file")
+		}
+		if len(f.regions.Stores) == 0 || len(f.regions.Regions) == 0 {
+			return errors.New("no store or region in regions-file")
+		}
+		return f.validateEvents()
+	}
+	storeCount := f.storeCount()
+	if storeCount == 0 {
+		return errors.New("no store is specified")
+	}
+	var regionCount int
+	for _, r := range f.Regions {
+		for _, id := range r.Stores {
+			if id == 0 || id > uint64(storeCount) {
+				return errors.Errorf("region store %d is not an initial store", id)
+			}
+		}
+		placeCount := len(r.Stores)
+		if placeCount == 0 {
+			placeCount = storeCount
+		}
+		if r.Replicas > placeCount {
+			return errors.Errorf("%d replicas cannot be placed on %d stores", r.Replicas, placeCount)
+		}
+		regionCount += r.Count
+	}
+	if regionCount == 0 {
+		return errors.New("no region is specified")
+	}
+	return f.validateEvents()
+}
+
+func (f *CaseFile) validateEvents() error {
+	for _, e := range f.Events {
+		if e.End != 0 && e.End <= e.Start {
+			return errors.Errorf("event %s ends at %d before it starts at %d", e.Type, e.End, e.Start)
+		}
+		switch e.Type {
+		case EventWriteFlow, EventReadFlow:
+			if e.Bytes == 0 {
+				return errors.Errorf("event %s has no flow", e.Type)
+			}
+			if len(e.Keys) == 0 && len(e.Tables) == 0 && len(e.Regions) == 0 && (e.LeaderStore == 0 || e.RegionCount == 0) {
+				return errors.Errorf("event %s has no key, table or region", e.Type)
+			}
+		case EventAddNodes:
+			if e.Count == 0 {
+				return errors.Errorf("event %s has no node to add", e.Type)
+			}
+		case EventDeleteNodes, EventStoreDown, EventStopHeartbeat, EventNetworkPartition:
+			if len(e.Stores) == 0 {
+				return errors.Errorf("event %s has no store", e.Type)
+			}
+		case EventSlowSnapshot:
+			if len(e.Stores) == 0 {
+				return errors.Errorf("event %s has no store", e.Type)
+			}
+			if e.Slowdown <= 1 {
+				return errors.Errorf("event %s should slow down the snapshots by more than 1 times", e.Type)
+			}
+		default:
+			return errors.Errorf("unknown event type %s", e.Type)
+		}
+	}
+	return nil
+}
+
+// replayRegions adds the stores and regions of the regions file into the case.
+func (f *CaseFile) replayRegions(simCase *Case) {
+	maxID := IDAllocator.GetID()
+	updateMaxID := func(id uint64) {
+		if id > maxID {
+			maxID = id
+		}
+	}
+	for _, id := range f.regions.Stores {
+		simCase.Stores = append(simCase.Stores, &Store{
+			ID:        id,
+			Status:    metapb.StoreState_Up,
+			Capacity:  defaultFileStoreCapacity,
+			Available: defaultFileStoreAvailable,
+			Version:   defaultFileStoreVersion,
+		})
+		updateMaxID(id)
+	}
+	metas, err := f.regions.Metas()
+	if err != nil {
+		simutil.Logger.Fatal("invalid regions file", zap.Error(err))
+	}
+	for _, meta := range metas {
+		// the leader is not dumped, use the first voter.
+		var leader *metapb.Peer
+		for _, p := range meta.GetPeers() {
+			if leader == nil && !p.GetIsLearner() {
+				leader = p
+			}
+			updateMaxID(p.GetId())
+		}
+		updateMaxID(meta.GetId())
+		if leader == nil {
+			continue
+		}
+		simCase.Regions = append(simCase.Regions, Region{
+			ID:       meta.GetId(),
+			Peers:    meta.GetPeers(),
+			Leader:   leader,
+			Size:     defaultFileRegionSize,
+			Keys:     defaultFileRegionKeys,
+			StartKey: meta.GetStartKey(),
+			EndKey:   meta.GetEndKey(),
+		})
+	}
+	// the ids allocated later follow the ids in the file.
+	IDAllocator.id = maxID
+}
+
+// NewCase creates the case described by the file.
+func (f *CaseFile) NewCase() *Case {
+	var simCase Case
+
+	if f.regions != nil {
+		f.replayRegions(&simCase)
+	}
+	for _, s := range f.Stores {
+		labels := make([]*metapb.StoreLabel, 0, len(s.Labels))
+		for k, v := range s.Labels {
+			labels = append(labels, &metapb.StoreLabel{Key: k, Value: v})
+		}
+		sort.Slice(labels, func(i, j int) bool { return labels[i].Key < labels[j].Key })
+		for i := 0; i < s.Count; i++ {
+			simCase.Stores = append(simCase.Stores, &Store{
+				ID:        IDAllocator.nextID(),
+				Status:    metapb.StoreState_Up,
+				Labels:    labels,
+				Capacity:  uint64(s.Capacity),
+				Available: uint64(s.Available),
+				Version:   s.Version,
+			})
+		}
+	}
+
+	// alloc the ids of the nodes to add before the regions, so that they
+	// follow the initial stores.
+	addNodeIDs := make([][]uint64, len(f.Events))
+	for i, e := range f.Events {
+		if e.Type == EventAddNodes {
+			for j := 0; j < e.Count; j++ {
+				addNodeIDs[i] = append(addNodeIDs[i], IDAllocator.nextID())
+			}
+		}
+	}
+
+	var allStores []uint64
+	for _, s := range simCase.Stores {
+		allStores = append(allStores, s.ID)
+	}
+	for _, r := range f.Regions {
+		stores := r.Stores
+		if len(stores) == 0 {
+			stores = allStores
+		}
+		for i := 0; i < r.Count; i++ {
+			peers := make([]*metapb.Peer, 0, r.Replicas)
+			for j := 0; j < r.Replicas; j++ {
+				peers = append(peers, &metapb.Peer{Id: IDAllocator.nextID(), StoreId: stores[(i+j)%len(stores)]})
+			}
+			simCase.Regions = append(simCase.Regions, Region{
+				ID:     IDAllocator.nextID(),
+				Peers:  peers,
+				Leader: peers[0],
+				Size:   int64(r.Size),
+				Keys:   r.Keys,
+			})
+		}
+	}
+	simCase.RegionSplitSize = int64(f.RegionSplitSize)
+	simCase.RegionSplitKeys = f.RegionSplitKeys
+	simCase.TableNumber = f.TableNumber
+
+	// the stores alive at the end of the case.
+	aliveStores := make(map[uint64]struct{})
+	for _, id := range allStores {
+		aliveStores[id] = struct{}{}
+	}
+	for i, e := range f.Events {
+		switch e.Type {
+		case EventWriteFlow, EventReadFlow:
+			simCase.Events = append(simCase.Events, f.newFlowEvent(e, simCase.Regions)...)
+		case EventAddNodes:
+			for _, id := range addNodeIDs[i] {
+				aliveStores[id] = struct{}{}
+			}
+			ids := addNodeIDs[i]
+			simCase.Events = append(simCase.Events, &AddNodesDescriptor{Step: nodeStep(e, ids)})
+		case EventDeleteNodes:
+			for _, id := range e.Stores {
+				delete(aliveStores, id)
+			}
+			simCase.Events = append(simCase.Events, &DeleteNodesDescriptor{Step: nodeStep(e, e.Stores)})
+		case EventStoreDown, EventNetworkPartition:
+			// the stores never recover are not alive at the end.
+			if e.End == 0 {
+				for _, id := range e.Stores {
+					delete(aliveStores, id)
+				}
+			}
+			if e.Type == EventStoreDown {
+				simCase.Events = append(simCase.Events, &DownNodesDescriptor{Step: faultStep(e)})
+			} else {
+				simCase.Events = append(simCase.Events, &PartitionNodesDescriptor{Step: faultStep(e)})
+			}
+		case EventStopHeartbeat:
+			simCase.Events = append(simCase.Events, &StopHeartbeatDescriptor{Step: faultStep(e)})
+		case EventSlowSnapshot:
+			slowdown := make(map[uint64]int64, len(e.Stores))
+			for _, id := range e.Stores {
+				slowdown[id] = e.Slowdown
+			}
+			event := e
+			step := func(tick int64) map[uint64]int64 {
+				if !isEventActive(event, tick) {
+					return nil
+				}
+				return slowdown
+			}
+			simCase.Events = append(simCase.Events, &SlowSnapshotDescriptor{Step: step})
+		}
+	}
+
+	simCase.Checker = f.newChecker(aliveStores)
+	return &simCase
+}
+
+func isEventActive(e EventConfig, tick int64) bool {
+	return tick >= e.Start && (e.End == 0 || tick < e.End)
+}
+
+// nodeStep returns the next node of ids every interval ticks.
+func nodeStep(e EventConfig, ids []uint64) func(tick int64) uint64 {
+	ids = append([]uint64(nil), ids...)
+	return func(tick int64) uint64 {
+		if len(ids) == 0 || !isEventActive(e, tick) || (tick-e.Start)%e.Interval != 0 {
+			return 0
+		}
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+}
+
+// faultStep returns the stores of the event when it is active.
+func faultStep(e EventConfig) func(tick int64) []uint64 {
+	return func(tick int64) []uint64 {
+		if !isEventActive(e, tick) {
+			return nil
+		}
+		return e.Stores
+	}
+}
+
+func (f *CaseFile) newFlowEvent(e EventConfig, regions []Region) []EventDescriptor {
+	var events []EventDescriptor
+	bytes := int64(e.Bytes)
+
+	spotFlow := make(map[string]int64)
+	for _, key := range e.Keys {
+		spotFlow[key] = bytes
+	}
+	for _, table := range e.Tables {
+		spotFlow[string(codec.EncodeBytes(codec.GenerateTableKey(table)))] = bytes
+	}
+	if len(spotFlow) > 0 {
+		step := func(tick int64) map[string]int64 {
+			if !isEventActive(e, tick) {
+				return nil
+			}
+			return spotFlow
+		}
+		if e.Type == EventWriteFlow {
+			events = append(events, &WriteFlowOnSpotDescriptor{Step: step})
+		} else {
+			events = append(events, &ReadFlowOnSpotDescriptor{Step: step})
+		}
+	}
+
+	regionFlow := make(map[uint64]int64)
+	for _, id := range e.Regions {
+		regionFlow[id] = bytes
+	}
+	if e.LeaderStore != 0 {
+		var count int
+		for _, r := range regions {
+			if count >= e.RegionCount {
+				break
+			}
+			if r.Leader.GetStoreId() == e.LeaderStore {
+				regionFlow[r.ID] = bytes
+				count++
+			}
+		}
+	}
+	if len(regionFlow) > 0 {
+		step := func(tick int64) map[uint64]int64 {
+			if !isEventActive(e, tick) {
+				return nil
+			}
+			return regionFlow
+		}
+		if e.Type == EventWriteFlow {
+			events = append(events, &WriteFlowOnRegionDescriptor{Step: step})
+		} else {
+			events = append(events, &ReadFlowOnRegionDescriptor{Step: step})
+		}
+	}
+	return events
+}
+
+func (f *CaseFile) newChecker(aliveStores map[uint64]struct{}) CheckerFunc {
+	c := f.Checker
+	return func(regions *core.RegionsInfo, stats []info.StoreStats) bool {
+		var stores []uint64
+		for _, stat := range stats {
+			if _, ok := aliveStores[stat.GetStoreId()]; ok {
+				stores = append(stores, stat.GetStoreId())
+			}
+		}
+		if len(stores) == 0 || (c.StoreCount > 0 && len(stores) != c.StoreCount) {
+			return false
+		}
+
+		var peerCount int
+		for _, region := range regions.GetRegions() {
+			peerCount += len(region.GetPeers())
+		}
+		meanLeaderCount := float64(regions.GetRegionCount()) / float64(len(stores))
+		meanRegionCount := float64(peerCount) / float64(len(stores))
+
+		res := true
+		leaderCounts := make([]int, 0, len(stores))
+		regionCounts := make([]int, 0, len(stores))
+		for _, id := range stores {
+			leaderCount := regions.GetStoreLeaderCount(id)
+			regionCount := regions.GetStoreRegionCount(id)
+			leaderCounts = append(leaderCounts, leaderCount)
+			regionCounts = append(regionCounts, regionCount)
+			if c.LeaderThreshold > 0 {
+				res = res && isFloatUniform(leaderCount, meanLeaderCount, c.LeaderThreshold)
+			}
+			if c.RegionThreshold > 0 {
+				res = res && isFloatUniform(regionCount, meanRegionCount, c.RegionThreshold)
+			}
+			res = res && regionCount >= c.MinRegionCount
+		}
+		simutil.Logger.Info("current counts", zap.Ints("leader", leaderCounts), zap.Ints("region", regionCounts))
+		return res
+	}
+}
+
+func isFloatUniform(count int, meanCount float64, threshold float64) bool {
+	return (1.0-threshold)*meanCount <= float64(count) && float64(count) <= (1.0+threshold)*meanCount
+}
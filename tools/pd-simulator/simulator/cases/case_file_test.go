@@ -0,0 +1,256 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/tools/regions-dump/regionsdump"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testCaseFileSuite{})
+
+type testCaseFileSuite struct {
+	dir string
+}
+
+func (s *testCaseFileSuite) SetUpSuite(c *C) {
+	var err error
+	s.dir, err = ioutil.TempDir("", "sim-case")
+	c.Assert(err, IsNil)
+}
+
+func (s *testCaseFileSuite) TearDownSuite(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *testCaseFileSuite) writeFile(c *C, name, content string) string {
+	path := filepath.Join(s.dir, name)
+	c.Assert(ioutil.WriteFile(path, []byte(content), 0644), IsNil)
+	return path
+}
+
+func (s *testCaseFileSuite) TestLoadCaseFile(c *C) {
+	path := s.writeFile(c, "add-nodes.toml", `
+[[stores]]
+count = 3
+[stores.labels]
+zone = "z1"
+
+[[regions]]
+count = 30
+size = "1MiB"
+
+[[events]]
+type = "add-nodes"
+start = 10
+interval = 5
+count = 2
+
+[[events]]
+type = "write-flow"
+bytes = "1MiB"
+leader-store = 1
+region-count = 4
+
+[checker]
+store-count = 5
+region-threshold = 0.1
+`)
+	name, err := RegisterCaseFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "add-nodes")
+	defer delete(CaseMap, name)
+
+	IDAllocator.ResetID()
+	defer IDAllocator.ResetID()
+	simCase := NewCase(name)
+	c.Assert(simCase, NotNil)
+	c.Assert(simCase.Stores, HasLen, 3)
+	for i, store := range simCase.Stores {
+		c.Assert(store.ID, Equals, uint64(i+1))
+		c.Assert(store.Labels, HasLen, 1)
+		c.Assert(store.Capacity, Equals, uint64(defaultFileStoreCapacity))
+	}
+	c.Assert(simCase.Regions, HasLen, 30)
+	for i, region := range simCase.Regions {
+		c.Assert(region.Peers, HasLen, defaultFileRegionReplicas)
+		c.Assert(region.Leader.GetStoreId(), Equals, uint64(i%3+1))
+		c.Assert(region.Size, Equals, int64(MB))
+	}
+	c.Assert(simCase.Events, HasLen, 2)
+
+	// the new nodes are numbered after the initial stores.
+	addNodes := simCase.Events[0].(*AddNodesDescriptor)
+	c.Assert(addNodes.Step(5), Equals, uint64(0))
+	c.Assert(addNodes.Step(10), Equals, uint64(4))
+	c.Assert(addNodes.Step(12), Equals, uint64(0))
+	c.Assert(addNodes.Step(15), Equals, uint64(5))
+	c.Assert(addNodes.Step(20), Equals, uint64(0))
+
+	writeFlow := simCase.Events[1].(*WriteFlowOnRegionDescriptor)
+	c.Assert(writeFlow.Step(0), HasLen, 4)
+}
+
+func (s *testCaseFileSuite) TestLoadJSONCaseFile(c *C) {
+	path := s.writeFile(c, "hot-read.json", `{
+	"stores": [{"count": 3}],
+	"regions": [{"count": 3, "replicas": 3}],
+	"events": [{"type": "read-flow", "start": 1, "end": 3, "keys": ["a"], "bytes": "1KiB"}]
+}`)
+	f, err := LoadCaseFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(f.Events[0].Bytes, Equals, typeutil.ByteSize(KB))
+
+	IDAllocator.ResetID()
+	defer IDAllocator.ResetID()
+	simCase := f.NewCase()
+	readFlow := simCase.Events[0].(*ReadFlowOnSpotDescriptor)
+	c.Assert(readFlow.Step(0), HasLen, 0)
+	c.Assert(readFlow.Step(1), DeepEquals, map[string]int64{"a": KB})
+	c.Assert(readFlow.Step(3), HasLen, 0)
+}
+
+func (s *testCaseFileSuite) TestFaultEvents(c *C) {
+	path := s.writeFile(c, "faults.toml", `
+[[stores]]
+count = 4
+
+[[regions]]
+count = 8
+
+[[events]]
+type = "store-down"
+start = 10
+end = 20
+stores = [1]
+
+[[events]]
+type = "network-partition"
+start = 10
+stores = [2]
+
+[[events]]
+type = "slow-snapshot"
+end = 5
+stores = [3, 4]
+slowdown = 2
+`)
+	f, err := LoadCaseFile(path)
+	c.Assert(err, IsNil)
+	IDAllocator.ResetID()
+	defer IDAllocator.ResetID()
+	simCase := f.NewCase()
+	c.Assert(simCase.Events, HasLen, 3)
+
+	storeDown := simCase.Events[0].(*DownNodesDescriptor)
+	c.Assert(storeDown.Step(9), HasLen, 0)
+	c.Assert(storeDown.Step(10), DeepEquals, []uint64{1})
+	c.Assert(storeDown.Step(20), HasLen, 0)
+	partition := simCase.Events[1].(*PartitionNodesDescriptor)
+	c.Assert(partition.Step(100), DeepEquals, []uint64{2})
+	slowSnapshot := simCase.Events[2].(*SlowSnapshotDescriptor)
+	c.Assert(slowSnapshot.Step(0), DeepEquals, map[uint64]int64{3: 2, 4: 2})
+	c.Assert(slowSnapshot.Step(5), HasLen, 0)
+}
+
+func (s *testCaseFileSuite) TestRegionsFile(c *C) {
+	regions := []*metapb.Region{
+		{Id: 10, EndKey: []byte("b"), Peers: []*metapb.Peer{{Id: 11, StoreId: 4, IsLearner: true}, {Id: 12, StoreId: 5}}},
+		{Id: 20, StartKey: []byte("b"), Peers: []*metapb.Peer{{Id: 21, StoreId: 5}, {Id: 22, StoreId: 7}}},
+	}
+	var buf bytes.Buffer
+	c.Assert(regionsdump.NewFile(regions).WriteJSON(&buf), IsNil)
+	s.writeFile(c, "regions.json", buf.String())
+	path := s.writeFile(c, "replay.toml", `
+regions-file = "regions.json"
+
+[[events]]
+type = "add-nodes"
+count = 1
+`)
+	f, err := LoadCaseFile(path)
+	c.Assert(err, IsNil)
+	IDAllocator.ResetID()
+	defer IDAllocator.ResetID()
+	simCase := f.NewCase()
+	c.Assert(simCase.Stores, HasLen, 3)
+	c.Assert(simCase.Stores[2].ID, Equals, uint64(7))
+	c.Assert(simCase.Regions, HasLen, 2)
+	c.Assert(simCase.Regions[0].Leader.GetId(), Equals, uint64(12))
+	c.Assert(simCase.Regions[1].StartKey, DeepEquals, []byte("b"))
+	// the new node follows the ids in the file.
+	addNodes := simCase.Events[0].(*AddNodesDescriptor)
+	c.Assert(addNodes.Step(0), Equals, uint64(23))
+
+	// the stores and regions cannot be specified with the regions file.
+	path = s.writeFile(c, "invalid-replay.toml", `
+regions-file = "regions.json"
+[[stores]]
+count = 3
+`)
+	_, err = LoadCaseFile(path)
+	c.Assert(err, NotNil)
+}
+
+func (s *testCaseFileSuite) TestInvalidCaseFile(c *C) {
+	invalids := []string{
+		// no store
+		`[[regions]]
+count = 1`,
+		// too many replicas
+		`[[stores]]
+count = 2
+[[regions]]
+count = 1`,
+		// unknown event
+		`[[stores]]
+count = 3
+[[regions]]
+count = 1
+[[events]]
+type = "unknown"`,
+		// slow snapshot without slowdown
+		`[[stores]]
+count = 3
+[[regions]]
+count = 1
+[[events]]
+type = "slow-snapshot"
+stores = [1]`,
+		// flow without target
+		`[[stores]]
+count = 3
+[[regions]]
+count = 1
+[[events]]
+type = "write-flow"
+bytes = "1MiB"`,
+	}
+	for _, content := range invalids {
+		_, err := LoadCaseFile(s.writeFile(c, "invalid.toml", content))
+		c.Assert(err, NotNil)
+	}
+}
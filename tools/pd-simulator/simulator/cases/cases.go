@@ -39,6 +39,10 @@ type Region struct {
 	Leader *metapb.Peer
 	Size   int64
 	Keys   int64
+	// StartKey and EndKey are the key range of the region, the key ranges
+	// are generated if they are not specified by any region.
+	StartKey []byte
+	EndKey   []byte
 }
 
 // CheckerFunc checks if the scheduler is finished.
@@ -48,6 +48,16 @@ func (w *ReadFlowOnRegionDescriptor) Type() string {
 	return "read-flow-on-region"
 }
 
+// ReadFlowOnSpotDescriptor reads bytes in some range.
+type ReadFlowOnSpotDescriptor struct {
+	Step func(tick int64) map[string]int64
+}
+
+// Type implements the EventDescriptor interface.
+func (w *ReadFlowOnSpotDescriptor) Type() string {
+	return "read-flow-on-spot"
+}
+
 // AddNodesDescriptor adds nodes.
 type AddNodesDescriptor struct {
 	Step func(tick int64) uint64
@@ -67,3 +77,47 @@ type DeleteNodesDescriptor struct {
 func (w *DeleteNodesDescriptor) Type() string {
 	return "delete-nodes"
 }
+
+// StopHeartbeatDescriptor stops the heartbeats of the nodes returned by Step,
+// the nodes not returned any more send heartbeats again.
+type StopHeartbeatDescriptor struct {
+	Step func(tick int64) []uint64
+}
+
+// Type implements the EventDescriptor interface.
+func (w *StopHeartbeatDescriptor) Type() string {
+	return "stop-heartbeat"
+}
+
+// DownNodesDescriptor makes the nodes returned by Step down, the nodes not
+// returned any more recover.
+type DownNodesDescriptor struct {
+	Step func(tick int64) []uint64
+}
+
+// Type implements the EventDescriptor interface.
+func (w *DownNodesDescriptor) Type() string {
+	return "down-nodes"
+}
+
+// PartitionNodesDescriptor partitions the nodes returned by Step from the
+// other nodes, the nodes not returned any more recover.
+type PartitionNodesDescriptor struct {
+	Step func(tick int64) []uint64
+}
+
+// Type implements the EventDescriptor interface.
+func (w *PartitionNodesDescriptor) Type() string {
+	return "partition-nodes"
+}
+
+// SlowSnapshotDescriptor slows down the snapshots on the nodes returned by
+// Step by the times, the nodes not returned any more recover.
+type SlowSnapshotDescriptor struct {
+	Step func(tick int64) map[uint64]int64
+}
+
+// Type implements the EventDescriptor interface.
+func (w *SlowSnapshotDescriptor) Type() string {
+	return "slow-snapshot"
+}
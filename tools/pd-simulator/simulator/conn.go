@@ -48,5 +48,11 @@ func (c *Connection) nodeHealth(storeID uint64) bool {
 		return false
 	}
 
-	return n.GetState() == metapb.StoreState_Up
+	return n.GetState() == metapb.StoreState_Up && !n.isDown()
+}
+
+// nodeReachable checks if the node is healthy and can be reached by the
+// other nodes.
+func (c *Connection) nodeReachable(storeID uint64) bool {
+	return c.nodeHealth(storeID) && !c.Nodes[storeID].isPartitioned()
 }
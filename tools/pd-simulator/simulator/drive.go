@@ -16,6 +16,7 @@ package simulator
 import (
 	"context"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -38,6 +39,10 @@ type Driver struct {
 	raftEngine  *RaftEngine
 	conn        *Connection
 	simConfig   *SimConfig
+	startTime   time.Time
+	// balanceSamples are the variances of the leader and region counts
+	// sampled during the case.
+	balanceSamples []*BalanceSample
 }
 
 // NewDriver returns a driver.
@@ -50,6 +55,7 @@ func NewDriver(pdAddr string, caseName string, simConfig *SimConfig) (*Driver, e
 		pdAddr:    pdAddr,
 		simCase:   simCase,
 		simConfig: simConfig,
+		startTime: time.Now(),
 	}, nil
 }
 
@@ -113,6 +119,9 @@ func (d *Driver) Tick() {
 		go n.Tick(&d.wg)
 	}
 	d.wg.Wait()
+	if d.tickCount%balanceSampleTicks == 0 {
+		d.sampleBalance()
+	}
 }
 
 // Check checks if the simulation is completed.
@@ -53,10 +53,20 @@ func parserEvent(e cases.EventDescriptor) Event {
 		return &WriteFlowOnRegion{descriptor: t}
 	case *cases.ReadFlowOnRegionDescriptor:
 		return &ReadFlowOnRegion{descriptor: t}
+	case *cases.ReadFlowOnSpotDescriptor:
+		return &ReadFlowOnSpot{descriptor: t}
 	case *cases.AddNodesDescriptor:
 		return &AddNodes{descriptor: t}
 	case *cases.DeleteNodesDescriptor:
 		return &DeleteNodes{descriptor: t}
+	case *cases.StopHeartbeatDescriptor:
+		return &StopHeartbeat{descriptor: t}
+	case *cases.DownNodesDescriptor:
+		return &DownNodes{descriptor: t}
+	case *cases.PartitionNodesDescriptor:
+		return &PartitionNodes{descriptor: t}
+	case *cases.SlowSnapshotDescriptor:
+		return &SlowSnapshot{descriptor: t}
 	}
 	return nil
 }
@@ -125,6 +135,27 @@ func (e *ReadFlowOnRegion) Run(raft *RaftEngine, tickCount int64) bool {
 	return false
 }
 
+// ReadFlowOnSpot reads bytes in some range.
+type ReadFlowOnSpot struct {
+	descriptor *cases.ReadFlowOnSpotDescriptor
+}
+
+// Run implements the event interface.
+func (e *ReadFlowOnSpot) Run(raft *RaftEngine, tickCount int64) bool {
+	res := e.descriptor.Step(tickCount)
+	readBytes := make(map[uint64]int64, len(res))
+	for key, size := range res {
+		region := raft.SearchRegion([]byte(key))
+		if region == nil {
+			simutil.Logger.Error("region not found for key", zap.String("key", key))
+			continue
+		}
+		readBytes[region.GetID()] += size
+	}
+	raft.updateRegionReadBytes(readBytes)
+	return false
+}
+
 // AddNodes adds nodes.
 type AddNodes struct {
 	descriptor *cases.AddNodesDescriptor
@@ -198,3 +229,97 @@ func (e *DeleteNodes) Run(raft *RaftEngine, tickCount int64) bool {
 	}
 	return false
 }
+
+// updateFaultNodes injects a fault into the nodes which are newly in ids by
+// calling set with true, and recovers the nodes which are not in ids any more
+// by calling set with false. It returns the current faulty nodes.
+func updateFaultNodes(raft *RaftEngine, faultNodes map[uint64]struct{}, ids []uint64, set func(n *Node, fault bool)) map[uint64]struct{} {
+	current := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		current[id] = struct{}{}
+	}
+	for id := range faultNodes {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		if node := raft.conn.Nodes[id]; node != nil {
+			set(node, false)
+		}
+	}
+	for id := range current {
+		if _, ok := faultNodes[id]; ok {
+			continue
+		}
+		node := raft.conn.Nodes[id]
+		if node == nil {
+			simutil.Logger.Error("node is not existed", zap.Uint64("node-id", id))
+			delete(current, id)
+			continue
+		}
+		set(node, true)
+	}
+	return current
+}
+
+// StopHeartbeat stops the heartbeats of nodes.
+type StopHeartbeat struct {
+	descriptor *cases.StopHeartbeatDescriptor
+	faultNodes map[uint64]struct{}
+}
+
+// Run implements the event interface.
+func (e *StopHeartbeat) Run(raft *RaftEngine, tickCount int64) bool {
+	e.faultNodes = updateFaultNodes(raft, e.faultNodes, e.descriptor.Step(tickCount), (*Node).setHeartbeatStopped)
+	return false
+}
+
+// DownNodes makes nodes down.
+type DownNodes struct {
+	descriptor *cases.DownNodesDescriptor
+	faultNodes map[uint64]struct{}
+}
+
+// Run implements the event interface.
+func (e *DownNodes) Run(raft *RaftEngine, tickCount int64) bool {
+	e.faultNodes = updateFaultNodes(raft, e.faultNodes, e.descriptor.Step(tickCount), (*Node).setDown)
+	return false
+}
+
+// PartitionNodes partitions nodes from the other nodes.
+type PartitionNodes struct {
+	descriptor *cases.PartitionNodesDescriptor
+	faultNodes map[uint64]struct{}
+}
+
+// Run implements the event interface.
+func (e *PartitionNodes) Run(raft *RaftEngine, tickCount int64) bool {
+	e.faultNodes = updateFaultNodes(raft, e.faultNodes, e.descriptor.Step(tickCount), (*Node).setPartitioned)
+	return false
+}
+
+// SlowSnapshot slows down the snapshots on nodes.
+type SlowSnapshot struct {
+	descriptor *cases.SlowSnapshotDescriptor
+	faultNodes map[uint64]struct{}
+}
+
+// Run implements the event interface.
+func (e *SlowSnapshot) Run(raft *RaftEngine, tickCount int64) bool {
+	res := e.descriptor.Step(tickCount)
+	ids := make([]uint64, 0, len(res))
+	for id := range res {
+		ids = append(ids, id)
+	}
+	e.faultNodes = updateFaultNodes(raft, e.faultNodes, ids, func(n *Node, fault bool) {
+		if !fault {
+			n.setSnapshotSlowdown(0)
+		}
+	})
+	// the slowdown of a faulty node may change.
+	for id := range e.faultNodes {
+		if n := raft.conn.Nodes[id]; n != nil && n.snapshotSlowdown != res[id] {
+			n.setSnapshotSlowdown(res[id])
+		}
+	}
+	return false
+}
@@ -50,6 +50,11 @@ type Node struct {
 	raftEngine               *RaftEngine
 	ioRate                   int64
 	sizeMutex                sync.Mutex
+	// the faults injected by the events.
+	heartbeatStopped bool
+	downTime         time.Time
+	partitionTime    time.Time
+	snapshotSlowdown int64
 }
 
 // NewNode returns a Node.
@@ -121,10 +126,12 @@ func (n *Node) receiveRegionHeartbeat() {
 // Tick steps node status change.
 func (n *Node) Tick(wg *sync.WaitGroup) {
 	defer wg.Done()
-	if n.GetState() != metapb.StoreState_Up {
+	if n.GetState() != metapb.StoreState_Up || n.isDown() {
 		return
 	}
-	n.stepHeartBeat()
+	if !n.heartbeatStopped {
+		n.stepHeartBeat()
+	}
 	n.stepCompaction()
 	n.stepTask()
 	n.tick++
@@ -208,6 +215,9 @@ func (n *Node) regionHeartBeat() {
 }
 
 func (n *Node) reportRegionChange() {
+	if n.heartbeatStopped || n.isDown() {
+		return
+	}
 	regionIDs := n.raftEngine.GetRegionChange(n.Id)
 	for _, regionID := range regionIDs {
 		region := n.raftEngine.GetRegion(regionID)
@@ -246,6 +256,59 @@ func (n *Node) Stop() {
 	simutil.Logger.Info("node stopped", zap.Uint64("node-id", n.Id))
 }
 
+func (n *Node) isDown() bool {
+	return !n.downTime.IsZero()
+}
+
+func (n *Node) isPartitioned() bool {
+	return !n.partitionTime.IsZero()
+}
+
+// unreachableTime returns the time since the node cannot be reached by the
+// other nodes, it is zero if the node is reachable.
+func (n *Node) unreachableTime() time.Time {
+	if n.isDown() && (!n.isPartitioned() || n.downTime.Before(n.partitionTime)) {
+		return n.downTime
+	}
+	return n.partitionTime
+}
+
+func (n *Node) setHeartbeatStopped(stopped bool) {
+	n.heartbeatStopped = stopped
+	simutil.Logger.Info("node heartbeat changed", zap.Uint64("node-id", n.Id), zap.Bool("stopped", stopped))
+}
+
+func (n *Node) setDown(down bool) {
+	if down {
+		n.downTime = time.Now()
+	} else {
+		n.downTime = time.Time{}
+	}
+	simutil.Logger.Info("node down changed", zap.Uint64("node-id", n.Id), zap.Bool("down", down))
+}
+
+func (n *Node) setPartitioned(partitioned bool) {
+	if partitioned {
+		n.partitionTime = time.Now()
+	} else {
+		n.partitionTime = time.Time{}
+	}
+	simutil.Logger.Info("node partition changed", zap.Uint64("node-id", n.Id), zap.Bool("partitioned", partitioned))
+}
+
+func (n *Node) setSnapshotSlowdown(slowdown int64) {
+	n.snapshotSlowdown = slowdown
+	simutil.Logger.Info("node snapshot slowdown changed", zap.Uint64("node-id", n.Id), zap.Int64("slowdown", slowdown))
+}
+
+// snapshotIORate returns the io rate to send or receive snapshots.
+func (n *Node) snapshotIORate() int64 {
+	if n.snapshotSlowdown > 1 {
+		return n.ioRate / n.snapshotSlowdown
+	}
+	return n.ioRate
+}
+
 func (n *Node) incUsedSize(size uint64) {
 	n.sizeMutex.Lock()
 	defer n.sizeMutex.Unlock()
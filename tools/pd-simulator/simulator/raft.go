@@ -16,8 +16,10 @@ package simulator
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/cases"
 	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/simutil"
@@ -49,8 +51,23 @@ func NewRaftEngine(conf *cases.Case, conn *Connection, storeConfig *SimConfig) *
 		regionSplitKeys: conf.RegionSplitKeys,
 		storeConfig:     storeConfig,
 	}
+	useRegionKeys := false
+	for _, region := range conf.Regions {
+		if len(region.StartKey) > 0 || len(region.EndKey) > 0 {
+			useRegionKeys = true
+			break
+		}
+	}
 	var splitKeys []string
-	if conf.TableNumber > 0 {
+	if useRegionKeys {
+		r.useTiDBEncodedKey = true
+		for _, region := range conf.Regions {
+			if !simutil.IsTiDBEncodedKey(region.StartKey) || !simutil.IsTiDBEncodedKey(region.EndKey) {
+				r.useTiDBEncodedKey = false
+				break
+			}
+		}
+	} else if conf.TableNumber > 0 {
 		splitKeys = simutil.GenerateTableKeys(conf.TableNumber, len(conf.Regions)-1)
 		r.useTiDBEncodedKey = true
 	} else {
@@ -63,11 +80,15 @@ func NewRaftEngine(conf *cases.Case, conn *Connection, storeConfig *SimConfig) *
 			Peers:       region.Peers,
 			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
 		}
-		if i > 0 {
-			meta.StartKey = []byte(splitKeys[i-1])
-		}
-		if i < len(conf.Regions)-1 {
-			meta.EndKey = []byte(splitKeys[i])
+		if useRegionKeys {
+			meta.StartKey, meta.EndKey = region.StartKey, region.EndKey
+		} else {
+			if i > 0 {
+				meta.StartKey = []byte(splitKeys[i-1])
+			}
+			if i < len(conf.Regions)-1 {
+				meta.EndKey = []byte(splitKeys[i])
+			}
 		}
 		regionInfo := core.NewRegionInfo(
 			meta,
@@ -94,12 +115,13 @@ func (r *RaftEngine) stepRegions() {
 	regions := r.GetRegions()
 	for _, region := range regions {
 		r.stepLeader(region)
-		r.stepSplit(region)
+		r.stepDownPeers(r.GetRegion(region.GetID()))
+		r.stepSplit(r.GetRegion(region.GetID()))
 	}
 }
 
 func (r *RaftEngine) stepLeader(region *core.RegionInfo) {
-	if region.GetLeader() != nil && r.conn.nodeHealth(region.GetLeader().GetStoreId()) {
+	if region.GetLeader() != nil && r.conn.nodeReachable(region.GetLeader().GetStoreId()) {
 		return
 	}
 	newLeader := r.electNewLeader(region)
@@ -117,6 +139,49 @@ func (r *RaftEngine) stepLeader(region *core.RegionInfo) {
 	r.recordRegionChange(newRegion)
 }
 
+// stepDownPeers reports the peers which cannot be reached by the leader as
+// down peers.
+func (r *RaftEngine) stepDownPeers(region *core.RegionInfo) {
+	if region.GetLeader() == nil {
+		return
+	}
+	var (
+		downPeers []*pdpb.PeerStats
+		changed   bool
+	)
+	for _, peer := range region.GetPeers() {
+		wasDown := region.GetDownPeer(peer.GetId()) != nil
+		node, ok := r.conn.Nodes[peer.GetStoreId()]
+		if !ok {
+			// keep the down peers of the deleted nodes.
+			for _, downPeer := range region.GetDownPeers() {
+				if downPeer.GetPeer().GetId() == peer.GetId() {
+					downPeers = append(downPeers, downPeer)
+				}
+			}
+			continue
+		}
+		unreachableTime := node.unreachableTime()
+		if unreachableTime.IsZero() {
+			changed = changed || wasDown
+			continue
+		}
+		changed = changed || !wasDown
+		downPeers = append(downPeers, &pdpb.PeerStats{
+			Peer:        peer,
+			DownSeconds: uint64(time.Since(unreachableTime).Seconds()),
+		})
+	}
+	if !changed && len(downPeers) == 0 {
+		return
+	}
+	newRegion := region.Clone(core.WithDownPeers(downPeers))
+	r.SetRegion(newRegion)
+	if changed {
+		r.recordRegionChange(newRegion)
+	}
+}
+
 func (r *RaftEngine) stepSplit(region *core.RegionInfo) {
 	if region.GetLeader() == nil {
 		return
@@ -221,7 +286,7 @@ func (r *RaftEngine) electNewLeader(region *core.RegionInfo) *metapb.Peer {
 	)
 	ids := region.GetStoreIds()
 	for id := range ids {
-		if r.conn.nodeHealth(id) {
+		if r.conn.nodeReachable(id) {
 			newLeaderStoreID = id
 		} else {
 			unhealth++
@@ -0,0 +1,208 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pkg/errors"
+)
+
+const (
+	// balanceSampleTicks is the ticks between two samples of the leader and
+	// region count variance.
+	balanceSampleTicks     = 10
+	operatorHistoryPrefix  = "pd/api/v1/operators/history"
+	operatorHistoryTimeout = 10 * time.Second
+)
+
+// Report is the machine-readable result of a case.
+type Report struct {
+	Case string `json:"case"`
+	// Result is "OK" if the checker of the case passes, otherwise "FAIL".
+	Result          string  `json:"result"`
+	Ticks           int64   `json:"ticks"`
+	TimeCostSeconds float64 `json:"time_cost_seconds"`
+	// Operators are the operators ended during the case, the operators
+	// still running at the end are not included.
+	Operators []*OperatorReport `json:"operators"`
+	Tasks     map[string]int    `json:"tasks"`
+	Stores    []*StoreReport    `json:"stores"`
+	Balance   []*BalanceSample  `json:"balance"`
+}
+
+// OperatorReport is the count of the operators of a scheduler and a kind.
+type OperatorReport struct {
+	// Scheduler is the name of the scheduler or checker created the operators.
+	Scheduler string `json:"scheduler"`
+	Kind      string `json:"kind"`
+	Created   int    `json:"created"`
+	Finished  int    `json:"finished"`
+	// Status is the count of the operators of each end status.
+	Status map[string]int `json:"status"`
+}
+
+// StoreReport is the status of a store at the end of the case.
+type StoreReport struct {
+	StoreID          uint64 `json:"store_id"`
+	LeaderCount      int    `json:"leader_count"`
+	RegionCount      int    `json:"region_count"`
+	SendSnapshots    int    `json:"send_snapshots"`
+	ReceiveSnapshots int    `json:"receive_snapshots"`
+	SendBytes        int64  `json:"send_bytes"`
+	ReceiveBytes     int64  `json:"receive_bytes"`
+}
+
+// BalanceSample is the variance of the leader and region counts of the
+// stores at a tick.
+type BalanceSample struct {
+	Tick           int64   `json:"tick"`
+	LeaderVariance float64 `json:"leader_variance"`
+	RegionVariance float64 `json:"region_variance"`
+}
+
+func (d *Driver) sampleBalance() {
+	leaderCounts := make([]int, 0, len(d.conn.Nodes))
+	regionCounts := make([]int, 0, len(d.conn.Nodes))
+	for id := range d.conn.Nodes {
+		leaderCounts = append(leaderCounts, d.raftEngine.regionsInfo.GetStoreLeaderCount(id))
+		regionCounts = append(regionCounts, d.raftEngine.regionsInfo.GetStoreRegionCount(id))
+	}
+	d.balanceSamples = append(d.balanceSamples, &BalanceSample{
+		Tick:           d.tickCount,
+		LeaderVariance: variance(leaderCounts),
+		RegionVariance: variance(regionCounts),
+	})
+}
+
+func variance(counts []int) float64 {
+	if len(counts) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, c := range counts {
+		sum += float64(c)
+	}
+	mean := sum / float64(len(counts))
+	var v float64
+	for _, c := range counts {
+		v += (float64(c) - mean) * (float64(c) - mean)
+	}
+	return v / float64(len(counts))
+}
+
+// Report returns the report of the case. It should be called before the PD
+// server is closed to get the operators.
+func (d *Driver) Report(caseName string, checked bool, timeCost time.Duration) (*Report, error) {
+	report := &Report{
+		Case:            caseName,
+		Result:          "FAIL",
+		Ticks:           d.tickCount,
+		TimeCostSeconds: timeCost.Seconds(),
+		Tasks:           d.raftEngine.schedulerStats.taskStats.getStatistics(),
+		Balance:         d.balanceSamples,
+	}
+	if checked {
+		report.Result = "OK"
+	}
+
+	snapStats := d.raftEngine.schedulerStats.snapshotStats
+	snapStats.RLock()
+	for id := range d.conn.Nodes {
+		report.Stores = append(report.Stores, &StoreReport{
+			StoreID:          id,
+			LeaderCount:      d.raftEngine.regionsInfo.GetStoreLeaderCount(id),
+			RegionCount:      d.raftEngine.regionsInfo.GetStoreRegionCount(id),
+			SendSnapshots:    snapStats.send[id],
+			ReceiveSnapshots: snapStats.receive[id],
+			SendBytes:        snapStats.sendBytes[id],
+			ReceiveBytes:     snapStats.receiveBytes[id],
+		})
+	}
+	snapStats.RUnlock()
+	sort.Slice(report.Stores, func(i, j int) bool { return report.Stores[i].StoreID < report.Stores[j].StoreID })
+
+	records, err := d.getOperatorRecords()
+	if err != nil {
+		return report, err
+	}
+	report.Operators = summaryOperators(records)
+	return report, nil
+}
+
+func (d *Driver) getOperatorRecords() ([]*operator.OpRecord, error) {
+	url := fmt.Sprintf("%s/%s?start=%d", d.pdAddr, operatorHistoryPrefix, d.startTime.Unix())
+	client := &http.Client{Timeout: operatorHistoryTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to get operator history: %s", body)
+	}
+	var records []*operator.OpRecord
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return records, nil
+}
+
+func summaryOperators(records []*operator.OpRecord) []*OperatorReport {
+	type key struct{ scheduler, kind string }
+	reports := make(map[key]*OperatorReport)
+	for _, r := range records {
+		k := key{r.Desc, r.Kind}
+		report, ok := reports[k]
+		if !ok {
+			report = &OperatorReport{Scheduler: r.Desc, Kind: r.Kind, Status: make(map[string]int)}
+			reports[k] = report
+		}
+		report.Created++
+		if r.Status == operator.OpStatusToString(operator.SUCCESS) {
+			report.Finished++
+		}
+		report.Status[r.Status]++
+	}
+	ret := make([]*OperatorReport, 0, len(reports))
+	for _, report := range reports {
+		ret = append(ret, report)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Scheduler != ret[j].Scheduler {
+			return ret[i].Scheduler < ret[j].Scheduler
+		}
+		return ret[i].Kind < ret[j].Kind
+	})
+	return ret
+}
+
+// WriteReport writes the report into a file as JSON.
+func WriteReport(report *Report, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(ioutil.WriteFile(path, data, 0644))
+}
@@ -0,0 +1,192 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testReportSuite{})
+
+type testReportSuite struct {
+	dir string
+}
+
+func (s *testReportSuite) SetUpTest(c *C) {
+	var err error
+	s.dir, err = ioutil.TempDir("", "sim-report")
+	c.Assert(err, IsNil)
+}
+
+func (s *testReportSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func (s *testReportSuite) TestReport(c *C) {
+	success := operator.OpStatusToString(operator.SUCCESS)
+	timeout := operator.OpStatusToString(operator.TIMEOUT)
+	records := []*operator.OpRecord{
+		{RegionID: 1, Desc: "balance-region-scheduler", Kind: "region", Status: success},
+		{RegionID: 2, Desc: "balance-region-scheduler", Kind: "region", Status: timeout},
+		{RegionID: 1, Desc: "balance-leader-scheduler", Kind: "leader", Status: success},
+		{RegionID: 2, Desc: "balance-region-scheduler", Kind: "leader,region", Status: success},
+	}
+	var requestURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURL = r.URL.String()
+		data, _ := json.Marshal(records)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	// 3 stores, store 1 has 2 leaders and store 3 has no region.
+	d := &Driver{
+		pdAddr: server.URL,
+		conn:   &Connection{Nodes: map[uint64]*Node{1: nil, 2: nil, 3: nil}},
+		raftEngine: &RaftEngine{
+			regionsInfo:    core.NewRegionsInfo(),
+			schedulerStats: newSchedulerStatistics(),
+		},
+		startTime: time.Now(),
+	}
+	keys := [][]byte{{}, []byte("b"), {}}
+	for id := uint64(1); id <= 2; id++ {
+		peers := []*metapb.Peer{{Id: id * 10, StoreId: 1}, {Id: id*10 + 1, StoreId: 2}}
+		region := &metapb.Region{Id: id, StartKey: keys[id-1], EndKey: keys[id], Peers: peers}
+		d.raftEngine.regionsInfo.SetRegion(core.NewRegionInfo(region, peers[0]))
+	}
+	d.tickCount = balanceSampleTicks
+	d.sampleBalance()
+
+	// Move a region of 96MB from store 1 to store 3.
+	stats := d.raftEngine.schedulerStats
+	stats.snapshotStats.incSendSnapshot(1)
+	stats.snapshotStats.incReceiveSnapshot(3)
+	stats.snapshotStats.incSnapshotBytes(1, 3, 96<<20)
+	stats.taskStats.incAddPeer(2)
+	peers := []*metapb.Peer{{Id: 22, StoreId: 3}, {Id: 21, StoreId: 2}}
+	region := &metapb.Region{Id: 2, StartKey: keys[1], EndKey: keys[2], Peers: peers}
+	d.raftEngine.regionsInfo.SetRegion(core.NewRegionInfo(region, peers[1]))
+	d.tickCount = 2*balanceSampleTicks + 3
+	d.sampleBalance()
+
+	report, err := d.Report("test", true, 2*time.Second)
+	c.Assert(err, IsNil)
+	c.Assert(requestURL, Equals, fmt.Sprintf("/%s?start=%d", operatorHistoryPrefix, d.startTime.Unix()))
+	path := filepath.Join(s.dir, "report.json")
+	c.Assert(WriteReport(report, path), IsNil)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+
+	var result struct {
+		Case            string  `json:"case"`
+		Result          string  `json:"result"`
+		Ticks           int64   `json:"ticks"`
+		TimeCostSeconds float64 `json:"time_cost_seconds"`
+		Operators       []struct {
+			Scheduler string         `json:"scheduler"`
+			Kind      string         `json:"kind"`
+			Created   int            `json:"created"`
+			Finished  int            `json:"finished"`
+			Status    map[string]int `json:"status"`
+		} `json:"operators"`
+		Tasks  map[string]int `json:"tasks"`
+		Stores []struct {
+			StoreID          uint64 `json:"store_id"`
+			LeaderCount      int    `json:"leader_count"`
+			RegionCount      int    `json:"region_count"`
+			SendSnapshots    int    `json:"send_snapshots"`
+			ReceiveSnapshots int    `json:"receive_snapshots"`
+			SendBytes        int64  `json:"send_bytes"`
+			ReceiveBytes     int64  `json:"receive_bytes"`
+		} `json:"stores"`
+		Balance []struct {
+			Tick           int64   `json:"tick"`
+			LeaderVariance float64 `json:"leader_variance"`
+			RegionVariance float64 `json:"region_variance"`
+		} `json:"balance"`
+	}
+	c.Assert(json.Unmarshal(data, &result), IsNil)
+
+	c.Assert(result.Case, Equals, "test")
+	c.Assert(result.Result, Equals, "OK")
+	c.Assert(result.Ticks, Equals, int64(2*balanceSampleTicks+3))
+	c.Assert(result.TimeCostSeconds, Equals, 2.0)
+
+	// operators are grouped by scheduler and kind.
+	c.Assert(result.Operators, HasLen, 3)
+	c.Assert(result.Operators[0].Scheduler, Equals, "balance-leader-scheduler")
+	c.Assert(result.Operators[0].Kind, Equals, "leader")
+	c.Assert(result.Operators[0].Created, Equals, 1)
+	c.Assert(result.Operators[0].Finished, Equals, 1)
+	c.Assert(result.Operators[0].Status, DeepEquals, map[string]int{success: 1})
+	c.Assert(result.Operators[1].Scheduler, Equals, "balance-region-scheduler")
+	c.Assert(result.Operators[1].Kind, Equals, "leader,region")
+	c.Assert(result.Operators[1].Created, Equals, 1)
+	c.Assert(result.Operators[1].Finished, Equals, 1)
+	c.Assert(result.Operators[2].Scheduler, Equals, "balance-region-scheduler")
+	c.Assert(result.Operators[2].Kind, Equals, "region")
+	c.Assert(result.Operators[2].Created, Equals, 2)
+	c.Assert(result.Operators[2].Finished, Equals, 1)
+	c.Assert(result.Operators[2].Status, DeepEquals, map[string]int{success: 1, timeout: 1})
+
+	c.Assert(result.Tasks["Add Peer (task)"], Equals, 1)
+
+	// stores are sorted by ID with the bytes moved.
+	c.Assert(result.Stores, HasLen, 3)
+	expectedStores := []struct {
+		leaders, regions, send, receive int
+		sendBytes, receiveBytes         int64
+	}{
+		{1, 1, 1, 0, 96 << 20, 0},
+		{1, 2, 0, 0, 0, 0},
+		{0, 1, 0, 1, 0, 96 << 20},
+	}
+	for i, e := range expectedStores {
+		store := result.Stores[i]
+		c.Assert(store.StoreID, Equals, uint64(i+1))
+		c.Assert(store.LeaderCount, Equals, e.leaders)
+		c.Assert(store.RegionCount, Equals, e.regions)
+		c.Assert(store.SendSnapshots, Equals, e.send)
+		c.Assert(store.ReceiveSnapshots, Equals, e.receive)
+		c.Assert(store.SendBytes, Equals, e.sendBytes)
+		c.Assert(store.ReceiveBytes, Equals, e.receiveBytes)
+	}
+
+	// leaders: [2, 0, 0] -> [1, 1, 0], regions: [2, 2, 0] -> [1, 2, 1].
+	c.Assert(result.Balance, HasLen, 2)
+	c.Assert(result.Balance[0].Tick, Equals, int64(balanceSampleTicks))
+	c.Assert(result.Balance[0].LeaderVariance, Equals, variance([]int{2, 0, 0}))
+	c.Assert(result.Balance[0].RegionVariance, Equals, variance([]int{2, 2, 0}))
+	c.Assert(result.Balance[1].Tick, Equals, int64(2*balanceSampleTicks+3))
+	c.Assert(result.Balance[1].LeaderVariance, Equals, variance([]int{1, 1, 0}))
+	c.Assert(result.Balance[1].RegionVariance, Equals, variance([]int{1, 2, 1}))
+}
@@ -113,6 +113,13 @@ func mustDecodeMvccKey(key []byte) ([]byte, error) {
 	return res, nil
 }
 
+// IsTiDBEncodedKey checks if the key is encoded according to the TiDB encoding
+// rules, the empty key is treated as encoded.
+func IsTiDBEncodedKey(key []byte) bool {
+	_, err := mustDecodeMvccKey(key)
+	return err == nil
+}
+
 // GenerateTiDBEncodedSplitKey calculates the split key with start and end key,
 // the keys are encoded according to the TiDB encoding rules.
 func GenerateTiDBEncodedSplitKey(start, end []byte) ([]byte, error) {
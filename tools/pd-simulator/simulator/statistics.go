@@ -110,14 +110,18 @@ func (t *taskStatistics) incTransferLeader(fromPeerID, toPeerID uint64) {
 
 type snapshotStatistics struct {
 	sync.RWMutex
-	receive map[uint64]int
-	send    map[uint64]int
+	receive      map[uint64]int
+	send         map[uint64]int
+	receiveBytes map[uint64]int64
+	sendBytes    map[uint64]int64
 }
 
 func newSnapshotStatistics() *snapshotStatistics {
 	return &snapshotStatistics{
-		receive: make(map[uint64]int),
-		send:    make(map[uint64]int),
+		receive:      make(map[uint64]int),
+		send:         make(map[uint64]int),
+		receiveBytes: make(map[uint64]int64),
+		sendBytes:    make(map[uint64]int64),
 	}
 }
 
@@ -166,6 +170,13 @@ func (s *snapshotStatistics) incReceiveSnapshot(storeID uint64) {
 	s.receive[storeID]++
 }
 
+func (s *snapshotStatistics) incSnapshotBytes(sendStoreID, receiveStoreID uint64, size int64) {
+	s.Lock()
+	defer s.Unlock()
+	s.sendBytes[sendStoreID] += size
+	s.receiveBytes[receiveStoreID] += size
+}
+
 // PrintStatistics prints the statistics of the scheduler.
 func (s *schedulerStatistics) PrintStatistics() {
 	task := s.taskStats.getStatistics()
@@ -239,6 +239,11 @@ func (a *addPeer) Step(r *RaftEngine) {
 		a.finished = true
 		return
 	}
+	// the snapshot cannot be sent until the nodes can reach each other.
+	_, recvExists := r.conn.Nodes[a.peer.GetStoreId()]
+	if recvExists && (!r.conn.nodeReachable(sendNode.Id) || !r.conn.nodeReachable(a.peer.GetStoreId())) {
+		return
+	}
 	if !processSnapshot(sendNode, a.sendingStat, snapshotSize) {
 		return
 	}
@@ -269,6 +274,7 @@ func (a *addPeer) Step(r *RaftEngine) {
 		r.SetRegion(newRegion)
 		r.recordRegionChange(newRegion)
 		recvNode.incUsedSize(uint64(snapshotSize))
+		r.schedulerStats.snapshotStats.incSnapshotBytes(sendNode.Id, recvNode.Id, snapshotSize)
 		a.finished = true
 	}
 }
@@ -409,7 +415,7 @@ func processSnapshot(n *Node, stat *snapshotStat, snapshotSize int64) bool {
 			n.stats.ReceivingSnapCount++
 		}
 	}
-	stat.remainSize -= n.ioRate
+	stat.remainSize -= n.snapshotIORate()
 	// The sending or receiving process has not finished yet.
 	if stat.remainSize > 0 {
 		return false
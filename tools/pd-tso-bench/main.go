@@ -40,7 +40,12 @@ var (
 	caPath      = flag.String("cacert", "", "path of file that contains list of trusted SSL CAs")
 	certPath    = flag.String("cert", "", "path of file that contains X509 certificate in PEM format")
 	keyPath     = flag.String("key", "", "path of file that contains X509 key in PEM format")
-	wg          sync.WaitGroup
+	// the options of the TSO client.
+	maxBatchSize     = flag.Int("max-batch-size", 10000, "max number of the TSO requests in a batch")
+	maxBatchWait     = flag.Duration("max-batch-wait", 0, "max time to wait for more TSO requests to batch")
+	tsoStreams       = flag.Int("streams", 1, "number of the TSO streams")
+	latencyHistogram = flag.Bool("latency-histogram", false, "enable the latency histograms of each TSO request")
+	wg               sync.WaitGroup
 )
 
 var promServer *httptest.Server
@@ -57,14 +62,28 @@ func main() {
 	promServer = httptest.NewServer(promhttp.Handler())
 	flag.Parse()
 
+	opts := []pd.ClientOption{
+		pd.WithTSOMaxBatchSize(*maxBatchSize),
+		pd.WithTSOMaxBatchWaitTime(*maxBatchWait),
+		pd.WithTSOStreams(*tsoStreams),
+	}
+	if *latencyHistogram {
+		opts = append(opts, pd.WithTSOLatencyHistogram())
+	}
 	pdCli, err := pd.NewClient([]string{*pdAddrs}, pd.SecurityOption{
 		CAPath:   *caPath,
 		CertPath: *certPath,
 		KeyPath:  *keyPath,
-	})
+	}, opts...)
 	if err != nil {
 		log.Fatal(fmt.Sprintf("%v", err))
 	}
+	log.Info("start the tso bench",
+		zap.Int("concurrency", *concurrency),
+		zap.Int("max-batch-size", *maxBatchSize),
+		zap.Duration("max-batch-wait", *maxBatchWait),
+		zap.Int("streams", *tsoStreams),
+		zap.Bool("latency-histogram", *latencyHistogram))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	// To avoid the first time high latency.
@@ -2,11 +2,13 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"math"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +16,8 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/pkg/etcdutil"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/tools/regions-dump/regionsdump"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/clientv3"
 	"go.etcd.io/etcd/pkg/transport"
@@ -28,6 +32,10 @@ var (
 	caPath    = flag.String("cacert", "", "path of file that contains list of trusted SSL CAs")
 	certPath  = flag.String("cert", "", "path of file that contains X509 certificate in PEM format")
 	keyPath   = flag.String("key", "", "path of file that contains X509 key in PEM format")
+	// The region storage is locked by PD, so PD should be stopped to load the
+	// regions from it.
+	regionStorage = flag.String("region-storage", "", "path of the region storage of a stopped PD, e.g. data.pd/region-meta, the regions are loaded from etcd if it is not specified")
+	format        = flag.String("format", formatRaw, "format of the dump file, raw, json or csv, the json file can be replayed by pd-simulator and pd-heartbeat-bench")
 )
 
 const (
@@ -36,6 +44,10 @@ const (
 	pdRootPath      = "/pd"
 	maxKVRangeLimit = 10000
 	minKVRangeLimit = 100
+
+	formatRaw  = "raw"
+	formatJSON = "json"
+	formatCSV  = "csv"
 )
 
 var (
@@ -54,30 +66,62 @@ func main() {
 	if *endID != 0 && *endID < *startID {
 		checkErr(errors.New("The end id should great or equal than start id"))
 	}
+	if *format != formatRaw && *format != formatJSON && *format != formatCSV {
+		checkErr(errors.Errorf("unknown format %s", *format))
+	}
 	rootPath = path.Join(pdRootPath, strconv.FormatUint(*clusterID, 10))
-	f, err := os.Create(*filePath)
-	checkErr(err)
-	defer f.Close()
-
-	urls := strings.Split(*endpoints, ",")
 
-	tlsInfo := transport.TLSInfo{
-		CertFile:      *certPath,
-		KeyFile:       *keyPath,
-		TrustedCAFile: *caPath,
+	var regions []*metapb.Region
+	if *regionStorage != "" {
+		var err error
+		regions, err = loadRegionsFromStorage(*regionStorage)
+		checkErr(err)
+	} else {
+		urls := strings.Split(*endpoints, ",")
+
+		tlsInfo := transport.TLSInfo{
+			CertFile:      *certPath,
+			KeyFile:       *keyPath,
+			TrustedCAFile: *caPath,
+		}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		checkErr(err)
+
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints:   urls,
+			DialTimeout: etcdTimeout,
+			TLS:         tlsConfig,
+		})
+		checkErr(err)
+
+		regions, err = loadRegions(client)
+		checkErr(err)
 	}
-	tlsConfig, err := tlsInfo.ClientConfig()
-	checkErr(err)
 
-	client, err := clientv3.New(clientv3.Config{
-		Endpoints:   urls,
-		DialTimeout: etcdTimeout,
-		TLS:         tlsConfig,
-	})
+	f, err := os.Create(*filePath)
 	checkErr(err)
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	defer w.Flush()
+	switch *format {
+	case formatRaw:
+		for _, region := range regions {
+			fmt.Fprintln(w, core.RegionToHexMeta(region).Region)
+		}
+	case formatJSON:
+		checkErr(regionsdump.NewFile(regions).WriteJSON(w))
+	case formatCSV:
+		checkErr(regionsdump.NewFile(regions).WriteCSV(w))
+	}
 
-	err = loadRegions(client, f)
-	checkErr(err)
+	// the key ranges are only checked if all regions are dumped.
+	if *startID == 0 && *endID == 0 {
+		issues := regionsdump.CheckKeyRanges(regions)
+		for _, issue := range issues {
+			fmt.Printf("%s: [%s, %s) regions %v\n", issue.Type, issue.StartKey, issue.EndKey, issue.Regions)
+		}
+		fmt.Printf("%d regions, %d gaps or overlaps\n", len(regions), len(issues))
+	}
 	fmt.Println("successful!")
 }
 
@@ -85,14 +129,13 @@ func regionPath(regionID uint64) string {
 	return path.Join("raft", "r", fmt.Sprintf("%020d", regionID))
 }
 
-func loadRegions(client *clientv3.Client, f *os.File) error {
+func loadRegions(client *clientv3.Client) ([]*metapb.Region, error) {
+	var regions []*metapb.Region
 	nextID := *startID
 	endKey := regionPath(math.MaxUint64)
 	if *endID != 0 {
 		endKey = regionPath(*endID)
 	}
-	w := bufio.NewWriter(f)
-	defer w.Flush()
 	// Since the region key may be very long, using a larger rangeLimit will cause
 	// the message packet to exceed the grpc message size limit (4MB). Here we use
 	// a variable rangeLimit to work around.
@@ -104,24 +147,50 @@ func loadRegions(client *clientv3.Client, f *os.File) error {
 			if rangeLimit /= 2; rangeLimit >= minKVRangeLimit {
 				continue
 			}
-			return err
+			return nil, err
 		}
 
 		for _, s := range res {
 			region := &metapb.Region{}
 			if err := region.Unmarshal([]byte(s)); err != nil {
-				return errors.WithStack(err)
+				return nil, errors.WithStack(err)
 			}
 			nextID = region.GetId() + 1
-			fmt.Fprintln(w, core.RegionToHexMeta(region).Region)
+			regions = append(regions, region)
 		}
 
 		if len(res) < rangeLimit {
-			return nil
+			return regions, nil
 		}
 	}
 }
 
+// loadRegionsFromStorage loads the regions from the region storage. It is used
+// when PD saves the regions in the region storage instead of etcd.
+func loadRegionsFromStorage(dir string) ([]*metapb.Region, error) {
+	regionStorage, err := core.NewRegionStorage(context.Background(), dir)
+	if err != nil {
+		return nil, err
+	}
+	defer regionStorage.Close()
+	storage := core.NewStorage(kv.NewMemoryKV()).SetRegionStorage(regionStorage)
+	storage.SwitchToRegionStorage()
+
+	var regions []*metapb.Region
+	err = storage.LoadRegions(func(region *core.RegionInfo) []*core.RegionInfo {
+		id := region.GetID()
+		if id >= *startID && (*endID == 0 || id < *endID) {
+			regions = append(regions, region.GetMeta())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].GetId() < regions[j].GetId() })
+	return regions, nil
+}
+
 func loadRange(client *clientv3.Client, key, endKey string, limit int) ([]string, []string, error) {
 	key = path.Join(rootPath, key)
 	endKey = path.Join(rootPath, endKey)
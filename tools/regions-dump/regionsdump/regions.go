@@ -0,0 +1,272 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regionsdump
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/codec"
+	"github.com/pkg/errors"
+)
+
+// Region is the meta of a region in the dump file. The keys are in the hex
+// format, and the table and index IDs are decoded from them if the keys are
+// encoded by TiDB.
+type Region struct {
+	ID         uint64  `json:"id"`
+	StartKey   string  `json:"start_key"`
+	EndKey     string  `json:"end_key"`
+	StartTable int64   `json:"start_table,omitempty"`
+	StartIndex int64   `json:"start_index,omitempty"`
+	EndTable   int64   `json:"end_table,omitempty"`
+	EndIndex   int64   `json:"end_index,omitempty"`
+	ConfVer    uint64  `json:"conf_ver"`
+	Version    uint64  `json:"version"`
+	Peers      []*Peer `json:"peers"`
+}
+
+// Peer is a peer of a region in the dump file.
+type Peer struct {
+	ID        uint64 `json:"id"`
+	StoreID   uint64 `json:"store_id"`
+	IsLearner bool   `json:"is_learner,omitempty"`
+}
+
+// NewRegion creates a Region from the region meta.
+func NewRegion(meta *metapb.Region) *Region {
+	startKey, endKey := codec.Key(meta.GetStartKey()), codec.Key(meta.GetEndKey())
+	r := &Region{
+		ID:         meta.GetId(),
+		StartKey:   hex.EncodeToString(startKey),
+		EndKey:     hex.EncodeToString(endKey),
+		StartTable: startKey.TableID(),
+		StartIndex: startKey.IndexID(),
+		EndTable:   endKey.TableID(),
+		EndIndex:   endKey.IndexID(),
+		ConfVer:    meta.GetRegionEpoch().GetConfVer(),
+		Version:    meta.GetRegionEpoch().GetVersion(),
+	}
+	for _, p := range meta.GetPeers() {
+		r.Peers = append(r.Peers, &Peer{ID: p.GetId(), StoreID: p.GetStoreId(), IsLearner: p.GetIsLearner()})
+	}
+	return r
+}
+
+// Meta returns the region meta of the Region.
+func (r *Region) Meta() (*metapb.Region, error) {
+	startKey, err := hex.DecodeString(r.StartKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid start key of region %d", r.ID)
+	}
+	endKey, err := hex.DecodeString(r.EndKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid end key of region %d", r.ID)
+	}
+	meta := &metapb.Region{
+		Id:          r.ID,
+		StartKey:    startKey,
+		EndKey:      endKey,
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: r.ConfVer, Version: r.Version},
+	}
+	for _, p := range r.Peers {
+		meta.Peers = append(meta.Peers, &metapb.Peer{Id: p.ID, StoreId: p.StoreID, IsLearner: p.IsLearner})
+	}
+	return meta, nil
+}
+
+// The types of the key range issues.
+const (
+	IssueGap     = "gap"
+	IssueOverlap = "overlap"
+)
+
+// Issue is a gap or an overlap of the key ranges of the regions. For a gap,
+// the range [StartKey, EndKey) is not covered by any region. For an overlap,
+// the range is covered by both regions.
+type Issue struct {
+	Type     string `json:"type"`
+	StartKey string `json:"start_key"`
+	EndKey   string `json:"end_key"`
+	// Regions are the regions next to the gap or the overlapped regions.
+	Regions []uint64 `json:"regions"`
+}
+
+type keyRange struct {
+	id       uint64
+	startKey []byte
+	endKey   []byte
+}
+
+// CheckKeyRanges returns the gaps and overlaps of the key ranges of the
+// regions. The regions should cover the whole key space.
+func CheckKeyRanges(regions []*metapb.Region) []*Issue {
+	if len(regions) == 0 {
+		return nil
+	}
+	ranges := make([]keyRange, 0, len(regions))
+	for _, r := range regions {
+		ranges = append(ranges, keyRange{id: r.GetId(), startKey: r.GetStartKey(), endKey: r.GetEndKey()})
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		if c := bytes.Compare(ranges[i].startKey, ranges[j].startKey); c != 0 {
+			return c < 0
+		}
+		return ranges[i].id < ranges[j].id
+	})
+
+	var issues []*Issue
+	if first := ranges[0]; len(first.startKey) > 0 {
+		issues = append(issues, newIssue(IssueGap, nil, first.startKey, first.id))
+	}
+	// prev is the range which covers the largest key so far.
+	prev := ranges[0]
+	for _, cur := range ranges[1:] {
+		switch {
+		case len(prev.endKey) == 0:
+			issues = append(issues, newIssue(IssueOverlap, cur.startKey, cur.endKey, prev.id, cur.id))
+			continue
+		case bytes.Compare(prev.endKey, cur.startKey) < 0:
+			issues = append(issues, newIssue(IssueGap, prev.endKey, cur.startKey, prev.id, cur.id))
+		case bytes.Compare(prev.endKey, cur.startKey) > 0:
+			end := prev.endKey
+			if len(cur.endKey) > 0 && bytes.Compare(cur.endKey, end) < 0 {
+				end = cur.endKey
+			}
+			issues = append(issues, newIssue(IssueOverlap, cur.startKey, end, prev.id, cur.id))
+			if len(cur.endKey) > 0 && bytes.Compare(cur.endKey, prev.endKey) <= 0 {
+				continue
+			}
+		}
+		prev = cur
+	}
+	if len(prev.endKey) > 0 {
+		issues = append(issues, newIssue(IssueGap, prev.endKey, nil, prev.id))
+	}
+	return issues
+}
+
+func newIssue(typ string, startKey, endKey []byte, regions ...uint64) *Issue {
+	return &Issue{
+		Type:     typ,
+		StartKey: hex.EncodeToString(startKey),
+		EndKey:   hex.EncodeToString(endKey),
+		Regions:  regions,
+	}
+}
+
+// File is the dump file of the regions. It can be replayed by pd-simulator and
+// pd-heartbeat-bench as the initial state of a cluster.
+type File struct {
+	Stores  []uint64  `json:"stores"`
+	Regions []*Region `json:"regions"`
+	Issues  []*Issue  `json:"issues,omitempty"`
+}
+
+// NewFile creates the dump file of the regions.
+func NewFile(regions []*metapb.Region) *File {
+	f := &File{Issues: CheckKeyRanges(regions)}
+	stores := make(map[uint64]struct{})
+	for _, meta := range regions {
+		f.Regions = append(f.Regions, NewRegion(meta))
+		for _, p := range meta.GetPeers() {
+			stores[p.GetStoreId()] = struct{}{}
+		}
+	}
+	for id := range stores {
+		f.Stores = append(f.Stores, id)
+	}
+	sort.Slice(f.Stores, func(i, j int) bool { return f.Stores[i] < f.Stores[j] })
+	return f
+}
+
+// WriteJSON writes the file as JSON.
+func (f *File) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = w.Write(data)
+	return errors.WithStack(err)
+}
+
+// WriteCSV writes the regions as CSV, one region per line.
+func (f *File) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"id", "start_key", "end_key", "start_table", "start_index", "end_table", "end_index", "conf_ver", "version", "peers"}
+	if err := cw.Write(header); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, r := range f.Regions {
+		peers := make([]string, 0, len(r.Peers))
+		for _, p := range r.Peers {
+			peer := strconv.FormatUint(p.ID, 10) + ":" + strconv.FormatUint(p.StoreID, 10)
+			if p.IsLearner {
+				peer += ":learner"
+			}
+			peers = append(peers, peer)
+		}
+		record := []string{
+			strconv.FormatUint(r.ID, 10),
+			r.StartKey,
+			r.EndKey,
+			strconv.FormatInt(r.StartTable, 10),
+			strconv.FormatInt(r.StartIndex, 10),
+			strconv.FormatInt(r.EndTable, 10),
+			strconv.FormatInt(r.EndIndex, 10),
+			strconv.FormatUint(r.ConfVer, 10),
+			strconv.FormatUint(r.Version, 10),
+			strings.Join(peers, " "),
+		}
+		if err := cw.Write(record); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	cw.Flush()
+	return errors.WithStack(cw.Error())
+}
+
+// LoadFile loads the dump file written by WriteJSON.
+func LoadFile(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &File{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode regions file %s", path)
+	}
+	return f, nil
+}
+
+// Metas returns the region metas of the file.
+func (f *File) Metas() ([]*metapb.Region, error) {
+	metas := make([]*metapb.Region, 0, len(f.Regions))
+	for _, r := range f.Regions {
+		meta, err := r.Meta()
+		if err != nil {
+			return nil, err
+		}
+		metas = append(metas, meta)
+	}
+	return metas, nil
+}
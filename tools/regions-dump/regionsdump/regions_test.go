@@ -0,0 +1,123 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regionsdump
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/codec"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testRegionsSuite{})
+
+type testRegionsSuite struct{}
+
+func newMeta(id uint64, start, end string, stores ...uint64) *metapb.Region {
+	meta := &metapb.Region{
+		Id:          id,
+		StartKey:    []byte(start),
+		EndKey:      []byte(end),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 2, Version: 3},
+	}
+	for i, s := range stores {
+		meta.Peers = append(meta.Peers, &metapb.Peer{Id: id*10 + uint64(i), StoreId: s})
+	}
+	return meta
+}
+
+func (s *testRegionsSuite) TestRegion(c *C) {
+	meta := newMeta(1, "", "", 1, 2)
+	meta.StartKey = codec.EncodeBytes(codec.GenerateTableKey(10))
+	meta.EndKey = codec.EncodeBytes(codec.GenerateIndexKey(12, 3))
+	meta.Peers[1].IsLearner = true
+
+	r := NewRegion(meta)
+	c.Assert(r.StartTable, Equals, int64(10))
+	c.Assert(r.StartIndex, Equals, int64(0))
+	c.Assert(r.EndTable, Equals, int64(12))
+	c.Assert(r.EndIndex, Equals, int64(3))
+	c.Assert(r.Peers[1].IsLearner, IsTrue)
+
+	newMeta, err := r.Meta()
+	c.Assert(err, IsNil)
+	c.Assert(newMeta, DeepEquals, meta)
+}
+
+func (s *testRegionsSuite) TestCheckKeyRanges(c *C) {
+	hexKey := func(k string) string { return hex.EncodeToString([]byte(k)) }
+
+	regions := []*metapb.Region{
+		newMeta(1, "", "b"),
+		newMeta(2, "b", "d"),
+		newMeta(3, "d", ""),
+	}
+	c.Assert(CheckKeyRanges(regions), HasLen, 0)
+
+	regions = []*metapb.Region{
+		newMeta(1, "a", "b"),
+		newMeta(2, "c", "e"),
+		newMeta(3, "d", "f"),
+		newMeta(4, "d1", "d2"),
+	}
+	issues := CheckKeyRanges(regions)
+	c.Assert(issues, DeepEquals, []*Issue{
+		{Type: IssueGap, StartKey: "", EndKey: hexKey("a"), Regions: []uint64{1}},
+		{Type: IssueGap, StartKey: hexKey("b"), EndKey: hexKey("c"), Regions: []uint64{1, 2}},
+		{Type: IssueOverlap, StartKey: hexKey("d"), EndKey: hexKey("e"), Regions: []uint64{2, 3}},
+		{Type: IssueOverlap, StartKey: hexKey("d1"), EndKey: hexKey("d2"), Regions: []uint64{3, 4}},
+		{Type: IssueGap, StartKey: hexKey("f"), EndKey: "", Regions: []uint64{3}},
+	})
+
+	regions = []*metapb.Region{
+		newMeta(1, "", ""),
+		newMeta(2, "b", "c"),
+	}
+	issues = CheckKeyRanges(regions)
+	c.Assert(issues, HasLen, 1)
+	c.Assert(issues[0].Type, Equals, IssueOverlap)
+}
+
+func (s *testRegionsSuite) TestFile(c *C) {
+	regions := []*metapb.Region{
+		newMeta(1, "", "b", 1, 2, 3),
+		newMeta(2, "b", "", 2, 3, 4),
+	}
+	f := NewFile(regions)
+	c.Assert(f.Stores, DeepEquals, []uint64{1, 2, 3, 4})
+	c.Assert(f.Issues, HasLen, 0)
+
+	var buf bytes.Buffer
+	c.Assert(f.WriteJSON(&buf), IsNil)
+	newFile := &File{}
+	c.Assert(json.Unmarshal(buf.Bytes(), newFile), IsNil)
+	metas, err := newFile.Metas()
+	c.Assert(err, IsNil)
+	c.Assert(metas, DeepEquals, regions)
+
+	buf.Reset()
+	c.Assert(f.WriteCSV(&buf), IsNil)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(lines, HasLen, 3)
+	c.Assert(lines[2], Equals, "2,62,,0,0,0,0,2,3,20:2 21:3 22:4")
+}